@@ -0,0 +1,199 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// Header carries the API key. A bearer Authorization header is accepted as a fallback.
+const Header = "X-API-Key"
+
+// ContextKey is the gin context key holding the authenticated *Key.
+const ContextKey = "apiKey"
+
+// ErrKeyNotFound is returned by a KeyStore when the key is unknown or revoked.
+var ErrKeyNotFound = errors.New("api key not found")
+
+// Key is the identity and scopes granted to an API key.
+type Key struct {
+	Identity string
+	Scopes   []string
+}
+
+// HasScope reports whether the key grants scope.
+func (k *Key) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyStore resolves raw API keys to their identity.
+type KeyStore interface {
+	Lookup(ctx context.Context, key string) (*Key, error)
+}
+
+// Hash returns the hex SHA-256 digest under which keys are stored.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// StaticKeyStore is an in-memory KeyStore. Keys are held only as hashes.
+type StaticKeyStore struct {
+	keys map[string]*Key
+}
+
+// NewStaticKeyStore creates a store from raw keys to their identity.
+func NewStaticKeyStore(keys map[string]*Key) *StaticKeyStore {
+	hashed := make(map[string]*Key, len(keys))
+	for raw, k := range keys {
+		hashed[Hash(raw)] = k
+	}
+	return &StaticKeyStore{keys: hashed}
+}
+
+// NewStaticKeyStoreFromEnv reads API_KEYS, a comma-separated list of
+// `identity:key[:scope|scope...]` entries. Malformed entries are skipped with a warning.
+func NewStaticKeyStoreFromEnv(svc *service.Service) *StaticKeyStore {
+	keys := map[string]*Key{}
+	for _, entry := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			if svc != nil && svc.Logger != nil {
+				svc.Logger.Warn("skipping malformed API_KEYS entry")
+			}
+			continue
+		}
+
+		k := &Key{Identity: parts[0]}
+		if len(parts) == 3 && parts[2] != "" {
+			k.Scopes = strings.Split(parts[2], "|")
+		}
+		keys[parts[1]] = k
+	}
+	return NewStaticKeyStore(keys)
+}
+
+// Lookup returns the identity for key.
+func (s *StaticKeyStore) Lookup(_ context.Context, key string) (*Key, error) {
+	if k, ok := s.keys[Hash(key)]; ok {
+		return k, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// DBKeyStore looks up hashed keys in a table with the columns
+// key_hash text, identity text, scopes text[] and revoked_at timestamptz.
+type DBKeyStore struct {
+	DB    service.DBTX
+	Table string
+}
+
+// NewDBKeyStore creates a store reading from table, defaulting to api_keys.
+func NewDBKeyStore(db service.DBTX, table string) *DBKeyStore {
+	if table == "" {
+		table = "api_keys"
+	}
+	return &DBKeyStore{DB: db, Table: table}
+}
+
+// Lookup returns the identity for key unless it is unknown or revoked.
+func (s *DBKeyStore) Lookup(ctx context.Context, key string) (*Key, error) {
+	query := fmt.Sprintf("SELECT identity, scopes FROM %s WHERE key_hash = $1 AND revoked_at IS NULL", pq.QuoteIdentifier(s.Table))
+
+	k := &Key{}
+	err := s.DB.QueryRowContext(ctx, query, Hash(key)).Scan(&k.Identity, pq.Array(&k.Scopes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up api key: %w", err)
+	}
+	return k, nil
+}
+
+// APIKeyMiddleware authenticates requests by API key against keys. It can replace
+// or sit alongside the Firebase middleware on a per-route basis, and sets the same
+// user id context values so logging is identical for both.
+func APIKeyMiddleware(keys KeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(Header)
+		if raw == "" {
+			if parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2); len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+				raw = parts[1]
+			}
+		}
+		if raw == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing API key"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		k, err := keys.Lookup(ctx, raw)
+		if errors.Is(err, ErrKeyNotFound) {
+			logging.FromContext(ctx).Warn("unauthorized request: unknown API key")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to verify API key: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "unable to verify API key"})
+			return
+		}
+
+		c.Set(ContextKey, k)
+		c.Set(logging.UserIDKey, k.Identity)
+		c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With(logging.FieldUserID, k.Identity)))
+		c.Next()
+	}
+}
+
+// RequireScopes rejects requests whose API key lacks any of scopes with 403.
+// It must run after APIKeyMiddleware.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := GetKey(c)
+		if k == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		for _, scope := range scopes {
+			if !k.HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("missing scope %q", scope)})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// GetKey retrieves the authenticated API key from the Gin context.
+func GetKey(c *gin.Context) *Key {
+	if v, ok := c.Get(ContextKey); ok {
+		if k, ok := v.(*Key); ok {
+			return k
+		}
+	}
+	return nil
+}
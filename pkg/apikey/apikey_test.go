@@ -0,0 +1,111 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEngine(store KeyStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/reports", APIKeyMiddleware(store), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"identity": GetKey(c).Identity, "user": c.GetString(logging.UserIDKey)})
+	})
+	r.POST("/reports", APIKeyMiddleware(store), RequireScopes("reports:write"), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return r
+}
+
+func do(r http.Handler, method string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/reports", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	store := NewStaticKeyStore(map[string]*Key{
+		"reader-key": {Identity: "etl-reader", Scopes: []string{"reports:read"}},
+		"writer-key": {Identity: "etl-writer", Scopes: []string{"reports:read", "reports:write"}},
+	})
+	r := newEngine(store)
+
+	t.Run("valid key", func(t *testing.T) {
+		w := do(r, http.MethodGet, map[string]string{Header: "reader-key"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"identity":"etl-reader","user":"etl-reader"}`, w.Body.String())
+	})
+
+	t.Run("bearer fallback", func(t *testing.T) {
+		w := do(r, http.MethodGet, map[string]string{"Authorization": "Bearer writer-key"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		w := do(r, http.MethodGet, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		w := do(r, http.MethodGet, map[string]string{Header: "nope"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("scope enforcement", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, do(r, http.MethodPost, map[string]string{Header: "reader-key"}).Code)
+		assert.Equal(t, http.StatusCreated, do(r, http.MethodPost, map[string]string{Header: "writer-key"}).Code)
+	})
+}
+
+func TestNewStaticKeyStoreFromEnv(t *testing.T) {
+	t.Setenv("API_KEYS", "etl:k1:reports:read|reports:write, billing:k2 ,broken")
+	store := NewStaticKeyStoreFromEnv(nil)
+
+	k, err := store.Lookup(context.Background(), "k1")
+	require.NoError(t, err)
+	assert.Equal(t, "etl", k.Identity)
+	assert.Equal(t, []string{"reports:read", "reports:write"}, k.Scopes)
+
+	k, err = store.Lookup(context.Background(), "k2")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", k.Identity)
+	assert.Empty(t, k.Scopes)
+
+	_, err = store.Lookup(context.Background(), "broken")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestDBKeyStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewDBKeyStore(db, "")
+	query := `SELECT identity, scopes FROM "api_keys" WHERE key_hash = \$1 AND revoked_at IS NULL`
+
+	mock.ExpectQuery(query).WithArgs(Hash("good")).
+		WillReturnRows(sqlmock.NewRows([]string{"identity", "scopes"}).AddRow("partner", "{orders:read}"))
+	mock.ExpectQuery(query).WithArgs(Hash("revoked")).
+		WillReturnRows(sqlmock.NewRows([]string{"identity", "scopes"}))
+
+	k, err := store.Lookup(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, "partner", k.Identity)
+	assert.True(t, k.HasScope("orders:read"))
+
+	_, err = store.Lookup(context.Background(), "revoked")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
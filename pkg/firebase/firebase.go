@@ -3,35 +3,132 @@ package firebase
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	fb "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
 	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
 	"google.golang.org/api/option"
 )
 
+// UserKey is the gin context key holding the verified *auth.Token.
+const UserKey = "firebaseUser"
+
 // AuthAPI defines the subset of Firebase Auth methods we use.
 // This makes it mockable in tests.
 type AuthAPI interface {
 	VerifyIDToken(ctx context.Context, token string) (*auth.Token, error)
 }
 
+// RevocationCheckAPI is implemented by Auth clients that can check whether a
+// token was revoked. It is used by VerifyToken when Config.CheckRevoked is set.
+type RevocationCheckAPI interface {
+	VerifyIDTokenAndCheckRevoked(ctx context.Context, token string) (*auth.Token, error)
+}
+
+// UserLookupAPI is implemented by Auth clients that can look up users.
+// It is used as a cheap connectivity probe by HealthCheck.
+type UserLookupAPI interface {
+	GetUser(ctx context.Context, uid string) (*auth.UserRecord, error)
+}
+
 // FirebaseService wraps a base service with Firebase integration.
 type FirebaseService struct {
 	Base   *service.Service
 	App    *fb.App
 	Auth   AuthAPI
 	Config *FirebaseConfig
+
+	healthMu        sync.Mutex
+	healthCheckedAt time.Time
+	healthErr       error
 }
 
 // FirebaseConfig defines the Firebase configuration.
 type FirebaseConfig struct {
 	CredentialsPath string
 	ProjectID       string
+	// HealthCacheTTL is how long a HealthCheck result is reused. Defaults to 30s.
+	HealthCacheTTL time.Duration
+	// InitTimeout bounds app and Auth client initialization. Defaults to 30s.
+	InitTimeout time.Duration
+	// RolePermissions maps legacy role claims to permissions for tokens without a permissions claim.
+	RolePermissions map[string][]string
+	// CheckRevoked makes VerifyToken also reject revoked tokens, at the cost of a
+	// call to Firebase per verification.
+	CheckRevoked bool
+}
+
+// defaultHealthCacheTTL bounds how often HealthCheck reaches out to Firebase.
+const defaultHealthCacheTTL = 30 * time.Second
+
+// healthProbeUID is looked up by HealthCheck; a "user not found" answer proves connectivity.
+const healthProbeUID = "svc-common-health-probe"
+
+// defaultInitTimeout bounds Firebase app and Auth client initialization at startup.
+const defaultInitTimeout = 30 * time.Second
+
+var now = time.Now
+
+// initFirebase creates the Firebase app and Auth client; swapped in tests.
+var initFirebase = func(ctx context.Context, opts []option.ClientOption) (*fb.App, AuthAPI, error) {
+	app, err := fb.NewApp(ctx, nil, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize Firebase app: %w", err)
+	}
+
+	authClient, err := app.Auth(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize Firebase Auth: %w", err)
+	}
+	return app, authClient, nil
+}
+
+// initWithTimeout runs initFirebase, giving up once timeout elapses even if the
+// underlying calls do not honor their context. An initialization that completes
+// after being abandoned has its Auth client released.
+func initWithTimeout(timeout time.Duration, opts []option.ClientOption) (*fb.App, AuthAPI, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type result struct {
+		app  *fb.App
+		auth AuthAPI
+		err  error
+	}
+	init := initFirebase
+	done := make(chan result, 1)
+	go func() {
+		app, authClient, err := init(ctx, opts)
+		done <- result{app, authClient, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.app, r.auth, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.err == nil {
+				releaseAuth(r.auth)
+			}
+		}()
+		return nil, nil, fmt.Errorf("firebase initialization did not complete within %s: %w", timeout, ctx.Err())
+	}
+}
+
+// releaseAuth closes an Auth client that holds resources. *fb.App has nothing to
+// close, so dropping the last reference to it releases it.
+func releaseAuth(authClient AuthAPI) {
+	if c, ok := authClient.(io.Closer); ok {
+		_ = c.Close()
+	}
 }
 
 // NewFirebaseService creates a new Firebase-integrated service using the base service.
@@ -53,36 +150,87 @@ func NewFirebaseService(base *service.Service, cfg *FirebaseConfig) (*FirebaseSe
 		os.Setenv("GOOGLE_CLOUD_PROJECT", cfg.ProjectID)
 	}
 
-	app, err := fb.NewApp(context.Background(), nil, opts...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Firebase app: %w", err)
+	timeout := cfg.InitTimeout
+	if timeout <= 0 {
+		timeout = defaultInitTimeout
 	}
-
-	authClient, err := app.Auth(context.Background())
+	app, authClient, err := initWithTimeout(timeout, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Firebase Auth: %w", err)
+		return nil, err
 	}
 
 	base.Logger.Info("Firebase initialized for project %s", cfg.ProjectID)
 
-	return &FirebaseService{
+	fs := &FirebaseService{
 		Base:   base,
 		App:    app,
 		Auth:   authClient,
 		Config: cfg,
-	}, nil
+	}
+	base.RegisterHealthCheck("firebase", fs.HealthCheck)
+
+	return fs, nil
 }
 
 func getConfigFromEnv() *FirebaseConfig {
-	return &FirebaseConfig{
+	cfg := &FirebaseConfig{
 		CredentialsPath: os.Getenv("FIREBASE_CREDENTIALS"),
 		ProjectID:       os.Getenv("FIREBASE_PROJECT_ID"),
+		RolePermissions: rolePermissionsFromEnv(),
+		CheckRevoked:    os.Getenv("FIREBASE_CHECK_REVOKED") == "true",
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("FIREBASE_HEALTH_CACHE_TTL")); err == nil {
+		cfg.HealthCacheTTL = ttl
 	}
+	if timeout, err := time.ParseDuration(os.Getenv("FIREBASE_INIT_TIMEOUT")); err == nil {
+		cfg.InitTimeout = timeout
+	}
+	return cfg
 }
 
-// VerifyToken verifies and decodes a Firebase ID token.
+// HealthCheck verifies Firebase Auth is reachable with the configured credentials.
+// Results are cached for Config.HealthCacheTTL to avoid hammering Firebase from probes.
+func (fs *FirebaseService) HealthCheck(ctx context.Context) error {
+	ttl := defaultHealthCacheTTL
+	if fs.Config != nil && fs.Config.HealthCacheTTL > 0 {
+		ttl = fs.Config.HealthCacheTTL
+	}
+
+	fs.healthMu.Lock()
+	defer fs.healthMu.Unlock()
+
+	if !fs.healthCheckedAt.IsZero() && now().Sub(fs.healthCheckedAt) < ttl {
+		return fs.healthErr
+	}
+
+	fs.healthErr = fs.probe(ctx)
+	fs.healthCheckedAt = now()
+	return fs.healthErr
+}
+
+func (fs *FirebaseService) probe(ctx context.Context) error {
+	if fs.Auth == nil {
+		return fmt.Errorf("firebase auth client is not initialized")
+	}
+
+	lookup, ok := fs.Auth.(UserLookupAPI)
+	if !ok {
+		return fmt.Errorf("firebase auth client %T does not support health probes", fs.Auth)
+	}
+
+	if _, err := lookup.GetUser(ctx, healthProbeUID); err != nil && !auth.IsUserNotFound(err) {
+		return fmt.Errorf("firebase health check failed: %w", err)
+	}
+	return nil
+}
+
+// VerifyToken verifies and decodes a Firebase ID token, also rejecting revoked
+// tokens when Config.CheckRevoked is set.
+// Outcomes and latency are recorded in the firebase_token_verification* metrics.
 func (fs *FirebaseService) VerifyToken(ctx context.Context, token string) (*auth.Token, error) {
-	tok, err := fs.Auth.VerifyIDToken(ctx, token)
+	start := time.Now()
+	tok, err := fs.verify(ctx, token)
+	recordVerification(start, err)
 	if err != nil {
 		fs.Base.Logger.Error("failed to verify Firebase token: %v", err)
 		return nil, fmt.Errorf("invalid Firebase token: %w", err)
@@ -90,6 +238,17 @@ func (fs *FirebaseService) VerifyToken(ctx context.Context, token string) (*auth
 	return tok, nil
 }
 
+func (fs *FirebaseService) verify(ctx context.Context, token string) (*auth.Token, error) {
+	if fs.Config == nil || !fs.Config.CheckRevoked {
+		return fs.Auth.VerifyIDToken(ctx, token)
+	}
+	checker, ok := fs.Auth.(RevocationCheckAPI)
+	if !ok {
+		return nil, fmt.Errorf("firebase auth client %T does not support revocation checks", fs.Auth)
+	}
+	return checker.VerifyIDTokenAndCheckRevoked(ctx, token)
+}
+
 // FirebaseAuthMiddleware returns a Gin middleware that validates Firebase tokens.
 func (fs *FirebaseService) FirebaseAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -113,17 +272,42 @@ func (fs *FirebaseService) FirebaseAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("firebaseUser", tok)
+		c.Set(UserKey, tok)
+		c.Set(logging.UserIDKey, tok.UID)
+
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With(logging.FieldUserID, tok.UID)))
 		c.Next()
 	}
 }
 
 // GetFirebaseUser retrieves the authenticated Firebase user from the Gin context.
 func GetFirebaseUser(c *gin.Context) *auth.Token {
-	if v, ok := c.Get("firebaseUser"); ok {
+	if v, ok := c.Get(UserKey); ok {
 		if tok, ok := v.(*auth.Token); ok {
 			return tok
 		}
 	}
 	return nil
 }
+
+// GetAuthClaims returns the claims of the token verified by FirebaseAuthMiddleware,
+// including custom claims such as permissions. It reports false when the middleware
+// did not run or rejected the request.
+func GetAuthClaims(c *gin.Context) (map[string]interface{}, bool) {
+	tok := GetFirebaseUser(c)
+	if tok == nil {
+		return nil, false
+	}
+	return tok.Claims, true
+}
+
+// TokenTenant returns the Identity Platform tenant of the token verified by
+// FirebaseAuthMiddleware, for use as http.TenantOptions.FromToken.
+func TokenTenant(c *gin.Context) (string, bool) {
+	tok := GetFirebaseUser(c)
+	if tok == nil || tok.Firebase.Tenant == "" {
+		return "", false
+	}
+	return tok.Firebase.Tenant, true
+}
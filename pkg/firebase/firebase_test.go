@@ -7,11 +7,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
+	fb "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
 )
 
 // --- Mock Firebase Auth client ---
@@ -199,7 +202,7 @@ func TestGetFirebaseUser_Helper(t *testing.T) {
 
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	tok := &auth.Token{UID: "user-xyz"}
-	c.Set("firebaseUser", tok)
+	c.Set(UserKey, tok)
 
 	res := GetFirebaseUser(c)
 	assert.NotNil(t, res)
@@ -213,3 +216,195 @@ func TestGetFirebaseUser_NoUser(t *testing.T) {
 	res := GetFirebaseUser(c)
 	assert.Nil(t, res)
 }
+
+func TestGetAuthClaims_AfterMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fs := &FirebaseService{
+		Base: newBaseService(t),
+		Auth: &mockAuthClient{
+			verifyFunc: func(ctx context.Context, token string) (*auth.Token, error) {
+				return &auth.Token{UID: "abc123", Claims: map[string]interface{}{
+					PermissionsClaim: []interface{}{"docs:read"},
+				}}, nil
+			},
+		},
+	}
+
+	r := gin.New()
+	r.GET("/secure", fs.FirebaseAuthMiddleware(), func(c *gin.Context) {
+		claims, ok := GetAuthClaims(c)
+		if !ok {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, claims)
+	})
+	r.GET("/open", func(c *gin.Context) {
+		_, ok := GetAuthClaims(c)
+		c.JSON(http.StatusOK, gin.H{"ok": ok})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"permissions":["docs:read"]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/open", nil))
+	assert.JSONEq(t, `{"ok":false}`, rec.Body.String())
+}
+
+// --- Health checks ---
+
+type mockLookupClient struct {
+	mockAuthClient
+	calls   int
+	lookErr error
+}
+
+func (m *mockLookupClient) GetUser(ctx context.Context, uid string) (*auth.UserRecord, error) {
+	m.calls++
+	if m.lookErr != nil {
+		return nil, m.lookErr
+	}
+	return &auth.UserRecord{UserInfo: &auth.UserInfo{UID: uid}}, nil
+}
+
+func TestHealthCheck_Healthy(t *testing.T) {
+	client := &mockLookupClient{}
+	fs := &FirebaseService{Base: newBaseService(t), Auth: client, Config: &FirebaseConfig{}}
+
+	assert.NoError(t, fs.HealthCheck(context.Background()))
+	assert.Equal(t, 1, client.calls)
+}
+
+func TestHealthCheck_Unhealthy(t *testing.T) {
+	client := &mockLookupClient{lookErr: errors.New("project not found")}
+	fs := &FirebaseService{Base: newBaseService(t), Auth: client, Config: &FirebaseConfig{}}
+
+	err := fs.HealthCheck(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "project not found")
+}
+
+func TestHealthCheck_CachesResult(t *testing.T) {
+	current := time.Now()
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	client := &mockLookupClient{lookErr: errors.New("unreachable")}
+	fs := &FirebaseService{Base: newBaseService(t), Auth: client, Config: &FirebaseConfig{HealthCacheTTL: time.Minute}}
+
+	assert.Error(t, fs.HealthCheck(context.Background()))
+	client.lookErr = nil
+	assert.Error(t, fs.HealthCheck(context.Background()), "cached failure is reused within the TTL")
+	assert.Equal(t, 1, client.calls)
+
+	current = current.Add(2 * time.Minute)
+	assert.NoError(t, fs.HealthCheck(context.Background()))
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestHealthCheck_UnsupportedClient(t *testing.T) {
+	fs := &FirebaseService{Base: newBaseService(t), Auth: &mockAuthClient{}}
+	assert.Error(t, fs.HealthCheck(context.Background()))
+
+	fs = &FirebaseService{Base: newBaseService(t)}
+	assert.Error(t, fs.HealthCheck(context.Background()))
+}
+
+func TestGetConfigFromEnv_HealthCacheTTL(t *testing.T) {
+	t.Setenv("FIREBASE_HEALTH_CACHE_TTL", "5s")
+	assert.Equal(t, 5*time.Second, getConfigFromEnv().HealthCacheTTL)
+}
+
+// --- Initialization timeout ---
+
+func stubInit(t *testing.T, fn func(context.Context, []option.ClientOption) (*fb.App, AuthAPI, error)) {
+	orig := initFirebase
+	initFirebase = fn
+	t.Cleanup(func() { initFirebase = orig })
+}
+
+func TestGetConfigFromEnv_InitTimeout(t *testing.T) {
+	t.Setenv("FIREBASE_INIT_TIMEOUT", "2s")
+	assert.Equal(t, 2*time.Second, getConfigFromEnv().InitTimeout)
+}
+
+func TestNewFirebaseService_InitSuccess(t *testing.T) {
+	mock := &mockAuthClient{}
+	stubInit(t, func(ctx context.Context, _ []option.ClientOption) (*fb.App, AuthAPI, error) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		return nil, mock, nil
+	})
+
+	fs, err := NewFirebaseService(newBaseService(t), &FirebaseConfig{InitTimeout: time.Second})
+	assert.NoError(t, err)
+	assert.Same(t, mock, fs.Auth)
+}
+
+func TestNewFirebaseService_InitFailure(t *testing.T) {
+	stubInit(t, func(context.Context, []option.ClientOption) (*fb.App, AuthAPI, error) {
+		return nil, nil, errors.New("bad credentials")
+	})
+
+	fs, err := NewFirebaseService(newBaseService(t), &FirebaseConfig{InitTimeout: time.Second})
+	assert.Nil(t, fs)
+	assert.ErrorContains(t, err, "bad credentials")
+}
+
+type closingAuthClient struct {
+	mockAuthClient
+	closed chan struct{}
+}
+
+func (c *closingAuthClient) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestNewFirebaseService_InitTimeout(t *testing.T) {
+	release := make(chan struct{})
+	abandoned := &closingAuthClient{closed: make(chan struct{})}
+	stubInit(t, func(context.Context, []option.ClientOption) (*fb.App, AuthAPI, error) {
+		<-release // ignores its context, like a stalled metadata lookup
+		return nil, abandoned, nil
+	})
+
+	start := time.Now()
+	fs, err := NewFirebaseService(newBaseService(t), &FirebaseConfig{InitTimeout: 20 * time.Millisecond})
+	assert.Nil(t, fs)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "did not complete within 20ms")
+	assert.Less(t, time.Since(start), time.Second)
+
+	close(release)
+	select {
+	case <-abandoned.closed:
+	case <-time.After(time.Second):
+		t.Fatal("abandoned Auth client was not closed")
+	}
+}
+
+func TestTokenTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	_, ok := TokenTenant(c)
+	assert.False(t, ok)
+
+	c.Set(UserKey, &auth.Token{UID: "abc123"})
+	_, ok = TokenTenant(c)
+	assert.False(t, ok)
+
+	tok := &auth.Token{UID: "abc123"}
+	tok.Firebase.Tenant = "acme-x1y2z"
+	c.Set(UserKey, tok)
+	tenant, ok := TokenTenant(c)
+	assert.True(t, ok)
+	assert.Equal(t, "acme-x1y2z", tenant)
+}
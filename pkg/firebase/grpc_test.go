@@ -0,0 +1,124 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newGRPCAuthService(t *testing.T) *FirebaseService {
+	return &FirebaseService{
+		Base: newBaseService(t),
+		Auth: &mockAuthClient{
+			verifyFunc: func(_ context.Context, token string) (*auth.Token, error) {
+				switch token {
+				case "editor":
+					return &auth.Token{UID: "u1", Claims: map[string]interface{}{PermissionsClaim: []interface{}{"docs:read", "docs:write"}}}, nil
+				case "viewer":
+					return &auth.Token{UID: "u2", Claims: map[string]interface{}{RoleClaim: "viewer"}}, nil
+				}
+				return nil, errors.New("bad token")
+			},
+		},
+		Config: &FirebaseConfig{RolePermissions: map[string][]string{"viewer": {"docs:read"}}},
+	}
+}
+
+// callScoped runs a unary call for method through the auth and scope interceptors.
+func callScoped(fs *FirebaseService, authorization, method string) (*auth.Token, error) {
+	ctx := context.Background()
+	if authorization != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+	}
+	scopes := map[string][]string{
+		"/docs.Docs/Get":    {"docs:read"},
+		"/docs.Docs/Update": {"docs:read", "docs:write"},
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	var seen *auth.Token
+	handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		seen = TokenFromContext(ctx)
+		return "ok", nil
+	}
+	_, err := fs.AuthUnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return fs.RequireScopesUnaryServerInterceptor(scopes)(ctx, req, info, handler)
+	})
+	return seen, err
+}
+
+func TestRequireScopesUnaryServerInterceptor(t *testing.T) {
+	fs := newGRPCAuthService(t)
+
+	tests := []struct {
+		name          string
+		authorization string
+		method        string
+		code          codes.Code
+	}{
+		{"sufficient scopes", "Bearer editor", "/docs.Docs/Update", codes.OK},
+		{"scopes from role", "Bearer viewer", "/docs.Docs/Get", codes.OK},
+		{"insufficient scopes", "Bearer viewer", "/docs.Docs/Update", codes.PermissionDenied},
+		{"unrestricted method", "Bearer viewer", "/docs.Docs/List", codes.OK},
+		{"missing metadata", "", "/docs.Docs/Get", codes.Unauthenticated},
+		{"wrong scheme", "Basic editor", "/docs.Docs/Get", codes.Unauthenticated},
+		{"invalid token", "Bearer nope", "/docs.Docs/Get", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := callScoped(fs, tt.authorization, tt.method)
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.NotNil(t, tok)
+			} else {
+				assert.Nil(t, tok)
+			}
+		})
+	}
+
+	_, err := callScoped(fs, "Bearer viewer", "/docs.Docs/Update")
+	assert.Contains(t, status.Convert(err).Message(), `missing scope "docs:write"`)
+}
+
+func TestRequireScopesUnaryServerInterceptor_Unauthenticated(t *testing.T) {
+	fs := newGRPCAuthService(t)
+	interceptor := fs.RequireScopesUnaryServerInterceptor(map[string][]string{"/docs.Docs/Get": {"docs:read"}})
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/docs.Docs/Get"}, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+// fakeStream is a grpc.ServerStream carrying only a context.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestRequireScopesStreamServerInterceptor(t *testing.T) {
+	fs := newGRPCAuthService(t)
+	scopes := map[string][]string{"/docs.Docs/Watch": {"docs:write"}}
+	info := &grpc.StreamServerInfo{FullMethod: "/docs.Docs/Watch"}
+
+	call := func(token string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		return fs.AuthStreamServerInterceptor()(nil, &fakeStream{ctx: ctx}, info, func(srv interface{}, ss grpc.ServerStream) error {
+			return fs.RequireScopesStreamServerInterceptor(scopes)(srv, ss, info, func(interface{}, grpc.ServerStream) error { return nil })
+		})
+	}
+
+	assert.NoError(t, call("editor"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("viewer")))
+}
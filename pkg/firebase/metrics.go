@@ -0,0 +1,54 @@
+package firebase
+
+import (
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/ranorsolutions/svc-common-go/pkg/metrics"
+)
+
+// Token verification outcomes recorded by VerifyToken.
+const (
+	OutcomeSuccess = "success"
+	OutcomeExpired = "expired"
+	OutcomeRevoked = "revoked"
+	OutcomeInvalid = "invalid"
+)
+
+var (
+	tokenVerifications = metrics.Default.NewCounterVec(
+		"firebase_token_verifications_total",
+		"Firebase ID token verifications by outcome.",
+		"outcome",
+	)
+	tokenVerificationDuration = metrics.Default.NewHistogramVec(
+		"firebase_token_verification_duration_seconds",
+		"Latency of Firebase ID token verification.",
+		nil,
+		"outcome",
+	)
+
+	isTokenExpired = auth.IsIDTokenExpired
+	isTokenRevoked = auth.IsIDTokenRevoked
+)
+
+// verificationOutcome classifies the result of a token verification.
+func verificationOutcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case isTokenExpired(err):
+		return OutcomeExpired
+	case isTokenRevoked(err):
+		return OutcomeRevoked
+	default:
+		return OutcomeInvalid
+	}
+}
+
+// recordVerification records the outcome and latency of a verification started at start.
+func recordVerification(start time.Time, err error) {
+	outcome := verificationOutcome(err)
+	tokenVerifications.WithLabelValues(outcome).Inc()
+	tokenVerificationDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,97 @@
+package firebase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errExpired = errors.New("token expired")
+	errRevoked = errors.New("token revoked")
+)
+
+func TestVerifyToken_RecordsOutcomes(t *testing.T) {
+	origExpired, origRevoked := isTokenExpired, isTokenRevoked
+	isTokenExpired = func(err error) bool { return errors.Is(err, errExpired) }
+	isTokenRevoked = func(err error) bool { return errors.Is(err, errRevoked) }
+	defer func() { isTokenExpired, isTokenRevoked = origExpired, origRevoked }()
+
+	results := map[string]error{
+		"good":    nil,
+		"old":     errExpired,
+		"garbage": errors.New("malformed token"),
+	}
+	fs := &FirebaseService{
+		Base: newBaseService(t),
+		Auth: &mockAuthClient{verifyFunc: func(_ context.Context, token string) (*auth.Token, error) {
+			if err := results[token]; err != nil {
+				return nil, err
+			}
+			return &auth.Token{UID: "u1"}, nil
+		}},
+	}
+
+	cases := map[string]string{
+		"good":    OutcomeSuccess,
+		"old":     OutcomeExpired,
+		"garbage": OutcomeInvalid,
+	}
+	for token, outcome := range cases {
+		before := tokenVerifications.WithLabelValues(outcome).Value()
+		observed := tokenVerificationDuration.WithLabelValues(outcome).Count()
+
+		_, _ = fs.VerifyToken(context.Background(), token)
+
+		assert.Equal(t, before+1, tokenVerifications.WithLabelValues(outcome).Value(), outcome)
+		assert.Equal(t, observed+1, tokenVerificationDuration.WithLabelValues(outcome).Count(), outcome)
+	}
+}
+
+type mockRevocationClient struct {
+	mockAuthClient
+	checkFunc func(context.Context, string) (*auth.Token, error)
+}
+
+func (m *mockRevocationClient) VerifyIDTokenAndCheckRevoked(ctx context.Context, token string) (*auth.Token, error) {
+	return m.checkFunc(ctx, token)
+}
+
+func TestVerifyToken_CheckRevoked(t *testing.T) {
+	origRevoked := isTokenRevoked
+	isTokenRevoked = func(err error) bool { return errors.Is(err, errRevoked) }
+	defer func() { isTokenRevoked = origRevoked }()
+
+	client := &mockRevocationClient{
+		mockAuthClient: mockAuthClient{verifyFunc: func(context.Context, string) (*auth.Token, error) {
+			return &auth.Token{UID: "u1"}, nil
+		}},
+		checkFunc: func(context.Context, string) (*auth.Token, error) {
+			return nil, errRevoked
+		},
+	}
+	fs := &FirebaseService{Base: newBaseService(t), Auth: client, Config: &FirebaseConfig{CheckRevoked: true}}
+
+	before := tokenVerifications.WithLabelValues(OutcomeRevoked).Value()
+	_, err := fs.VerifyToken(context.Background(), "revoked")
+	assert.ErrorIs(t, err, errRevoked)
+	assert.Equal(t, before+1, tokenVerifications.WithLabelValues(OutcomeRevoked).Value())
+
+	// Without the option the revocation check is skipped
+	fs.Config.CheckRevoked = false
+	_, err = fs.VerifyToken(context.Background(), "revoked")
+	assert.NoError(t, err)
+}
+
+func TestVerifyToken_CheckRevokedUnsupportedClient(t *testing.T) {
+	fs := &FirebaseService{
+		Base:   newBaseService(t),
+		Auth:   &mockAuthClient{verifyFunc: func(context.Context, string) (*auth.Token, error) { return &auth.Token{}, nil }},
+		Config: &FirebaseConfig{CheckRevoked: true},
+	}
+	_, err := fs.VerifyToken(context.Background(), "good")
+	assert.ErrorContains(t, err, "does not support revocation checks")
+}
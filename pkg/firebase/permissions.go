@@ -0,0 +1,129 @@
+package firebase
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// Custom claims read by permission checks.
+const (
+	PermissionsClaim = "permissions"
+	RoleClaim        = "role"
+)
+
+// Permissions returns the effective permissions of tok. The permissions claim is
+// authoritative when present; otherwise permissions are derived from the role claim
+// through Config.RolePermissions, easing migration of legacy role-only tokens.
+func (fs *FirebaseService) Permissions(tok *auth.Token) []string {
+	if tok == nil {
+		return nil
+	}
+	if raw, ok := tok.Claims[PermissionsClaim]; ok {
+		return claimStrings(raw)
+	}
+
+	role, _ := tok.Claims[RoleClaim].(string)
+	if role == "" || fs.Config == nil {
+		return nil
+	}
+	return fs.Config.RolePermissions[role]
+}
+
+// PermissionMode selects how RequirePermissionsWithMode combines required permissions.
+type PermissionMode int
+
+const (
+	// PermissionAll requires every listed permission.
+	PermissionAll PermissionMode = iota
+	// PermissionAny requires at least one listed permission.
+	PermissionAny
+)
+
+// RequirePermissions rejects requests whose Firebase user lacks any of perms with 403.
+// It must run after FirebaseAuthMiddleware.
+func (fs *FirebaseService) RequirePermissions(perms ...string) gin.HandlerFunc {
+	return fs.RequirePermissionsWithMode(perms, PermissionAll)
+}
+
+// RequirePermissionsWithMode rejects requests with 403 unless the Firebase user holds
+// all of perms (PermissionAll) or at least one of them (PermissionAny). An empty perms
+// list admits any authenticated user in either mode. It must run after FirebaseAuthMiddleware.
+func (fs *FirebaseService) RequirePermissionsWithMode(perms []string, mode PermissionMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tok := GetFirebaseUser(c)
+		if tok == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		granted := map[string]bool{}
+		for _, p := range fs.Permissions(tok) {
+			granted[p] = true
+		}
+
+		if mode == PermissionAny {
+			if len(perms) == 0 || slices.ContainsFunc(perms, func(p string) bool { return granted[p] }) {
+				c.Next()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("missing any of permissions %q", perms)})
+			return
+		}
+
+		for _, p := range perms {
+			if !granted[p] {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("missing permission %q", p)})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// claimStrings converts a decoded JSON claim into a string list.
+func claimStrings(raw interface{}) []string {
+	switch v := raw.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		return strings.Fields(strings.ReplaceAll(v, ",", " "))
+	}
+	return nil
+}
+
+// rolePermissionsFromEnv parses FIREBASE_ROLE_PERMISSIONS, formatted as
+// `role=perm|perm;role=perm`.
+func rolePermissionsFromEnv() map[string][]string {
+	raw := os.Getenv("FIREBASE_ROLE_PERMISSIONS")
+	if raw == "" {
+		return nil
+	}
+
+	roles := map[string][]string{}
+	for _, entry := range strings.Split(raw, ";") {
+		role, perms, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || role == "" {
+			continue
+		}
+		for _, p := range strings.Split(perms, "|") {
+			if p = strings.TrimSpace(p); p != "" {
+				roles[role] = append(roles[role], p)
+			}
+		}
+	}
+	return roles
+}
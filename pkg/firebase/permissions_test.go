@@ -0,0 +1,151 @@
+package firebase
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPermissionService() *FirebaseService {
+	return &FirebaseService{Config: &FirebaseConfig{
+		RolePermissions: map[string][]string{
+			"editor": {"docs:read", "docs:write"},
+			"viewer": {"docs:read"},
+		},
+	}}
+}
+
+func requireWrite(fs *FirebaseService, tok *auth.Token) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/docs", func(c *gin.Context) {
+		if tok != nil {
+			c.Set(UserKey, tok)
+		}
+		c.Next()
+	}, fs.RequirePermissions("docs:write"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	return w.Code
+}
+
+func TestRequirePermissions(t *testing.T) {
+	fs := newPermissionService()
+
+	t.Run("permissions claim only", func(t *testing.T) {
+		tok := &auth.Token{Claims: map[string]interface{}{PermissionsClaim: []interface{}{"docs:read", "docs:write"}}}
+		assert.Equal(t, http.StatusOK, requireWrite(fs, tok))
+
+		tok = &auth.Token{Claims: map[string]interface{}{PermissionsClaim: []interface{}{"docs:read"}}}
+		assert.Equal(t, http.StatusForbidden, requireWrite(fs, tok))
+	})
+
+	t.Run("role claim only", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, requireWrite(fs, &auth.Token{Claims: map[string]interface{}{RoleClaim: "editor"}}))
+		assert.Equal(t, http.StatusForbidden, requireWrite(fs, &auth.Token{Claims: map[string]interface{}{RoleClaim: "viewer"}}))
+		assert.Equal(t, http.StatusForbidden, requireWrite(fs, &auth.Token{Claims: map[string]interface{}{RoleClaim: "unknown"}}))
+	})
+
+	t.Run("permissions claim wins over role", func(t *testing.T) {
+		tok := &auth.Token{Claims: map[string]interface{}{RoleClaim: "editor", PermissionsClaim: []interface{}{"docs:read"}}}
+		assert.Equal(t, http.StatusForbidden, requireWrite(fs, tok))
+	})
+
+	t.Run("neither", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, requireWrite(fs, &auth.Token{Claims: map[string]interface{}{}}))
+		assert.Equal(t, http.StatusUnauthorized, requireWrite(fs, nil))
+	})
+}
+
+func TestRolePermissionsFromEnv(t *testing.T) {
+	t.Setenv("FIREBASE_ROLE_PERMISSIONS", "editor=docs:read|docs:write; viewer=docs:read;broken")
+	assert.Equal(t, map[string][]string{
+		"editor": {"docs:read", "docs:write"},
+		"viewer": {"docs:read"},
+	}, getConfigFromEnv().RolePermissions)
+}
+
+func TestRequirePermissions_EnforcesEveryPermission(t *testing.T) {
+	fs := newPermissionService()
+
+	cases := []struct {
+		name     string
+		required []string
+		granted  []interface{}
+		code     int
+	}{
+		{"empty required list", nil, []interface{}{}, http.StatusOK},
+		{"single present", []string{"docs:read"}, []interface{}{"docs:read"}, http.StatusOK},
+		{"single absent", []string{"docs:write"}, []interface{}{"docs:read"}, http.StatusForbidden},
+		{"all present", []string{"docs:read", "docs:write", "docs:delete"}, []interface{}{"docs:delete", "docs:read", "docs:write"}, http.StatusOK},
+		{"missing in the middle", []string{"docs:read", "docs:write", "docs:delete"}, []interface{}{"docs:read", "docs:delete"}, http.StatusForbidden},
+		{"missing first", []string{"docs:write", "docs:read"}, []interface{}{"docs:read"}, http.StatusForbidden},
+		{"duplicates in token", []string{"docs:read", "docs:write"}, []interface{}{"docs:read", "docs:read"}, http.StatusForbidden},
+		{"duplicates in token all present", []string{"docs:read", "docs:write"}, []interface{}{"docs:write", "docs:read", "docs:write"}, http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := &auth.Token{Claims: map[string]interface{}{PermissionsClaim: tc.granted}}
+			r := gin.New()
+			r.GET("/docs", func(c *gin.Context) {
+				c.Set(UserKey, tok)
+				c.Next()
+			}, fs.RequirePermissions(tc.required...), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func TestRequirePermissionsWithMode(t *testing.T) {
+	fs := newPermissionService()
+	tok := &auth.Token{Claims: map[string]interface{}{PermissionsClaim: []interface{}{"docs:read", "docs:comment"}}}
+
+	serve := func(perms []string, mode PermissionMode) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/docs", func(c *gin.Context) {
+			c.Set(UserKey, tok)
+			c.Next()
+		}, fs.RequirePermissionsWithMode(perms, mode), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		return w
+	}
+
+	cases := []struct {
+		name    string
+		perms   []string
+		all     int
+		any     int
+		message string
+	}{
+		{"none required", nil, http.StatusOK, http.StatusOK, ""},
+		{"all held", []string{"docs:read", "docs:comment"}, http.StatusOK, http.StatusOK, ""},
+		{"some held", []string{"docs:write", "docs:comment"}, http.StatusForbidden, http.StatusOK, `missing permission \"docs:write\"`},
+		{"none held", []string{"docs:write", "docs:delete"}, http.StatusForbidden, http.StatusForbidden, `missing any of permissions [\"docs:write\" \"docs:delete\"]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			all := serve(tc.perms, PermissionAll)
+			assert.Equal(t, tc.all, all.Code)
+			anyOf := serve(tc.perms, PermissionAny)
+			assert.Equal(t, tc.any, anyOf.Code)
+
+			if tc.all == http.StatusForbidden && tc.any == http.StatusOK {
+				assert.Contains(t, all.Body.String(), tc.message)
+			}
+			if tc.any == http.StatusForbidden {
+				assert.Contains(t, anyOf.Body.String(), tc.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package grpc
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/metrics"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NoiseServices are the services probes and tooling call constantly: health
+// checks and server reflection. GRPC_ACCESS_LOG_NOISE_SAMPLE sets how many of
+// their calls are logged.
+var NoiseServices = []string{
+	"/grpc.health.v1.Health",
+	"/grpc.reflection.v1.ServerReflection",
+	"/grpc.reflection.v1alpha.ServerReflection",
+}
+
+var (
+	handledCalls = metrics.Default.NewCounterVec(
+		"grpc_server_handled_total",
+		"gRPC calls completed by the server, by method, status code and calling service.",
+		"method", "code", "caller",
+	)
+	handlingDuration = metrics.Default.NewHistogramVec(
+		"grpc_server_handling_seconds",
+		"Latency of gRPC calls completed by the server, by method and calling service.",
+		nil,
+		"method", "caller",
+	)
+)
+
+// Caller labels for calls whose caller did not identify itself, and for callers
+// outside AccessLogOptions.Callers or beyond maxCallerLabels.
+const (
+	UnknownCaller = "unknown"
+	OtherCaller   = "other"
+)
+
+// maxCallerLabels bounds the distinct callers labeled when no allow-list is set, since
+// callers name themselves and each name is a new metrics series.
+const maxCallerLabels = 32
+
+// labeledCallers are the callers labeled so far without an allow-list, shared by
+// every interceptor because the metrics are.
+var labeledCallers = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+// accessLogSample draws the number compared against sample rates; swapped in tests.
+var accessLogSample = rand.Float64
+
+// AccessLogOptions configures the access log interceptors.
+type AccessLogOptions struct {
+	// Sample maps full methods, such as /grpc.health.v1.Health/Check, or services,
+	// such as /grpc.health.v1.Health, to the fraction of their calls that are logged.
+	// Zero suppresses them. Methods not listed are always logged.
+	Sample map[string]float64
+	// Callers lists the caller names given their own metrics label; others are
+	// labeled OtherCaller. When empty, the first 32 distinct callers are labeled.
+	Callers []string
+}
+
+// accessLogEnabled reports whether GRPC_ACCESS_LOG=true asks New to log every call.
+func accessLogEnabled() bool {
+	return os.Getenv("GRPC_ACCESS_LOG") == "true"
+}
+
+// accessLogOptionsFromEnv samples NoiseServices at GRPC_ACCESS_LOG_NOISE_SAMPLE,
+// suppressing them by default, and reads the known callers from the comma-separated
+// GRPC_KNOWN_CALLERS.
+func accessLogOptionsFromEnv(svc *service.Service) AccessLogOptions {
+	rate := 0.0
+	if raw := os.Getenv("GRPC_ACCESS_LOG_NOISE_SAMPLE"); raw != "" {
+		r, err := strconv.ParseFloat(raw, 64)
+		if err != nil || r < 0 || r > 1 {
+			if svc != nil && svc.Logger != nil {
+				svc.Logger.Warn("invalid GRPC_ACCESS_LOG_NOISE_SAMPLE %q, suppressing health and reflection logs", raw)
+			}
+		} else {
+			rate = r
+		}
+	}
+
+	opts := AccessLogOptions{Sample: make(map[string]float64, len(NoiseServices))}
+	for _, s := range NoiseServices {
+		opts.Sample[s] = rate
+	}
+	for _, caller := range strings.Split(os.Getenv("GRPC_KNOWN_CALLERS"), ",") {
+		if caller = strings.TrimSpace(caller); caller != "" {
+			opts.Callers = append(opts.Callers, caller)
+		}
+	}
+	return opts
+}
+
+// AccessLogUnaryServerInterceptor logs the method, status code and duration of every
+// call through the request logger, and counts it in the grpc_server_handled_total
+// and grpc_server_handling_seconds metrics, labeled with the method and the caller
+// read by CallerUnaryServerInterceptor, which must run first, within opts.Callers. Calls sampled out by opts are still
+// counted, and are logged anyway when they fail with a server error.
+func AccessLogUnaryServerInterceptor(opts AccessLogOptions) grpc.UnaryServerInterceptor {
+	known := callerSet(opts.Callers)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, opts, known, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// AccessLogStreamServerInterceptor is the streaming counterpart of
+// AccessLogUnaryServerInterceptor, logging each stream once it ends.
+func AccessLogStreamServerInterceptor(opts AccessLogOptions) grpc.StreamServerInterceptor {
+	known := callerSet(opts.Callers)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), opts, known, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, opts AccessLogOptions, known map[string]bool, method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	code := status.Code(err)
+	caller := callerLabel(CallerFromContext(ctx), known)
+	handledCalls.WithLabelValues(method, code.String(), caller).Inc()
+	handlingDuration.WithLabelValues(method, caller).Observe(elapsed.Seconds())
+
+	if !serverError(code) {
+		rate, ok := opts.Sample[method]
+		if !ok {
+			rate, ok = opts.Sample[method[:max(strings.LastIndex(method, "/"), 0)]]
+		}
+		if ok && accessLogSample() >= rate {
+			return
+		}
+	}
+
+	logging.FromContext(ctx).Info("%s code=%s duration=%s", method, code, elapsed.Round(time.Millisecond))
+}
+
+func callerSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+// callerLabel bounds the caller metrics label to known, or to the first
+// maxCallerLabels callers seen when known is empty.
+func callerLabel(caller string, known map[string]bool) string {
+	switch {
+	case caller == "":
+		return UnknownCaller
+	case len(known) > 0:
+		if known[caller] {
+			return caller
+		}
+		return OtherCaller
+	}
+
+	labeledCallers.Lock()
+	defer labeledCallers.Unlock()
+	if !labeledCallers.names[caller] {
+		if len(labeledCallers.names) >= maxCallerLabels {
+			return OtherCaller
+		}
+		labeledCallers.names[caller] = true
+	}
+	return caller
+}
+
+// serverError reports whether code means the server failed rather than the caller.
+func serverError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type recordingSink struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (s *recordingSink) add(level, format string, args []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (s *recordingSink) Info(format string, args ...interface{})  { s.add("INFO", format, args) }
+func (s *recordingSink) Warn(format string, args ...interface{})  { s.add("WARN", format, args) }
+func (s *recordingSink) Error(format string, args ...interface{}) { s.add("ERROR", format, args) }
+
+func (s *recordingSink) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+// callLogged runs the access log interceptor for method with a handler returning
+// err, and returns the lines it logged.
+func callLogged(opts AccessLogOptions, method string, err error) []string {
+	sink := &recordingSink{}
+	ctx := logging.NewContext(context.Background(), logging.New(sink))
+	_, _ = AccessLogUnaryServerInterceptor(opts)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, err })
+	return sink.Lines()
+}
+
+func TestAccessLog_LogsCalls(t *testing.T) {
+	lines := callLogged(AccessLogOptions{}, "/users.Users/Get", status.Error(codes.NotFound, "gone"))
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "INFO /users.Users/Get code=NotFound duration=")
+}
+
+func TestAccessLog_Sampling(t *testing.T) {
+	defer func(orig func() float64) { accessLogSample = orig }(accessLogSample)
+	accessLogSample = func() float64 { return 0.3 }
+
+	opts := AccessLogOptions{Sample: map[string]float64{
+		"/grpc.health.v1.Health":       0,
+		"/users.Users/List":            0.5,
+		"/users.Users/Delete":          0.1,
+		"/grpc.health.v1.Health/Watch": 1,
+	}}
+
+	assert.Empty(t, callLogged(opts, "/grpc.health.v1.Health/Check", nil))
+	assert.Len(t, callLogged(opts, "/grpc.health.v1.Health/Watch", nil), 1, "a method rate overrides its service")
+	assert.Len(t, callLogged(opts, "/users.Users/List", nil), 1)
+	assert.Empty(t, callLogged(opts, "/users.Users/Delete", nil))
+	assert.Len(t, callLogged(opts, "/users.Users/Get", nil), 1, "unlisted methods are always logged")
+}
+
+func TestAccessLog_ServerErrorsBypassSampling(t *testing.T) {
+	opts := AccessLogOptions{Sample: map[string]float64{"/grpc.health.v1.Health": 0}}
+
+	assert.Len(t, callLogged(opts, "/grpc.health.v1.Health/Check", status.Error(codes.Internal, "boom")), 1)
+	assert.Empty(t, callLogged(opts, "/grpc.health.v1.Health/Check", status.Error(codes.NotFound, "unknown service")))
+}
+
+func TestAccessLog_CountsSampledOutCalls(t *testing.T) {
+	method := "/test.Sampled/Call"
+	before := handledCalls.WithLabelValues(method, "OK", UnknownCaller).Value()
+
+	assert.Empty(t, callLogged(AccessLogOptions{Sample: map[string]float64{method: 0}}, method, nil))
+	assert.Equal(t, before+1, handledCalls.WithLabelValues(method, "OK", UnknownCaller).Value())
+	assert.NotZero(t, handlingDuration.WithLabelValues(method, UnknownCaller).Count())
+}
+
+func TestAccessLogOptionsFromEnv(t *testing.T) {
+	opts := accessLogOptionsFromEnv(nil)
+	for _, s := range NoiseServices {
+		assert.Zero(t, opts.Sample[s])
+	}
+
+	t.Setenv("GRPC_ACCESS_LOG_NOISE_SAMPLE", "0.25")
+	assert.Equal(t, 0.25, accessLogOptionsFromEnv(nil).Sample["/grpc.health.v1.Health"])
+
+	t.Setenv("GRPC_ACCESS_LOG_NOISE_SAMPLE", "2")
+	assert.Zero(t, accessLogOptionsFromEnv(newMockService(t)).Sample["/grpc.health.v1.Health"])
+}
+
+func TestNew_AccessLogSuppressesHealthChecks(t *testing.T) {
+	t.Setenv("GRPC_ACCESS_LOG", "true")
+	sink := &recordingSink{}
+	svc := newMockService(t)
+	svc.LogBatch = logging.NewBatchSink(sink, logging.BatchOptions{})
+	conn := dialBufconn(t, New(svc))
+
+	healthBefore := handledCalls.WithLabelValues("/grpc.health.v1.Health/Check", "OK", UnknownCaller).Value()
+	_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = GetInfo(context.Background(), conn)
+	require.NoError(t, err)
+	require.NoError(t, svc.LogBatch.Close(context.Background()))
+
+	var logged []string
+	for _, line := range sink.Lines() {
+		if strings.Contains(line, " code=") {
+			logged = append(logged, line)
+		}
+	}
+	require.Len(t, logged, 1)
+	assert.Contains(t, logged[0], "/"+InfoServiceName+"/GetInfo code=OK")
+	assert.Equal(t, healthBefore+1, handledCalls.WithLabelValues("/grpc.health.v1.Health/Check", "OK", UnknownCaller).Value())
+}
+
+func TestCallerLabel_BoundsCardinality(t *testing.T) {
+	known := callerSet([]string{"billing", "orders"})
+	assert.Equal(t, "billing", callerLabel("billing", known))
+	assert.Equal(t, OtherCaller, callerLabel("attacker-1", known))
+	assert.Equal(t, UnknownCaller, callerLabel("", known))
+
+	labeledCallers.Lock()
+	saved := labeledCallers.names
+	labeledCallers.names = map[string]bool{}
+	labeledCallers.Unlock()
+	defer func() {
+		labeledCallers.Lock()
+		labeledCallers.names = saved
+		labeledCallers.Unlock()
+	}()
+
+	// Without an allow-list only the first maxCallerLabels callers get their own label
+	for i := 0; i < maxCallerLabels; i++ {
+		assert.Equal(t, fmt.Sprintf("svc-%d", i), callerLabel(fmt.Sprintf("svc-%d", i), nil))
+	}
+	assert.Equal(t, OtherCaller, callerLabel("svc-new", nil))
+	assert.Equal(t, "svc-0", callerLabel("svc-0", nil), "callers already labeled keep their label")
+}
+
+func TestAccessLogOptionsFromEnv_KnownCallers(t *testing.T) {
+	t.Setenv("GRPC_KNOWN_CALLERS", "billing, orders,")
+	assert.Equal(t, []string{"billing", "orders"}, accessLogOptionsFromEnv(nil).Callers)
+}
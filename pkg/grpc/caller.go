@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type callerKey struct{}
+
+// maxCallerLength bounds the caller names accepted from metadata; longer ones are ignored.
+const maxCallerLength = 64
+
+// CallerFromContext returns the name of the calling service, or an empty string
+// if the caller did not identify itself.
+func CallerFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(callerKey{}).(string)
+	return name
+}
+
+// CallerUnaryServerInterceptor reads the caller service name from incoming metadata
+// into the context and attaches it as a field to the contextual logger. The name is
+// not authenticated, and names over 64 bytes are ignored.
+func CallerUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withIncomingCaller(ctx), req)
+	}
+}
+
+// CallerStreamServerInterceptor is the streaming counterpart of CallerUnaryServerInterceptor.
+func CallerStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withIncomingCaller(ss.Context())})
+	}
+}
+
+func withIncomingCaller(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	values := md.Get(service.CallerMetadataKey)
+	if len(values) == 0 || values[0] == "" || len(values[0]) > maxCallerLength {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, callerKey{}, values[0])
+	return logging.NewContext(ctx, logging.FromContext(ctx).With(logging.FieldCaller, values[0]))
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
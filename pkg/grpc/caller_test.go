@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestCallerTagFlowsFromClientToServer(t *testing.T) {
+	t.Setenv("GRPC_ACCESS_LOG", "true")
+	svc := newMockService(t)
+	method := "/grpc.health.v1.Health/Check"
+	handled := handledCalls.WithLabelValues(method, "OK", "billing").Value()
+	observed := handlingDuration.WithLabelValues(method, "billing").Count()
+
+	var caller, logField string
+	capture := grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		caller = CallerFromContext(ctx)
+		logField = logging.FromContext(ctx).Field(logging.FieldCaller)
+		return handler(ctx, req)
+	})
+	g := New(svc, capture)
+
+	l := bufconn.Listen(1024 * 1024)
+	go func() { _ = g.Serve(l) }()
+	defer g.GracefulStop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(service.CallerUnaryClientInterceptor("billing")),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "billing", caller)
+	assert.Equal(t, "billing", logField)
+	assert.Equal(t, handled+1, handledCalls.WithLabelValues(method, "OK", "billing").Value())
+	assert.Equal(t, observed+1, handlingDuration.WithLabelValues(method, "billing").Count())
+}
+
+func TestCallerFromContext_Missing(t *testing.T) {
+	assert.Equal(t, "", CallerFromContext(context.Background()))
+	assert.Equal(t, "", CallerFromContext(withIncomingCaller(context.Background())))
+}
+
+func TestWithIncomingCaller_IgnoresOversizedNames(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(service.CallerMetadataKey, strings.Repeat("x", maxCallerLength+1)))
+	assert.Equal(t, "", CallerFromContext(withIncomingCaller(ctx)))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(service.CallerMetadataKey, "billing"))
+	assert.Equal(t, "billing", CallerFromContext(withIncomingCaller(ctx)))
+}
@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// FieldViolation describes a single invalid request field.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Error returns a status error with a google.rpc.ErrorInfo detail, for clients to
+// branch on reason and domain rather than parse the message.
+func Error(code codes.Code, msg, reason, domain string, metadata map[string]string) error {
+	return withDetails(status.New(code, msg), &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	})
+}
+
+// InvalidArgument returns an InvalidArgument status error with a google.rpc.BadRequest
+// detail listing violations.
+func InvalidArgument(msg string, violations ...FieldViolation) error {
+	st := status.New(codes.InvalidArgument, msg)
+	if len(violations) == 0 {
+		return st.Err()
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, v := range violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	return withDetails(st, br)
+}
+
+// withDetails attaches details to st, falling back to the bare status if they cannot be encoded.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
+
+// ErrorInfoFromError extracts the google.rpc.ErrorInfo detail from a status error.
+func ErrorInfoFromError(err error) (*errdetails.ErrorInfo, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// FieldViolationsFromError extracts the field violations of a google.rpc.BadRequest
+// detail from a status error.
+func FieldViolationsFromError(err error) []FieldViolation {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	var violations []FieldViolation
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				violations = append(violations, FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		}
+	}
+	return violations
+}
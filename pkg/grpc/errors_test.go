@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestInvalidArgument_RoundTripsOverTheWire(t *testing.T) {
+	reject := grpc.ChainUnaryInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		return nil, InvalidArgument("invalid request",
+			FieldViolation{Field: "email", Description: "must be a valid email address"},
+			FieldViolation{Field: "name", Description: "is required"},
+		)
+	})
+	conn := dialBufconn(t, New(newMockService(t), reject))
+
+	_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, []FieldViolation{
+		{Field: "email", Description: "must be a valid email address"},
+		{Field: "name", Description: "is required"},
+	}, FieldViolationsFromError(err))
+
+	_, ok := ErrorInfoFromError(err)
+	assert.False(t, ok)
+}
+
+func TestError_ErrorInfo(t *testing.T) {
+	err := Error(codes.FailedPrecondition, "account suspended", "ACCOUNT_SUSPENDED", "billing.ranorsolutions.com", map[string]string{"account": "acc-1"})
+
+	// Details survive conversion to and from the wire representation
+	roundTripped := status.FromProto(status.Convert(err).Proto()).Err()
+
+	info, ok := ErrorInfoFromError(roundTripped)
+	require.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(roundTripped))
+	assert.Equal(t, "ACCOUNT_SUSPENDED", info.Reason)
+	assert.Equal(t, "billing.ranorsolutions.com", info.Domain)
+	assert.Equal(t, "acc-1", info.Metadata["account"])
+	assert.Empty(t, FieldViolationsFromError(roundTripped))
+}
+
+func TestDetailExtraction_NonStatusErrors(t *testing.T) {
+	plain := errors.New("boom")
+	_, ok := ErrorInfoFromError(plain)
+	assert.False(t, ok)
+	assert.Nil(t, FieldViolationsFromError(plain))
+
+	assert.Empty(t, status.Convert(InvalidArgument("bad")).Details())
+}
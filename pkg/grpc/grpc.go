@@ -1,9 +1,15 @@
 package grpc
 
 import (
+	"errors"
+	"fmt"
 	"net"
+	"sync"
+	"sync/atomic"
 
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/ranorsolutions/svc-common-go/pkg/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -14,14 +20,54 @@ import (
 type GRPCService struct {
 	Server  *grpc.Server
 	Service *service.Service
+
+	stopOnce sync.Once
+	stopped  atomic.Bool
 }
 
 // New creates a new gRPC server instance with default interceptors and health checks.
 func New(svc *service.Service, opts ...grpc.ServerOption) *GRPCService {
+	var sink logging.Sink
+	if svc != nil {
+		sink = svc.LogSink()
+	}
+
+	// Attach a contextual logger carrying the caller's request fields and identity,
+	// and recover handler panics so they are logged through it
+	recovery := recoveryOptionsFromEnv()
+	if svc != nil {
+		recovery.Sink = svc.PanicSink
+	}
+	// Join every call to its caller's trace, or start a sampled one, before anything logs
+	sampler := tracing.NewSampler(svc)
+	unary := []grpc.UnaryServerInterceptor{TracingUnaryServerInterceptor(sampler), logging.UnaryServerInterceptor(sink), CallerUnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{TracingStreamServerInterceptor(sampler), logging.StreamServerInterceptor(sink), CallerStreamServerInterceptor()}
+
+	// Log and count every call when asked, outside recovery so panics are logged as Internal
+	if accessLogEnabled() {
+		accessLog := accessLogOptionsFromEnv(svc)
+		unary = append(unary, AccessLogUnaryServerInterceptor(accessLog))
+		stream = append(stream, AccessLogStreamServerInterceptor(accessLog))
+	}
+
+	unary = append(unary, RecoveryUnaryServerInterceptor(recovery))
+	stream = append(stream, RecoveryStreamServerInterceptor(recovery))
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, opts...)
+
 	server := grpc.NewServer(opts...)
 
-	// Register health service for monitoring (optional)
-	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	// Register health service for monitoring; the service owns it so MonitorHealth can update it
+	hs := health.NewServer()
+	if svc != nil {
+		hs = svc.HealthServer()
+	}
+	grpc_health_v1.RegisterHealthServer(server, hs)
+
+	// Register build and dependency info for introspection
+	server.RegisterService(&infoServiceDesc, &infoServer{svc: svc})
 
 	// Enable reflection in non-production environments
 	if svc != nil && svc.Logger != nil {
@@ -40,15 +86,26 @@ func (g *GRPCService) Register(registerFunc func(*grpc.Server)) {
 	registerFunc(g.Server)
 }
 
-// ListenAndServe starts the gRPC server on the provided listener.
+// Serve starts the gRPC server on the provided listener and blocks until it stops.
+// It returns nil when the server was stopped, whether before or during Serve, and a
+// descriptive error wrapping the cause for genuine failures such as a listener that
+// was closed or broken underneath a running server.
 func (g *GRPCService) Serve(l net.Listener) error {
 	addr := l.Addr().String()
 	g.Service.Logger.Info("gRPC server listening on %s", addr)
-	return g.Server.Serve(l)
+
+	err := g.Server.Serve(l)
+	if err == nil || errors.Is(err, grpc.ErrServerStopped) || g.stopped.Load() {
+		return nil
+	}
+	return fmt.Errorf("gRPC server on %s failed: %w", addr, err)
 }
 
-// GracefulStop shuts down the server cleanly.
+// GracefulStop shuts down the server cleanly. Subsequent calls are no-ops.
 func (g *GRPCService) GracefulStop() {
-	g.Service.Logger.Info("Stopping gRPC server...")
-	g.Server.GracefulStop()
+	g.stopOnce.Do(func() {
+		g.stopped.Store(true)
+		g.Service.Logger.Info("Stopping gRPC server...")
+		g.Server.GracefulStop()
+	})
 }
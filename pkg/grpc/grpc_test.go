@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"context"
 	"net"
 	"testing"
 	"time"
@@ -8,6 +9,8 @@ import (
 	"github.com/ranorsolutions/http-common-go/pkg/log/logger"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func newMockService(t *testing.T) *service.Service {
@@ -53,3 +56,85 @@ func TestListenAndServe_ForcedStop(t *testing.T) {
 	err := g.Serve(l)
 	assert.NoError(t, err) // ✅ It's fine; stop before serve yields nil
 }
+
+func TestGracefulStop_Idempotent(t *testing.T) {
+	svc := newMockService(t)
+	g := New(svc)
+
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	done := make(chan error)
+	go func() { done <- g.Serve(l) }()
+	time.Sleep(50 * time.Millisecond)
+
+	assert.NotPanics(t, func() {
+		g.GracefulStop()
+		g.GracefulStop()
+	})
+	assert.NoError(t, <-done)
+}
+
+func TestNew_ServesServiceHealthStatus(t *testing.T) {
+	svc := newMockService(t)
+	conn := dialBufconn(t, New(svc))
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	svc.HealthServer().SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
+
+func TestServe_StoppedBeforeServeIsNotAnError(t *testing.T) {
+	g := New(newMockService(t))
+	g.GracefulStop()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.NoError(t, g.Serve(l))
+}
+
+func TestServe_GenuineFailureIsReported(t *testing.T) {
+	g := New(newMockService(t))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	err = g.Serve(l)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, net.ErrClosed)
+	assert.Contains(t, err.Error(), "gRPC server on "+addr+" failed")
+}
+
+func TestServe_ListenerClosedDuringGracefulStop(t *testing.T) {
+	g := New(newMockService(t))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- g.Serve(l) }()
+	time.Sleep(50 * time.Millisecond)
+
+	// Closing the listener as part of a shutdown is not a serve failure
+	g.stopped.Store(true)
+	require.NoError(t, l.Close())
+	assert.NoError(t, <-done)
+	g.GracefulStop()
+}
+
+func TestNew_HealthReflectsSetReady(t *testing.T) {
+	svc := newMockService(t)
+	client := grpc_health_v1.NewHealthClient(dialBufconn(t, New(svc)))
+
+	status := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	svc.SetReady(false)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, status())
+	svc.SetReady(true)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, status())
+}
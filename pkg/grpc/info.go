@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// InfoServiceName is the fully-qualified name of the built-in info service.
+const InfoServiceName = "svccommon.v1.Info"
+
+// Info describes a running service, its build and its configured dependencies.
+type Info struct {
+	Service      string
+	Version      string
+	GoVersion    string
+	Revision     string
+	BuildTime    string
+	Dependencies []string
+}
+
+// infoHandler is the server API for the info service.
+type infoHandler interface {
+	GetInfo(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+type infoServer struct {
+	svc *service.Service
+}
+
+// GetInfo reports the service name, version, build metadata and dependency names.
+func (i *infoServer) GetInfo(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	info := buildInfo(i.svc)
+
+	deps := make([]any, len(info.Dependencies))
+	for n, d := range info.Dependencies {
+		deps[n] = d
+	}
+
+	return structpb.NewStruct(map[string]any{
+		"service":      info.Service,
+		"version":      info.Version,
+		"goVersion":    info.GoVersion,
+		"revision":     info.Revision,
+		"buildTime":    info.BuildTime,
+		"dependencies": deps,
+	})
+}
+
+func buildInfo(svc *service.Service) Info {
+	info := Info{Dependencies: []string{}}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+
+	if svc != nil {
+		info.Service = svc.Name
+		info.Version = svc.Version
+		info.Dependencies = svc.DependencyNames()
+	}
+	return info
+}
+
+func getInfoHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(infoHandler).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + InfoServiceName + "/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(infoHandler).GetInfo(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var infoServiceDesc = grpc.ServiceDesc{
+	ServiceName: InfoServiceName,
+	HandlerType: (*infoHandler)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GetInfo", Handler: getInfoHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "svccommon/v1/info.proto",
+}
+
+// GetInfo calls the info service on conn and decodes the result.
+func GetInfo(ctx context.Context, conn grpc.ClientConnInterface) (*Info, error) {
+	out := new(structpb.Struct)
+	if err := conn.Invoke(ctx, "/"+InfoServiceName+"/GetInfo", new(emptypb.Empty), out); err != nil {
+		return nil, fmt.Errorf("failed to get service info: %w", err)
+	}
+
+	fields := out.AsMap()
+	str := func(key string) string {
+		v, _ := fields[key].(string)
+		return v
+	}
+
+	info := &Info{
+		Service:      str("service"),
+		Version:      str("version"),
+		GoVersion:    str("goVersion"),
+		Revision:     str("revision"),
+		BuildTime:    str("buildTime"),
+		Dependencies: []string{},
+	}
+	if deps, ok := fields["dependencies"].([]any); ok {
+		for _, d := range deps {
+			if name, ok := d.(string); ok {
+				info.Dependencies = append(info.Dependencies, name)
+			}
+		}
+	}
+	return info, nil
+}
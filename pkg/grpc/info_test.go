@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialBufconn serves g on an in-memory listener and returns a client connection to it.
+func dialBufconn(t *testing.T, g *GRPCService) *grpc.ClientConn {
+	l := bufconn.Listen(1024 * 1024)
+	go func() { _ = g.Serve(l) }()
+	t.Cleanup(g.GracefulStop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGetInfo(t *testing.T) {
+	svc := newMockService(t)
+	svc.Name, svc.Version = "billing", "1.2.3"
+	svc.ServiceConnections = map[string]*grpc.ClientConn{"users": nil, "auth": nil}
+
+	conn := dialBufconn(t, New(svc))
+
+	info, err := GetInfo(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", info.Service)
+	assert.Equal(t, "1.2.3", info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, []string{"auth", "users"}, info.Dependencies)
+}
+
+func TestGetInfo_ServiceFromConfig(t *testing.T) {
+	t.Setenv("SERVICE", "")
+	t.Setenv("VERSION", "")
+	os.Unsetenv("SERVICE")
+	os.Unsetenv("VERSION")
+
+	svc, err := service.NewWithConfig(service.Config{Name: "orders", Version: "2.0.0", NoDB: true})
+	require.NoError(t, err)
+	conn := dialBufconn(t, New(svc))
+
+	info, err := GetInfo(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", info.Service)
+	assert.Equal(t, "2.0.0", info.Version)
+}
+
+func TestGetInfo_NoDependencies(t *testing.T) {
+	conn := dialBufconn(t, New(newMockService(t)))
+
+	info, err := GetInfo(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Empty(t, info.Dependencies)
+}
+
+func TestBuildInfo_ConcurrentWithCloseDependency(t *testing.T) {
+	svc := newMockService(t)
+	svc.ServiceConnections = map[string]*grpc.ClientConn{}
+	for _, name := range []string{"users", "billing", "search"} {
+		conn, err := grpc.Dial("passthrough:///"+name, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		svc.ServiceConnections[name] = conn
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, name := range []string{"users", "billing"} {
+			assert.NoError(t, svc.CloseDependency(name))
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		assert.Contains(t, buildInfo(svc).Dependencies, "search")
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"search"}, buildInfo(svc).Dependencies)
+}
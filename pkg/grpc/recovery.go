@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryOptions configures the recovery interceptors.
+type RecoveryOptions struct {
+	// TypedPanics returns the status carried by a panic value, such as
+	// panic(status.Error(codes.NotFound, "...")), instead of Internal.
+	TypedPanics bool
+	// Sink, when set, persists recovered panics. Typed panics are not reported.
+	Sink service.PanicSink
+}
+
+// recoveryOptionsFromEnv enables typed panics when GRPC_TYPED_PANICS=true.
+func recoveryOptionsFromEnv() RecoveryOptions {
+	return RecoveryOptions{TypedPanics: os.Getenv("GRPC_TYPED_PANICS") == "true"}
+}
+
+// RecoveryUnaryServerInterceptor converts handler panics into an error so a
+// single request cannot crash the server. Panics are logged with their stack
+// through the request logger and answered with Internal, unless opts.TypedPanics
+// is set and the panic value carries a gRPC status.
+func RecoveryUnaryServerInterceptor(opts RecoveryOptions) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, recoverPanic(ctx, info.FullMethod, r, opts)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamServerInterceptor is the streaming counterpart of RecoveryUnaryServerInterceptor.
+func RecoveryStreamServerInterceptor(opts RecoveryOptions) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ss.Context(), info.FullMethod, r, opts)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recoverPanic logs the panic and maps it to the error returned to the caller.
+func recoverPanic(ctx context.Context, method string, r interface{}, opts RecoveryOptions) error {
+	if opts.TypedPanics {
+		if st, ok := panicStatus(r); ok {
+			logging.FromContext(ctx).Warn("recovered %s panic in %s: %s", st.Code(), method, st.Message())
+			return st.Err()
+		}
+	}
+
+	log := logging.FromContext(ctx)
+	stack := debug.Stack()
+	log.Error("recovered panic in %s: %v\n%s", method, r, stack)
+	service.ReportPanic(ctx, opts.Sink, service.PanicRecord{
+		Time:      time.Now().UTC(),
+		Protocol:  "grpc",
+		Method:    method,
+		RequestID: log.Field(logging.FieldRequestID),
+		UserID:    log.Field(logging.FieldUserID),
+		Message:   fmt.Sprint(r),
+		Stack:     string(stack),
+	})
+	return status.Error(codes.Internal, "internal error")
+}
+
+// panicStatus extracts a gRPC status from a panic value, ignoring OK and Unknown
+// since those do not express an intended code.
+func panicStatus(r interface{}) (*status.Status, bool) {
+	var st *status.Status
+	switch v := r.(type) {
+	case *status.Status:
+		st = v
+	case error:
+		s, ok := status.FromError(v)
+		if !ok {
+			return nil, false
+		}
+		st = s
+	default:
+		return nil, false
+	}
+
+	if st == nil || st.Code() == codes.OK || st.Code() == codes.Unknown {
+		return nil, false
+	}
+	return st, true
+}
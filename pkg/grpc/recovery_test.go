@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var unaryInfo = &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+func callPanicking(opts RecoveryOptions, value interface{}) error {
+	_, err := RecoveryUnaryServerInterceptor(opts)(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		panic(value)
+	})
+	return err
+}
+
+func TestRecovery_TypedStatusPanic(t *testing.T) {
+	err := callPanicking(RecoveryOptions{TypedPanics: true}, status.Error(codes.NotFound, "no such widget"))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "no such widget", status.Convert(err).Message())
+
+	err = callPanicking(RecoveryOptions{TypedPanics: true}, status.New(codes.PermissionDenied, "nope"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestRecovery_GenericPanicIsInternal(t *testing.T) {
+	for _, value := range []interface{}{"boom", errors.New("boom"), status.Error(codes.Unknown, "boom")} {
+		err := callPanicking(RecoveryOptions{TypedPanics: true}, value)
+		assert.Equal(t, codes.Internal, status.Code(err), "%v", value)
+		assert.Equal(t, "internal error", status.Convert(err).Message())
+	}
+}
+
+func TestRecovery_TypedPanicsDisabled(t *testing.T) {
+	err := callPanicking(RecoveryOptions{}, status.Error(codes.NotFound, "no such widget"))
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRecovery_PassesThroughWithoutPanic(t *testing.T) {
+	want := status.Error(codes.AlreadyExists, "dup")
+	resp, err := RecoveryUnaryServerInterceptor(RecoveryOptions{})(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		return "ok", want
+	})
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, want, err)
+}
+
+func TestRecovery_Stream(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}
+	err := RecoveryStreamServerInterceptor(RecoveryOptions{TypedPanics: true})(nil, &fakeServerStream{ctx: context.Background()}, info, func(interface{}, grpc.ServerStream) error {
+		panic(status.Error(codes.FailedPrecondition, "not ready"))
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestRecoveryOptionsFromEnv(t *testing.T) {
+	assert.False(t, recoveryOptionsFromEnv().TypedPanics)
+	t.Setenv("GRPC_TYPED_PANICS", "true")
+	assert.True(t, recoveryOptionsFromEnv().TypedPanics)
+}
+
+func TestNew_RecoversHandlerPanics(t *testing.T) {
+	t.Setenv("GRPC_TYPED_PANICS", "true")
+	panicking := grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		panic(status.Error(codes.NotFound, "gone"))
+	})
+	g := New(newMockService(t), panicking)
+
+	l := bufconn.Listen(1024 * 1024)
+	go func() { _ = g.Serve(l) }()
+	defer g.GracefulStop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context { return f.ctx }
+
+// recordingPanicSink captures reported panics.
+type recordingPanicSink chan service.PanicRecord
+
+func (s recordingPanicSink) RecordPanic(_ context.Context, rec service.PanicRecord) error {
+	s <- rec
+	return nil
+}
+
+func TestRecovery_ReportsPanicsToSink(t *testing.T) {
+	sink := make(recordingPanicSink, 2)
+	log := logging.New(nil).With(logging.FieldRequestID, "req-7").With(logging.FieldUserID, "user-9")
+	ctx := logging.NewContext(context.Background(), log)
+
+	_, err := RecoveryUnaryServerInterceptor(RecoveryOptions{Sink: sink})(ctx, nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	select {
+	case rec := <-sink:
+		assert.Equal(t, "grpc", rec.Protocol)
+		assert.Equal(t, "/test.Service/Get", rec.Method)
+		assert.Equal(t, "req-7", rec.RequestID)
+		assert.Equal(t, "user-9", rec.UserID)
+		assert.Equal(t, "boom", rec.Message)
+		assert.Contains(t, rec.Stack, "recovery_test.go")
+		assert.False(t, rec.Time.IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+
+	// Typed panics are intentional and not reported
+	err = callPanicking(RecoveryOptions{TypedPanics: true, Sink: sink}, status.Error(codes.NotFound, "no such widget"))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	select {
+	case rec := <-sink:
+		t.Fatalf("typed panic reported: %+v", rec)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TracingUnaryServerInterceptor joins each call to the trace of its traceparent
+// metadata, or starts a new trace, sampled by sampler.
+func TracingUnaryServerInterceptor(sampler *tracing.Sampler) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withIncomingTrace(ctx, sampler), req)
+	}
+}
+
+// TracingStreamServerInterceptor is the streaming counterpart of TracingUnaryServerInterceptor.
+func TracingStreamServerInterceptor(sampler *tracing.Sampler) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withIncomingTrace(ss.Context(), sampler)})
+	}
+}
+
+func withIncomingTrace(ctx context.Context, sampler *tracing.Sampler) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, _ = sampler.ExtractCarrier(ctx, metadataCarrier(md))
+	return ctx
+}
+
+// metadataCarrier adapts gRPC metadata to the propagation.TextMapCarrier interface.
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
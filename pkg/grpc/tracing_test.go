@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNew_TracesCalls(t *testing.T) {
+	var sc trace.SpanContext
+	capture := grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		sc = trace.SpanContextFromContext(ctx)
+		return handler(ctx, req)
+	})
+	check := func(t *testing.T, ctx context.Context) trace.SpanContext {
+		t.Helper()
+		conn := dialBufconn(t, New(newMockService(t), capture))
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		return sc
+	}
+
+	t.Run("joins the caller's trace", func(t *testing.T) {
+		t.Setenv("TRACE_SAMPLE_RATE", "1")
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+		got := check(t, ctx)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceID().String())
+		assert.False(t, got.IsSampled(), "the caller's decision wins over the rate")
+	})
+
+	t.Run("samples root traces by rate", func(t *testing.T) {
+		t.Setenv("TRACE_SAMPLE_RATE", "1")
+		got := check(t, context.Background())
+		assert.True(t, got.IsValid())
+		assert.True(t, got.IsSampled())
+
+		t.Setenv("TRACE_SAMPLE_RATE", "0")
+		got = check(t, context.Background())
+		assert.True(t, got.IsValid())
+		assert.False(t, got.IsSampled())
+	})
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+// validatorAll is implemented by protoc-gen-validate messages generated with all-errors support.
+type validatorAll interface {
+	ValidateAll() error
+}
+
+// validator is implemented by all protoc-gen-validate messages.
+type validator interface {
+	Validate() error
+}
+
+// fieldError matches the per-field errors generated by protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// multiError matches the aggregate error returned by ValidateAll.
+type multiError interface {
+	AllErrors() []error
+}
+
+// ValidationUnaryServerInterceptor rejects requests whose message fails its
+// protoc-gen-validate rules with InvalidArgument before the handler runs.
+// It is opt-in: pass it to New via grpc.ChainUnaryInterceptor.
+func ValidationUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := ValidateMessage(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamServerInterceptor validates every message received on the stream.
+func ValidationStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+// ValidateMessage runs the protoc-gen-validate rules of msg, returning an InvalidArgument
+// status error listing the failing fields, or nil for messages without rules.
+func ValidateMessage(msg interface{}) error {
+	var err error
+	switch v := msg.(type) {
+	case validatorAll:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	return invalidArgument(err)
+}
+
+// invalidArgument converts a validation error into an InvalidArgument status
+// carrying a BadRequest detail with one violation per failing field.
+func invalidArgument(err error) error {
+	errs := []error{err}
+	var multi multiError
+	if errors.As(err, &multi) {
+		errs = multi.AllErrors()
+	}
+
+	var violations []FieldViolation
+	for _, e := range errs {
+		var fe fieldError
+		if errors.As(e, &fe) {
+			violations = append(violations, FieldViolation{Field: fe.Field(), Description: fe.Reason()})
+		}
+	}
+	return InvalidArgument(err.Error(), violations...)
+}
+
+// validatingStream validates messages as they are received.
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return ValidateMessage(m)
+}
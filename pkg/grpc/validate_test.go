@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeFieldError mirrors the shape of protoc-gen-validate field errors.
+type fakeFieldError struct{ field, reason string }
+
+func (e fakeFieldError) Field() string  { return e.field }
+func (e fakeFieldError) Reason() string { return e.reason }
+func (e fakeFieldError) Error() string  { return fmt.Sprintf("invalid %s: %s", e.field, e.reason) }
+
+// fakeMultiError mirrors the aggregate error returned by ValidateAll.
+type fakeMultiError []error
+
+func (m fakeMultiError) AllErrors() []error { return m }
+func (m fakeMultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, e := range m {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type createUserRequest struct {
+	Email string
+	Name  string
+}
+
+func (r *createUserRequest) ValidateAll() error {
+	var errs fakeMultiError
+	if !strings.Contains(r.Email, "@") {
+		errs = append(errs, fakeFieldError{"Email", "value must be a valid email address"})
+	}
+	if r.Name == "" {
+		errs = append(errs, fakeFieldError{"Name", "value length must be at least 1 runes"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type pingRequest struct{ ok bool }
+
+func (r *pingRequest) Validate() error {
+	if !r.ok {
+		return errors.New("ping is invalid")
+	}
+	return nil
+}
+
+func runValidated(req interface{}) (bool, error) {
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+	_, err := ValidationUnaryServerInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/test.Users/Create"}, handler)
+	return called, err
+}
+
+func TestValidationUnaryServerInterceptor_RejectsInvalid(t *testing.T) {
+	called, err := runValidated(&createUserRequest{Email: "nope"})
+	require.Error(t, err)
+	assert.False(t, called, "handler must not run for invalid requests")
+
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, br.FieldViolations, 2)
+	assert.Equal(t, "Email", br.FieldViolations[0].Field)
+	assert.Equal(t, "Name", br.FieldViolations[1].Field)
+}
+
+func TestValidationUnaryServerInterceptor_Passes(t *testing.T) {
+	called, err := runValidated(&createUserRequest{Email: "a@b.co", Name: "Ada"})
+	require.NoError(t, err)
+	assert.True(t, called)
+
+	// Messages without validation rules pass through untouched
+	called, err = runValidated("plain")
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestValidationUnaryServerInterceptor_ValidateOnly(t *testing.T) {
+	called, err := runValidated(&pingRequest{})
+	assert.False(t, called)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "ping is invalid", st.Message())
+	assert.Empty(t, st.Details())
+
+	called, err = runValidated(&pingRequest{ok: true})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
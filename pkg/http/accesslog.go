@@ -0,0 +1,117 @@
+package http
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// accessLogSample draws the number compared against sample rates; swapped in tests.
+var accessLogSample = rand.Float64
+
+// AccessLogOptions configures AccessLogMiddleware. Paths are matched against the
+// route template, e.g. /api/v1/users/:id, or the request path for unmatched routes.
+type AccessLogOptions struct {
+	// Exclude lists paths whose requests are not logged, such as health checks.
+	// Request paths are matched as well as route templates.
+	Exclude []string
+	// Sample maps paths to the fraction of their requests that are logged, so hot
+	// routes do not flood the log. Paths not listed are always logged.
+	Sample map[string]float64
+	// Sink, when set, receives the lines instead of the request logger, for engines
+	// not built by New. Lines keep the request's log fields.
+	Sink logging.Sink
+}
+
+// accessLogEnabled reports whether ACCESS_LOG=true asks New to log every request.
+func accessLogEnabled() bool {
+	return os.Getenv("ACCESS_LOG") == "true"
+}
+
+// accessLogOptionsFromEnv reads the comma-separated excluded paths from
+// ACCESS_LOG_EXCLUDE and path=rate sample rates from ACCESS_LOG_SAMPLE.
+func accessLogOptionsFromEnv(svc *service.Service) AccessLogOptions {
+	var opts AccessLogOptions
+	for _, path := range strings.Split(os.Getenv("ACCESS_LOG_EXCLUDE"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			opts.Exclude = append(opts.Exclude, path)
+		}
+	}
+
+	for _, entry := range strings.Split(os.Getenv("ACCESS_LOG_SAMPLE"), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		path, raw, _ := strings.Cut(entry, "=")
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 1 || path == "" {
+			svc.Logger.Warn("invalid ACCESS_LOG_SAMPLE entry %q, logging every request to it", entry)
+			continue
+		}
+		if opts.Sample == nil {
+			opts.Sample = map[string]float64{}
+		}
+		opts.Sample[path] = rate
+	}
+	return opts
+}
+
+// AccessLogMiddleware logs the method, route, status and duration of every request,
+// with the request path and client IP as fields alongside the request ID and user.
+// Excluded and sampled-out requests are still logged when they fail with a server
+// error, and requests already reported by SlowRequestMiddleware are not logged twice.
+func AccessLogMiddleware(opts AccessLogOptions) gin.HandlerFunc {
+	excluded := make(map[string]bool, len(opts.Exclude))
+	for _, path := range opts.Exclude {
+		excluded[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError {
+			if excluded[route] || excluded[c.Request.URL.Path] || c.GetBool(SlowRequestKey) {
+				return
+			}
+			if rate, ok := opts.Sample[route]; ok && accessLogSample() >= rate {
+				return
+			}
+		}
+
+		logger := LoggerFromContext(c)
+		if opts.Sink != nil {
+			logger = logging.New(opts.Sink, logger.Fields()...)
+		}
+		logger = logger.With(logging.FieldPath, c.Request.URL.Path)
+		if query := c.Request.URL.RawQuery; query != "" {
+			logger = logger.With(logging.FieldQuery, logging.RedactText(query))
+		}
+		logger.With(logging.FieldClientIP, c.ClientIP()).
+			Info("%s %s status=%d duration=%s", c.Request.Method, route, status, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// RequestLogger returns AccessLogMiddleware writing through the service logger, for
+// services assembling their own engine. Requests whose path or route template is in
+// skipPaths, such as health probes, are not logged unless they fail with a server error.
+func RequestLogger(svc *service.Service, skipPaths ...string) gin.HandlerFunc {
+	opts := AccessLogOptions{Exclude: skipPaths}
+	if svc != nil {
+		opts.Sink = svc.LogSink()
+	}
+	return AccessLogMiddleware(opts)
+}
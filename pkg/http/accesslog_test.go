@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessLogEngine(sink *recordingSink, opts AccessLogOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withSink(sink), AccessLogMiddleware(opts), SlowRequestMiddleware(20*time.Millisecond))
+	r.GET("/healthz", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.GET("/search", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	r.GET("/slow", func(c *gin.Context) { time.Sleep(40 * time.Millisecond) })
+	return r
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	sink := &recordingSink{}
+	r := newAccessLogEngine(sink, AccessLogOptions{Exclude: []string{"/healthz"}, Sample: map[string]float64{"/search": 0.25}})
+
+	// Draw a predictable sequence: 1 in 4 falls under the sample rate
+	draws := []float64{0.1, 0.5, 0.75, 0.3}
+	orig := accessLogSample
+	accessLogSample = func() float64 {
+		d := draws[0]
+		draws = append(draws[1:], d)
+		return d
+	}
+	defer func() { accessLogSample = orig }()
+
+	serve := func(path string) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	serve("/healthz")
+	assert.Empty(t, sink.Lines(), "excluded paths are not logged")
+
+	for i := 0; i < 8; i++ {
+		serve("/search")
+	}
+	assert.Len(t, sink.Lines(), 2, "sampled routes log a fraction of requests")
+
+	serve("/healthz?fail=1")
+	serve("/search?fail=1")
+	serve("/search?fail=1")
+	serve("/users/42")
+	lines := sink.Lines()[2:]
+	if assert.Len(t, lines, 4, "server errors are always logged") {
+		assert.Regexp(t, `^INFO .*GET /healthz status=503 duration=\S+$`, lines[0])
+		assert.Contains(t, lines[1], "GET /search status=500")
+		assert.Contains(t, lines[2], "GET /search status=500")
+		assert.Contains(t, lines[3], "GET /users/:id status=404")
+	}
+
+	// Slow requests are only reported by the slow request log
+	serve("/slow")
+	lines = sink.Lines()[6:]
+	if assert.Len(t, lines, 1) {
+		assert.Regexp(t, `^WARN .*slow request: GET /slow`, lines[0])
+	}
+}
+
+func TestAccessLogOptionsFromEnv(t *testing.T) {
+	svc := newMockService(t)
+	t.Setenv("ACCESS_LOG_EXCLUDE", "/healthz, /metrics,")
+	t.Setenv("ACCESS_LOG_SAMPLE", "/api/v1/search=0.1, /api/v1/feed=2, bogus,/api/v1/ping=0")
+
+	opts := accessLogOptionsFromEnv(svc)
+	assert.Equal(t, []string{"/healthz", "/metrics"}, opts.Exclude)
+	assert.Equal(t, map[string]float64{"/api/v1/search": 0.1, "/api/v1/ping": 0}, opts.Sample)
+}
+
+func TestNew_AccessLog(t *testing.T) {
+	t.Setenv("ACCESS_LOG", "true")
+	t.Setenv("ACCESS_LOG_EXCLUDE", "/api/v1/ping")
+	sink := &recordingSink{}
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodGet, Path: "/ping", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }}},
+		{Method: http.MethodGet, Path: "/users", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }}},
+	}
+	svc.HTTPMiddleware = []*route.Middleware{{Name: "sink", Phase: route.PhaseContext, Priority: 10, Handler: withSink(sink)}}
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	for _, path := range []string{"/api/v1/ping", "/api/v1/users"} {
+		h.Engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	lines := sink.Lines()
+	if assert.Len(t, lines, 1) {
+		assert.Regexp(t, `request_id=\S+ route=/api/v1/users path=/api/v1/users client_ip=\S+ GET /api/v1/users status=200 duration=`, lines[0])
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	sink := &recordingSink{}
+	svc := newMockService(t)
+	svc.LogBatch = logging.NewBatchSink(sink, logging.BatchOptions{})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger(svc, "/healthz", "/users/:id"))
+	r.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		httptest.NewRequest(http.MethodGet, "/users/42", nil),
+		httptest.NewRequest(http.MethodPost, "/orders", nil),
+	} {
+		req.RemoteAddr = "203.0.113.7:4321"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.NoError(t, svc.LogBatch.Close(context.Background()))
+
+	lines := sink.Lines()
+	require.Len(t, lines, 1, "skipped paths and route templates are not logged")
+	assert.Regexp(t, `^INFO route=/orders path=/orders client_ip=203\.0\.113\.7 POST /orders status=201 duration=\S+$`, lines[0])
+}
+
+func TestAccessLogMiddleware_RedactsQuery(t *testing.T) {
+	sink := &recordingSink{}
+	r := newAccessLogEngine(sink, AccessLogOptions{})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42?expand=orders&access_token=abc123", nil))
+	require.Len(t, sink.Lines(), 1)
+	assert.Contains(t, sink.Lines()[0], "query=expand=orders&access_token=[REDACTED]")
+	assert.NotContains(t, sink.Lines()[0], "abc123")
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// adminPort reads the port of the admin listener from ADMIN_PORT. Debug endpoints are
+// only served there, away from the public API; without it they are not mounted.
+func adminPort() string {
+	return os.Getenv("ADMIN_PORT")
+}
+
+// newAdminServer returns the engine and server of the admin listener on port. Its
+// requests are recovered and logged like API requests but skip the API middleware.
+func newAdminServer(svc *service.Service, port string) (*gin.Engine, *http.Server) {
+	engine := gin.New()
+	engine.Use(RecoveryMiddleware(svc), LogContextMiddleware(svc))
+	return engine, &http.Server{
+		Addr:           fmt.Sprintf(":%s", port),
+		Handler:        engine,
+		MaxHeaderBytes: maxHeaderBytes(svc),
+	}
+}
+
+// mountDebug serves a debug endpoint on the admin engine, warning instead when there
+// is no admin listener to keep it off the public one.
+func mountDebug(svc *service.Service, admin *gin.Engine, path string, handler gin.HandlerFunc) {
+	if admin == nil {
+		svc.Logger.Warn("not serving %s: debug endpoints need an admin listener, set ADMIN_PORT", path)
+		return
+	}
+	admin.GET(path, handler)
+}
+
+// ServeAdmin serves the admin listener's endpoints on l. It returns immediately when
+// ADMIN_PORT is unset.
+func (s *HTTPService) ServeAdmin(l net.Listener) error {
+	if s.AdminServer == nil {
+		return nil
+	}
+	s.Service.Logger.Info("admin server listening on %s", formatAddr(l.Addr().String()))
+	return s.AdminServer.Serve(l)
+}
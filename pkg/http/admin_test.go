@@ -0,0 +1,42 @@
+package http
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeAdmin(t *testing.T) {
+	t.Setenv("DEBUG_CONFIG", "true")
+	t.Setenv("ADMIN_PORT", "0")
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	require.NotNil(t, h.AdminServer)
+	assert.Equal(t, ":0", h.AdminServer.Addr)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- h.ServeAdmin(l) }()
+
+	resp, err := http.Get("http://" + l.Addr().String() + DebugConfigPath)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"framework"`)
+
+	require.NoError(t, h.AdminServer.Close())
+	assert.ErrorIs(t, <-done, http.ErrServerClosed)
+}
+
+func TestServeAdmin_Disabled(t *testing.T) {
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	assert.Nil(t, h.Admin)
+	assert.NoError(t, h.ServeAdmin(nil))
+}
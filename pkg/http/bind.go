@@ -0,0 +1,70 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyOptionalKey is set on the gin context for routes declared with route.Handler.BodyOptional.
+const BodyOptionalKey = "bodyOptionalRoute"
+
+// ErrBodyRequired is returned by ShouldBindJSON when a route requiring a body got none.
+var ErrBodyRequired = errors.New("request body required")
+
+// bodyOptionalMiddleware marks the request as accepting an empty body.
+func bodyOptionalMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(BodyOptionalKey, true)
+		c.Next()
+	}
+}
+
+// ShouldBindJSON binds and validates the JSON body into obj like gin's ShouldBindJSON,
+// but reports an empty body as ErrBodyRequired instead of an opaque EOF. On routes
+// declared BodyOptional an empty body is accepted and obj is left untouched.
+func ShouldBindJSON(c *gin.Context, obj any) error {
+	if emptyBody(c.Request) {
+		if c.GetBool(BodyOptionalKey) {
+			return nil
+		}
+		return ErrBodyRequired
+	}
+	return c.ShouldBindJSON(obj)
+}
+
+// BindJSON binds the JSON body into obj with ShouldBindJSON, aborting with 400 and
+// returning false when the body is missing or invalid.
+func BindJSON(c *gin.Context, obj any) bool {
+	err := ShouldBindJSON(c, obj)
+	switch {
+	case errors.Is(err, ErrBodyRequired):
+		abortWithWarning(c, err, "a JSON request body is required", http.StatusBadRequest)
+		return false
+	case err != nil:
+		abortWithWarning(c, err, "invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// emptyBody reports whether r carries no body bytes. Bodies of unknown length are
+// peeked at and put back, so chunked requests are detected too.
+func emptyBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return true
+	}
+
+	br := bufio.NewReader(r.Body)
+	if _, err := br.Peek(1); errors.Is(err, io.EOF) {
+		return true
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{br, r.Body}
+	return false
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widgetRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func newBindEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	handler := func(c *gin.Context) {
+		req := widgetRequest{Name: "default"}
+		if !BindJSON(c, &req) {
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodPost, Path: "/widgets", Handler: []gin.HandlerFunc{handler}},
+		{Method: http.MethodPut, Path: "/widgets/touch", BodyOptional: true, Handler: []gin.HandlerFunc{handler}},
+	}
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	return h.Engine
+}
+
+func TestBindJSON(t *testing.T) {
+	engine := newBindEngine(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   io.Reader
+		chunk  bool
+		code   int
+		want   string
+	}{
+		{"body bound", http.MethodPost, "/api/v1/widgets", strings.NewReader(`{"name":"gear"}`), false, http.StatusOK, `{"name":"gear"}`},
+		{"empty body rejected", http.MethodPost, "/api/v1/widgets", nil, false, http.StatusBadRequest,
+			`{"error":"request body required","details":"a JSON request body is required"}`},
+		{"empty chunked body rejected", http.MethodPost, "/api/v1/widgets", strings.NewReader(""), true, http.StatusBadRequest,
+			`{"error":"request body required","details":"a JSON request body is required"}`},
+		{"chunked body bound", http.MethodPost, "/api/v1/widgets", strings.NewReader(`{"name":"cog"}`), true, http.StatusOK, `{"name":"cog"}`},
+		{"body-optional route accepts empty body", http.MethodPut, "/api/v1/widgets/touch", nil, false, http.StatusOK, `{"name":"default"}`},
+		{"body-optional route still validates bodies", http.MethodPut, "/api/v1/widgets/touch", strings.NewReader(`{"name":""}`), false, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, tt.body)
+			if tt.chunk {
+				req.ContentLength = -1
+			}
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.code, rec.Code)
+			if tt.want != "" {
+				assert.JSONEq(t, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBindJSON_LogsRejectionsAsWarnings(t *testing.T) {
+	sink := &recordingSink{}
+	r := gin.New()
+	r.POST("/widgets", withSink(sink), func(c *gin.Context) {
+		var req widgetRequest
+		BindJSON(c, &req)
+	})
+
+	for _, body := range []string{"", `{"name":""}`} {
+		req := httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Len(t, sink.Lines(), 2)
+	for _, line := range sink.Lines() {
+		assert.True(t, strings.HasPrefix(line, "WARN "), line)
+	}
+}
@@ -0,0 +1,328 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheStatusHeader reports how a cached route was served: HIT, STALE or MISS.
+const CacheStatusHeader = "X-Cache"
+
+// CachedResponse is a captured response stored by the cache middleware.
+type CachedResponse struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	StoredAt time.Time
+}
+
+// CacheStore persists cached responses. Implementations must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+}
+
+// CacheConfig configures CacheMiddleware.
+type CacheConfig struct {
+	// TTL is how long a response is fresh and served without revalidation.
+	TTL time.Duration
+	// StaleWhileRevalidate is how long past TTL a stale response is still served
+	// while it is refreshed after being sent. Past it, requests block on a fresh fetch.
+	StaleWhileRevalidate time.Duration
+	// RefreshTimeout bounds refreshes of stale responses. Defaults to 30s.
+	RefreshTimeout time.Duration
+	// Store holds cached responses. Defaults to an in-memory store of 1000 entries.
+	Store CacheStore
+	// Key derives the cache key. Defaults to the request URI.
+	Key func(c *gin.Context) string
+}
+
+// cachedHeaders lists the representation headers stored with a cached response.
+// Everything else, such as Set-Cookie or headers set by earlier middleware, is
+// left to each request.
+var cachedHeaders = []string{"Content-Type", "Content-Encoding", "Content-Language", "Content-Disposition", "ETag", "Last-Modified"}
+
+// CacheMiddleware caches successful GET responses with stale-while-revalidate semantics.
+// Requests carrying Authorization or Cookie headers bypass the cache, and responses
+// setting cookies or marked Cache-Control private or no-store are not stored.
+// Entries are keyed by the tenant and the request headers named in the response's
+// Vary header as well as Key. A stale response is sent to the client in full before
+// the rest of the handler chain re-runs to refresh it, so refreshes pass through
+// the same middleware as any other request.
+func CacheMiddleware(cfg CacheConfig) gin.HandlerFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryCacheStore(1000)
+	}
+	if cfg.Key == nil {
+		cfg.Key = func(c *gin.Context) string { return c.Request.URL.RequestURI() }
+	}
+	if cfg.RefreshTimeout <= 0 {
+		cfg.RefreshTimeout = 30 * time.Second
+	}
+
+	cacheControl := fmt.Sprintf("max-age=%d, stale-while-revalidate=%d",
+		int(cfg.TTL.Seconds()), int(cfg.StaleWhileRevalidate.Seconds()))
+
+	var mu sync.Mutex
+	refreshing := map[string]bool{}
+	// varyByRoute remembers the Vary header names each route responded with
+	varyByRoute := map[string][]string{}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || c.GetHeader("Authorization") != "" || c.GetHeader("Cookie") != "" {
+			c.Next()
+			return
+		}
+
+		mu.Lock()
+		vary := varyByRoute[c.FullPath()]
+		mu.Unlock()
+		key := cacheKey(c, cfg.Key(c), vary)
+
+		if cached, ok := cfg.Store.Get(key); ok {
+			age := time.Since(cached.StoredAt)
+			switch {
+			case age < cfg.TTL:
+				writeCached(c, cached, "HIT", cacheControl)
+				c.Abort()
+				return
+			case age < cfg.TTL+cfg.StaleWhileRevalidate:
+				mu.Lock()
+				start := !refreshing[key]
+				refreshing[key] = true
+				mu.Unlock()
+
+				writeCached(c, cached, "STALE", cacheControl)
+				if !start {
+					c.Abort()
+					return
+				}
+				defer func() {
+					mu.Lock()
+					delete(refreshing, key)
+					mu.Unlock()
+				}()
+				refresh(c, cfg, key, vary)
+				return
+			}
+		}
+
+		// Miss or too stale: fetch synchronously
+		w := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Header(CacheStatusHeader, "MISS")
+		c.Header("Cache-Control", cacheControl)
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if !storable(w.Status(), w.Header()) {
+			return
+		}
+		names := varyNames(w.Header())
+		if !slices.Equal(names, vary) {
+			mu.Lock()
+			varyByRoute[c.FullPath()] = names
+			mu.Unlock()
+			key = cacheKey(c, cfg.Key(c), names)
+		}
+		cfg.Store.Set(key, newCachedResponse(w.Status(), w.Header(), w.body.Bytes()))
+	}
+}
+
+// refresh re-runs the rest of the chain for a request already answered from a stale
+// entry, storing the new response under key. The request context is detached from
+// the client, which has its response, and bounded by cfg.RefreshTimeout.
+func refresh(c *gin.Context, cfg CacheConfig, key string, vary []string) {
+	c.Writer.Flush()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), cfg.RefreshTimeout)
+	defer cancel()
+	orig, origReq := c.Writer, c.Request
+	w := &detachedWriter{ResponseWriter: orig, header: http.Header{}}
+	c.Writer = w
+	c.Request = c.Request.WithContext(ctx)
+	defer func() {
+		c.Writer = orig
+		c.Request = origReq
+	}()
+
+	c.Next()
+
+	status := w.Status()
+	if status != http.StatusOK {
+		LoggerFromContext(c).Warn("cache refresh for %s returned status %d, keeping stale response", key, status)
+		return
+	}
+	if storable(status, w.header) && slices.Equal(varyNames(w.header), vary) {
+		cfg.Store.Set(key, newCachedResponse(status, w.header, w.body.Bytes()))
+	}
+}
+
+// cacheKey extends base with the request's tenant and the values of the vary headers.
+func cacheKey(c *gin.Context, base string, vary []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	if tenant := TenantFromContext(c); tenant != "" {
+		b.WriteString("\x00tenant=" + tenant)
+	}
+	for _, name := range vary {
+		b.WriteString("\x00" + name + "=" + strings.Join(c.Request.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// varyNames returns the canonical, sorted header names listed in h's Vary header.
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// storable reports whether a response may be stored for other clients.
+func storable(status int, h http.Header) bool {
+	if status != http.StatusOK || h.Get("Set-Cookie") != "" || slices.Contains(varyNames(h), "*") {
+		return false
+	}
+	cc := strings.ToLower(strings.Join(h.Values("Cache-Control"), ","))
+	return !strings.Contains(cc, "private") && !strings.Contains(cc, "no-store")
+}
+
+func newCachedResponse(status int, h http.Header, body []byte) *CachedResponse {
+	header := http.Header{}
+	for _, name := range cachedHeaders {
+		if v := h.Values(name); len(v) > 0 {
+			header[http.CanonicalHeaderKey(name)] = append([]string(nil), v...)
+		}
+	}
+	return &CachedResponse{
+		Status:   status,
+		Header:   header,
+		Body:     append([]byte(nil), body...),
+		StoredAt: time.Now(),
+	}
+}
+
+// writeCached writes cached to the client. Its Content-Length lets clients finish
+// reading a stale response while the request goes on to refresh it.
+func writeCached(c *gin.Context, cached *CachedResponse, status, cacheControl string) {
+	for k, values := range cached.Header {
+		c.Writer.Header()[k] = append([]string(nil), values...)
+	}
+	c.Header(CacheStatusHeader, status)
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Content-Length", strconv.Itoa(len(cached.Body)))
+	c.Data(cached.Status, cached.Header.Get("Content-Type"), cached.Body)
+}
+
+// captureWriter tees the response body to a buffer while writing it to the client.
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// detachedWriter collects a refreshed response without sending it to the client,
+// which was already answered from the cache.
+type detachedWriter struct {
+	gin.ResponseWriter
+	header http.Header
+	body   bytes.Buffer
+	status int
+	size   int
+}
+
+func (w *detachedWriter) Header() http.Header { return w.header }
+
+func (w *detachedWriter) WriteHeader(code int) {
+	if w.size == 0 && code > 0 {
+		w.status = code
+	}
+}
+
+func (w *detachedWriter) WriteHeaderNow() {}
+
+func (w *detachedWriter) Write(b []byte) (int, error) {
+	w.size += len(b)
+	return w.body.Write(b)
+}
+
+func (w *detachedWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *detachedWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *detachedWriter) Size() int { return w.size }
+
+func (w *detachedWriter) Written() bool { return w.size > 0 }
+
+func (w *detachedWriter) Flush() {}
+
+// MemoryCacheStore is a bounded in-memory CacheStore evicting the oldest entry when full.
+type MemoryCacheStore struct {
+	mu         sync.RWMutex
+	maxEntries int
+	entries    map[string]*CachedResponse
+}
+
+// NewMemoryCacheStore creates an in-memory store holding at most maxEntries responses.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCacheStore{maxEntries: maxEntries, entries: map[string]*CachedResponse{}}
+}
+
+// Get returns the cached response for key.
+func (m *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resp, ok := m.entries[key]
+	return resp, ok
+}
+
+// Set stores resp under key, evicting the oldest entry if the store is full.
+func (m *MemoryCacheStore) Set(key string, resp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.entries[key]; !exists && len(m.entries) >= m.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range m.entries {
+			if oldestKey == "" || e.StoredAt.Before(oldest) {
+				oldestKey, oldest = k, e.StoredAt
+			}
+		}
+		delete(m.entries, oldestKey)
+	}
+	m.entries[key] = resp
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheEngine(cfg CacheConfig, calls *atomic.Int32) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/reports/:id", CacheMiddleware(cfg), func(c *gin.Context) {
+		n := calls.Add(1)
+		c.String(http.StatusOK, fmt.Sprintf("%s-v%d", c.Param("id"), n))
+	})
+	return r
+}
+
+func get(r http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestCacheMiddleware_FreshHit(t *testing.T) {
+	var calls atomic.Int32
+	r := newCacheEngine(CacheConfig{TTL: time.Minute}, &calls)
+
+	first := get(r, "/reports/a")
+	assert.Equal(t, "MISS", first.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "max-age=60, stale-while-revalidate=0", first.Header().Get("Cache-Control"))
+	assert.Equal(t, "a-v1", first.Body.String())
+
+	second := get(r, "/reports/a")
+	assert.Equal(t, "HIT", second.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "a-v1", second.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Different keys are cached independently
+	assert.Equal(t, "b-v2", get(r, "/reports/b").Body.String())
+}
+
+func TestCacheMiddleware_StaleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int32
+	store := NewMemoryCacheStore(10)
+	r := newCacheEngine(CacheConfig{TTL: 20 * time.Millisecond, StaleWhileRevalidate: time.Minute, Store: store}, &calls)
+
+	assert.Equal(t, "a-v1", get(r, "/reports/a").Body.String())
+	time.Sleep(30 * time.Millisecond)
+
+	// Stale response is served immediately while a refresh runs in the background
+	stale := get(r, "/reports/a")
+	assert.Equal(t, "STALE", stale.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "a-v1", stale.Body.String())
+
+	assert.Eventually(t, func() bool {
+		cached, ok := store.Get("/reports/a")
+		return ok && string(cached.Body) == "a-v2"
+	}, time.Second, 5*time.Millisecond)
+
+	fresh := get(r, "/reports/a")
+	assert.Equal(t, "HIT", fresh.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "a-v2", fresh.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCacheMiddleware_BeyondMaxStaleBlocks(t *testing.T) {
+	var calls atomic.Int32
+	r := newCacheEngine(CacheConfig{TTL: 10 * time.Millisecond, StaleWhileRevalidate: 10 * time.Millisecond}, &calls)
+
+	assert.Equal(t, "a-v1", get(r, "/reports/a").Body.String())
+	time.Sleep(30 * time.Millisecond)
+
+	rec := get(r, "/reports/a")
+	assert.Equal(t, "MISS", rec.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "a-v2", rec.Body.String())
+}
+
+func TestCacheMiddleware_SkipsErrorsAndWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	r := gin.New()
+	r.Use(CacheMiddleware(CacheConfig{TTL: time.Minute}))
+	r.GET("/fail", func(c *gin.Context) {
+		calls.Add(1)
+		c.Status(http.StatusInternalServerError)
+	})
+	r.POST("/fail", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	get(r, "/fail")
+	get(r, "/fail")
+	assert.Equal(t, int32(2), calls.Load(), "error responses are not cached")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fail", nil))
+	assert.Empty(t, rec.Header().Get(CacheStatusHeader))
+}
+
+func TestMemoryCacheStore_EvictsOldest(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	base := time.Now()
+	store.Set("a", &CachedResponse{StoredAt: base})
+	store.Set("b", &CachedResponse{StoredAt: base.Add(time.Second)})
+	store.Set("c", &CachedResponse{StoredAt: base.Add(2 * time.Second)})
+
+	_, ok := store.Get("a")
+	assert.False(t, ok)
+	_, ok = store.Get("c")
+	assert.True(t, ok)
+}
+
+func TestCacheMiddleware_StoresOnlyRepresentationHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Header(RequestIDHeader, "req-1") })
+	r.GET("/reports", CacheMiddleware(CacheConfig{TTL: time.Minute}), func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.Header("X-Debug", "handler")
+		c.String(http.StatusOK, "report")
+	})
+
+	get(r, "/reports")
+	hit := get(r, "/reports")
+	assert.Equal(t, "HIT", hit.Header().Get(CacheStatusHeader))
+	assert.Equal(t, []string{"req-1"}, hit.Header().Values(RequestIDHeader), "headers of earlier middleware are not replayed")
+	assert.Equal(t, `"v1"`, hit.Header().Get("ETag"))
+	assert.Empty(t, hit.Header().Get("X-Debug"))
+	assert.Equal(t, "6", hit.Header().Get("Content-Length"))
+}
+
+func TestCacheMiddleware_SkipsPrivateResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	r := gin.New()
+	r.Use(CacheMiddleware(CacheConfig{TTL: time.Minute}))
+	r.GET("/cookie", func(c *gin.Context) {
+		calls.Add(1)
+		c.SetCookie("session", "abc", 60, "/", "", true, true)
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/private", func(c *gin.Context) {
+		calls.Add(1)
+		c.Header("Cache-Control", "private, max-age=60")
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/public", func(c *gin.Context) {
+		calls.Add(1)
+		c.String(http.StatusOK, "ok")
+	})
+
+	get(r, "/cookie")
+	assert.NotEqual(t, "HIT", get(r, "/cookie").Header().Get(CacheStatusHeader))
+	get(r, "/private")
+	assert.NotEqual(t, "HIT", get(r, "/private").Header().Get(CacheStatusHeader))
+	assert.Equal(t, int32(4), calls.Load())
+
+	get(r, "/public")
+	for _, header := range []string{"Authorization", "Cookie"} {
+		req := httptest.NewRequest(http.MethodGet, "/public", nil)
+		req.Header.Set(header, "secret")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Empty(t, rec.Header().Get(CacheStatusHeader), header)
+	}
+	assert.Equal(t, int32(7), calls.Load(), "credentialed requests bypass the cache")
+}
+
+func TestCacheMiddleware_KeysByTenantAndVary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if tenant := c.GetHeader(TenantHeader); tenant != "" {
+			c.Set(TenantKey, tenant)
+		}
+	})
+	r.GET("/reports", CacheMiddleware(CacheConfig{TTL: time.Minute}), func(c *gin.Context) {
+		n := calls.Add(1)
+		c.Header("Vary", "Accept-Language")
+		c.String(http.StatusOK, fmt.Sprintf("%s-%s-v%d", TenantFromContext(c), c.GetHeader("Accept-Language"), n))
+	})
+	serve := func(tenant, lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+		req.Header.Set(TenantHeader, tenant)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "acme-en-v1", serve("acme", "en").Body.String())
+	assert.Equal(t, "globex-en-v2", serve("globex", "en").Body.String())
+	assert.Equal(t, "acme-fr-v3", serve("acme", "fr").Body.String())
+
+	hit := serve("acme", "en")
+	assert.Equal(t, "HIT", hit.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "acme-en-v1", hit.Body.String())
+	assert.Equal(t, "globex-en-v2", serve("globex", "en").Body.String())
+	assert.Equal(t, "acme-fr-v3", serve("acme", "fr").Body.String())
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCacheMiddleware_RefreshRunsRouteMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls, authorized atomic.Int32
+	r := gin.New()
+	r.GET("/reports", CacheMiddleware(CacheConfig{TTL: 10 * time.Millisecond, StaleWhileRevalidate: time.Minute}),
+		func(c *gin.Context) {
+			authorized.Add(1)
+			c.Set("user", "svc")
+		},
+		func(c *gin.Context) {
+			n := calls.Add(1)
+			c.String(http.StatusOK, fmt.Sprintf("%s-v%d", c.GetString("user"), n))
+		})
+
+	assert.Equal(t, "svc-v1", get(r, "/reports").Body.String())
+	time.Sleep(20 * time.Millisecond)
+
+	stale := get(r, "/reports")
+	assert.Equal(t, "STALE", stale.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "svc-v1", stale.Body.String())
+	assert.Equal(t, int32(2), authorized.Load())
+	assert.Equal(t, "svc-v2", get(r, "/reports").Body.String())
+}
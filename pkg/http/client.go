@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Outbound client defaults.
+const (
+	defaultClientTimeout = 10 * time.Second
+	defaultRetryBackoff  = 100 * time.Millisecond
+)
+
+// ClientOptions configures NewHTTPClient.
+type ClientOptions struct {
+	// Timeout bounds each request including retries. Defaults to 10s.
+	Timeout time.Duration
+	// MaxRetries is the number of retries for idempotent requests. Defaults to 0.
+	MaxRetries int
+	// RetryBackoff is the initial delay between retries, doubled per attempt. Defaults to 100ms.
+	RetryBackoff time.Duration
+	// Headers are set on every request that does not already carry them.
+	Headers map[string]string
+	// Authorize, when set, is called on every attempt to add credentials.
+	Authorize func(req *http.Request) error
+	// Transport is the underlying transport. Defaults to a clone of http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// BearerToken returns an Authorize func setting a static bearer token.
+func BearerToken(token string) func(*http.Request) error {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// NewHTTPClient returns a client for calling external HTTP APIs. It propagates the
+// trace context (traceparent) and request ID from the request context, injects
+// static and auth headers, and retries idempotent requests on connection errors
+// and 502, 503 and 504 responses.
+func NewHTTPClient(opts ClientOptions) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultClientTimeout
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: &clientTransport{opts: opts},
+	}
+}
+
+type clientTransport struct {
+	opts ClientOptions
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := 0
+	if retryable(req) {
+		retries = t.opts.MaxRetries
+	}
+
+	backoff := t.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		r, err := t.prepare(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.opts.Transport.RoundTrip(r)
+		if attempt >= retries || !transient(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		logging.FromContext(req.Context()).Warn("retrying %s %s after attempt %d: %s", req.Method, req.URL.Redacted(), attempt+1, describe(resp, err))
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// prepare clones req for an attempt, rewinding the body and adding headers.
+func (t *clientTransport) prepare(req *http.Request, attempt int) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		r.Body = body
+	}
+
+	for k, v := range t.opts.Headers {
+		if r.Header.Get(k) == "" {
+			r.Header.Set(k, v)
+		}
+	}
+	injectContext(r.Context(), r.Header)
+
+	if t.opts.Authorize != nil {
+		if err := t.opts.Authorize(r); err != nil {
+			return nil, fmt.Errorf("failed to authorize request: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// injectContext propagates the trace context and request ID to an outbound request.
+func injectContext(ctx context.Context, h http.Header) {
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(h))
+	if id := logging.FromContext(ctx).Field(logging.FieldRequestID); id != "" && h.Get(RequestIDHeader) == "" {
+		h.Set(RequestIDHeader, id)
+	}
+}
+
+// retryable reports whether req may safely be sent more than once.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// transient reports whether an attempt failed in a way worth retrying.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func describe(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
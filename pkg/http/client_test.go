@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(ClientOptions{Timeout: 50 * time.Millisecond, MaxRetries: 3})
+	start := time.Now()
+	_, err := client.Get(srv.URL)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "timeouts are not retried past the deadline")
+}
+
+func TestNewHTTPClient_RetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(ClientOptions{MaxRetries: 3, RetryBackoff: time.Millisecond})
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies, "the body is replayed on every attempt")
+}
+
+func TestNewHTTPClient_DoesNotRetryNonIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(ClientOptions{MaxRetries: 3, RetryBackoff: time.Millisecond})
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// An idempotency key makes POST safe to retry
+	calls.Store(0)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("{}"))
+	req.Header.Set("Idempotency-Key", "abc")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestNewHTTPClient_InjectsHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(ClientOptions{
+		Headers:   map[string]string{"User-Agent": "billing/1.0", "X-Tenant": "acme"},
+		Authorize: BearerToken("s3cret"),
+	})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = logging.NewContext(ctx, logging.New(nil).With(logging.FieldRequestID, "req-1"))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	req.Header.Set("X-Tenant", "override")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "Bearer s3cret", got.Get("Authorization"))
+	assert.Equal(t, "billing/1.0", got.Get("User-Agent"))
+	assert.Equal(t, "override", got.Get("X-Tenant"), "request headers win over defaults")
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", got.Get("Traceparent"))
+	assert.Equal(t, "req-1", got.Get(RequestIDHeader))
+}
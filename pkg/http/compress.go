@@ -0,0 +1,235 @@
+package http
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionConfig configures response compression in New.
+type CompressionConfig struct {
+	// Level is a compress/flate level, such as gzip.BestSpeed. Zero and invalid
+	// levels use gzip.DefaultCompression.
+	Level int
+	// MinLength is the body size below which responses are sent uncompressed.
+	// Defaults to 1024 bytes.
+	MinLength int
+}
+
+const defaultCompressionMinLength = 1024
+
+// incompressibleTypes lists content type prefixes whose bodies are already compressed.
+var incompressibleTypes = []string{
+	"image/", "audio/", "video/", "font/woff",
+	"application/gzip", "application/x-gzip", "application/zip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/x-bzip2",
+	"application/pdf",
+}
+
+// Compression returns middleware compressing responses with gzip or deflate, as
+// negotiated through Accept-Encoding. Bodies shorter than minLength, responses
+// with an already-compressed content type or a Content-Encoding of their own, and
+// streams flushed before reaching minLength are sent as written. level is a
+// compress/flate level; zero and invalid levels use the default.
+func Compression(level int, minLength int) gin.HandlerFunc {
+	if level == 0 || level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	if minLength <= 0 {
+		minLength = defaultCompressionMinLength
+	}
+
+	gzipWriters := sync.Pool{New: func() any {
+		zw, _ := gzip.NewWriterLevel(io.Discard, level)
+		return zw
+	}}
+	flateWriters := sync.Pool{New: func() any {
+		zw, _ := flate.NewWriter(io.Discard, level)
+		return zw
+	}}
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		orig := c.Writer
+		w := &compressWriter{ResponseWriter: orig, encoding: encoding, minLength: minLength}
+		if encoding == "gzip" {
+			w.pool = &gzipWriters
+		} else {
+			w.pool = &flateWriters
+		}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = orig
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header, preferring
+// gzip and honoring q values. It returns "" when neither is acceptable.
+func negotiateEncoding(header string) string {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range []string{"gzip", "deflate"} {
+		q, ok := accepted[enc]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressible reports whether a response with header h and status code may be compressed.
+func compressible(h http.Header, code int) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusPartialContent || code == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// compressWriter buffers the start of a response until minLength bytes decide
+// whether it is worth compressing, then streams it through the encoder or as is.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding  string
+	minLength int
+	pool      *sync.Pool
+
+	buf     []byte
+	decided bool
+	zw      interface {
+		io.WriteCloser
+		Flush() error
+	}
+}
+
+// decide commits to compressing the response when compress allows it, and writes
+// out the buffered bytes.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	h := w.ResponseWriter.Header()
+	if compress && compressible(h, w.ResponseWriter.Status()) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		switch zw := w.pool.Get().(type) {
+		case *gzip.Writer:
+			zw.Reset(w.ResponseWriter)
+			w.zw = zw
+		case *flate.Writer:
+			zw.Reset(w.ResponseWriter)
+			w.zw = zw
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+func (w *compressWriter) write(b []byte) (int, error) {
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// finish writes out a response still buffered and closes the encoder.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.zw != nil {
+		_ = w.zw.Close()
+		w.pool.Put(w.zw)
+		w.zw = nil
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		return w.write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minLength {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Written also reports bytes still buffered, so handlers see their output as written.
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends streamed output promptly; streams flushed before reaching minLength
+// are sent uncompressed.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
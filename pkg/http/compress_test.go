@@ -0,0 +1,140 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var largeBody = strings.Repeat(`{"name":"widget","tags":["a","b","c"]},`, 100)
+
+func newCompressionService(t *testing.T) *HTTPService {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodGet, Path: "/large", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Header("Content-Length", "9999")
+			c.Data(http.StatusOK, "application/json", []byte(largeBody))
+		}}},
+		{Method: http.MethodGet, Path: "/small", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		}}},
+		{Method: http.MethodGet, Path: "/image", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Data(http.StatusOK, "image/png", []byte(largeBody))
+		}}},
+		{Method: http.MethodGet, Path: "/empty", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		}}},
+	}
+	h, err := New(svc, "v1", Option{Compression: &CompressionConfig{Level: gzip.BestSpeed, MinLength: 512}})
+	require.NoError(t, err)
+	return h
+}
+
+func getCompressed(h *HTTPService, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1"+path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	h.Engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompression(t *testing.T) {
+	h := newCompressionService(t)
+
+	t.Run("gzip", func(t *testing.T) {
+		w := getCompressed(h, "/large", "gzip, deflate")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Header().Get("Content-Length"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Less(t, w.Body.Len(), len(largeBody))
+
+		zr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("deflate", func(t *testing.T) {
+		w := getCompressed(h, "/large", "deflate")
+		assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+
+		body, err := io.ReadAll(flate.NewReader(w.Body))
+		require.NoError(t, err)
+		assert.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		for _, accept := range []string{"", "br", "gzip;q=0, identity"} {
+			w := getCompressed(h, "/large", accept)
+			assert.Empty(t, w.Header().Get("Content-Encoding"), accept)
+			assert.Equal(t, largeBody, w.Body.String(), accept)
+		}
+	})
+
+	t.Run("below min length", func(t *testing.T) {
+		w := getCompressed(h, "/small", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+	})
+
+	t.Run("already compressed type", func(t *testing.T) {
+		w := getCompressed(h, "/image", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, w.Body.String())
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		w := getCompressed(h, "/empty", "gzip")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+	})
+}
+
+func TestCompression_StreamsFlushedEarlyAreUncompressed(t *testing.T) {
+	engine := gin.New()
+	engine.Use(Compression(0, 0))
+	engine.GET("/events", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("data: hello\n\n")
+		c.Writer.Flush()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "data: hello\n\n", w.Body.String())
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                       "",
+		"gzip":                   "gzip",
+		"deflate, gzip":          "gzip",
+		"gzip;q=0.5, deflate":    "deflate",
+		"GZIP":                   "gzip",
+		"*":                      "gzip",
+		"*;q=0":                  "",
+		"br, deflate;q=0.1":      "deflate",
+		"gzip;q=0, deflate;q=0":  "",
+		"identity, gzip;q=bogus": "",
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, negotiateEncoding(header), header)
+	}
+}
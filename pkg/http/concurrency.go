@@ -0,0 +1,54 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrConcurrencyLimit is reported when a route is running at its MaxConcurrent limit.
+var ErrConcurrencyLimit = errors.New("route concurrency limit reached")
+
+// ConcurrencyLimitMiddleware lets at most max requests through concurrently.
+// Excess requests wait up to wait for a slot, or until the client goes away, and
+// are otherwise shed with 503 and a Retry-After hint. New installs it ahead of
+// any route.Handler with MaxConcurrent set, so each route gets its own limit.
+func ConcurrencyLimitMiddleware(max int, wait time.Duration) gin.HandlerFunc {
+	slots := make(chan struct{}, max)
+
+	return func(c *gin.Context) {
+		if !acquireSlot(c, slots, wait) {
+			LoggerFromContext(c).Warn("shedding request to %s: %v", c.FullPath(), ErrConcurrencyLimit)
+			c.Header("Retry-After", "1")
+			abortWithJSON(c, http.StatusServiceUnavailable, gin.H{"error": ErrConcurrencyLimit.Error(), "details": "too many concurrent requests, retry later"})
+			return
+		}
+		defer func() { <-slots }()
+		c.Next()
+	}
+}
+
+// acquireSlot takes a slot immediately, or within wait when wait is positive.
+func acquireSlot(c *gin.Context, slots chan struct{}, wait time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
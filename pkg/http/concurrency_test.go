@@ -0,0 +1,125 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRoutes serves a limited /slow route that blocks until release is closed, and an unlimited /fast route.
+func blockingRoutes(t *testing.T, max int, wait time.Duration) (*HTTPService, chan struct{}, chan struct{}) {
+	entered := make(chan struct{}, 16)
+	release := make(chan struct{})
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{
+			Method:          http.MethodGet,
+			Path:            "/slow",
+			MaxConcurrent:   max,
+			ConcurrencyWait: wait,
+			Handler: []gin.HandlerFunc{func(c *gin.Context) {
+				entered <- struct{}{}
+				<-release
+				c.Status(http.StatusOK)
+			}},
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/fast",
+			Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }},
+		},
+	}
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	return h, entered, release
+}
+
+func serveAsync(h *HTTPService, path string, wg *sync.WaitGroup, codes chan<- int) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		codes <- rec.Code
+	}()
+}
+
+func TestConcurrencyLimit_ShedsExcessRequests(t *testing.T) {
+	h, entered, release := blockingRoutes(t, 2, 0)
+
+	var wg sync.WaitGroup
+	admitted := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		serveAsync(h, "/api/v1/slow", &wg, admitted)
+		<-entered
+	}
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/slow", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), ErrConcurrencyLimit.Error())
+	}
+
+	// Other routes are unaffected by the saturated one
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fast", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	close(release)
+	wg.Wait()
+	close(admitted)
+	for code := range admitted {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	// Slots are returned once requests finish
+	rec = httptest.NewRecorder()
+	go func() { <-entered }()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/slow", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestConcurrencyLimit_QueuesWithinWait(t *testing.T) {
+	h, entered, release := blockingRoutes(t, 1, time.Second)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	serveAsync(h, "/api/v1/slow", &wg, codes)
+	<-entered
+	serveAsync(h, "/api/v1/slow", &wg, codes)
+
+	// The queued request gets the slot once the first one finishes
+	close(release)
+	<-entered
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
+
+func TestConcurrencyLimit_ShedsAfterWait(t *testing.T) {
+	h, entered, release := blockingRoutes(t, 1, 20*time.Millisecond)
+	defer close(release)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 1)
+	serveAsync(h, "/api/v1/slow", &wg, codes)
+	<-entered
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/slow", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
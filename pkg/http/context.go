@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// defaultDetachTimeout bounds background work started with DetachContext.
+const defaultDetachTimeout = 30 * time.Second
+
+// ServiceKey is the gin context key holding the *service.Service serving the request.
+const ServiceKey = "service"
+
+// ServiceContextMiddleware makes svc available to handlers via ServiceFromContext.
+func ServiceContextMiddleware(svc *service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ServiceKey, svc)
+		c.Next()
+	}
+}
+
+// ServiceFromContext returns the service serving the request, or nil if unset.
+func ServiceFromContext(c *gin.Context) *service.Service {
+	if v, ok := c.Get(ServiceKey); ok {
+		if svc, ok := v.(*service.Service); ok {
+			return svc
+		}
+	}
+	return nil
+}
+
+// DetachContext returns a context for background work spawned by a handler. It
+// keeps the request context's values (trace span, request-scoped logger) and
+// adds a logger carrying the request ID and authenticated user, but is not
+// cancelled when the request ends. It expires after timeout instead, or 30s
+// when timeout is zero; call the returned cancel once the work is done.
+func DetachContext(c *gin.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = defaultDetachTimeout
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	ctx = logging.NewContext(ctx, LoggerFromContext(c))
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceFromContext(t *testing.T) {
+	svc := newMockService(t)
+
+	var got any
+	svc.HTTPHandlers = append(svc.HTTPHandlers, &route.Handler{
+		Method:  http.MethodGet,
+		Path:    "/whoami",
+		Handler: []gin.HandlerFunc{func(c *gin.Context) { got = ServiceFromContext(c) }},
+	})
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	h.Engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/whoami", nil))
+	assert.Same(t, svc, got)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Nil(t, ServiceFromContext(c))
+}
+
+type detachKey struct{}
+
+func TestDetachContext_KeepsValuesButNotCancellation(t *testing.T) {
+	reqCtx, cancelRequest := context.WithCancel(context.WithValue(context.Background(), detachKey{}, "trace-1"))
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/orders", nil).WithContext(reqCtx)
+	c.Set(logging.RequestIDKey, "req-42")
+	c.Set(logging.UserIDKey, "user-7")
+
+	ctx, cancel := DetachContext(c, time.Minute)
+	defer cancel()
+	cancelRequest()
+
+	assert.Error(t, c.Request.Context().Err())
+	assert.NoError(t, ctx.Err(), "request cancellation must not reach the detached context")
+	assert.Equal(t, "trace-1", ctx.Value(detachKey{}))
+	assert.Equal(t, "req-42", logging.FromContext(ctx).Field(logging.FieldRequestID))
+	assert.Equal(t, "user-7", logging.FromContext(ctx).Field(logging.FieldUserID))
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+func TestDetachContext_OwnTimeout(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	ctx, cancel := DetachContext(c, 10*time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	ctx, cancel = DetachContext(c, 0)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	assert.WithinDuration(t, time.Now().Add(defaultDetachTimeout), deadline, time.Second)
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig configures CORS.
+type CORSConfig struct {
+	// AllowOrigins lists the origins allowed to call the service, such as
+	// https://app.example.com. "*" allows any origin, and a leading wildcard label
+	// such as https://*.example.com allows its subdomains.
+	AllowOrigins []string
+	// AllowMethods lists the methods allowed in preflight requests. Defaults to
+	// GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowMethods []string
+	// AllowHeaders lists the request headers allowed in preflight requests. Defaults
+	// to Authorization, Content-Type and X-Request-ID.
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers readable by the browser.
+	ExposeHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization headers. It is only
+	// honored for listed origins and subdomain wildcards, never for "*".
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response. Zero leaves it to them.
+	MaxAge time.Duration
+}
+
+// ErrCORSCredentialsAnyOrigin rejects configurations allowing credentials from any
+// origin, which would let every site make authenticated requests on a user's behalf.
+var ErrCORSCredentialsAnyOrigin = errors.New(`CORS cannot allow credentials for the "*" origin; list the allowed origins instead`)
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", RequestIDHeader}
+)
+
+// validate reports configurations New refuses to serve.
+func (cfg CORSConfig) validate() error {
+	if !cfg.AllowCredentials {
+		return nil
+	}
+	for _, o := range cfg.AllowOrigins {
+		if strings.TrimSpace(o) == "*" {
+			return ErrCORSCredentialsAnyOrigin
+		}
+	}
+	return nil
+}
+
+// CORS returns middleware applying cfg to cross-origin requests. Preflight requests
+// are answered with 204 when the origin is allowed and 403 otherwise, without
+// running the rest of the chain. Other requests from disallowed origins are served
+// without CORS headers, so browsers withhold the response.
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	methods := cfg.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge / time.Second))
+
+	anyOrigin := false
+	var origins []string
+	var wildcards []originWildcard
+	for _, o := range cfg.AllowOrigins {
+		o = strings.ToLower(strings.TrimRight(o, "/"))
+		switch {
+		case o == "*":
+			anyOrigin = true
+		case strings.Contains(o, "://*."):
+			scheme, domain, _ := strings.Cut(o, "://*")
+			wildcards = append(wildcards, originWildcard{prefix: scheme + "://", suffix: domain})
+		default:
+			origins = append(origins, o)
+		}
+	}
+	// listed reports whether origin is allowed by name or subdomain wildcard rather than "*"
+	listed := func(origin string) bool {
+		origin = strings.ToLower(origin)
+		for _, o := range origins {
+			if o == origin {
+				return true
+			}
+		}
+		for _, w := range wildcards {
+			if w.match(origin) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		isListed := listed(origin)
+		if !isListed && !anyOrigin {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Credentials are only shared with listed origins, which are named rather than "*"
+		if cfg.AllowCredentials && isListed {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		} else if anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposeHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+		}
+		c.Next()
+	}
+}
+
+// originWildcard matches origins such as https://*.example.com: prefix is the
+// scheme and suffix the parent domain, including its leading dot.
+type originWildcard struct {
+	prefix, suffix string
+}
+
+func (w originWildcard) match(origin string) bool {
+	rest, ok := strings.CutPrefix(origin, w.prefix)
+	return ok && len(rest) > len(w.suffix) && strings.HasSuffix(rest, w.suffix)
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func corsRequest(method, origin string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/widgets", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	return req
+}
+
+func newCORSService(t *testing.T, cfg CORSConfig) *HTTPService {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodGet, Path: "/widgets", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }}},
+		{Method: http.MethodPost, Path: "/widgets", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusCreated) }}},
+	}
+	h, err := New(svc, "v1", Option{CORS: &cfg})
+	require.NoError(t, err)
+	return h
+}
+
+func TestCORS(t *testing.T) {
+	h := newCORSService(t, CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com", "https://*.example.org"},
+		ExposeHeaders: []string{RequestIDHeader},
+		MaxAge:        10 * time.Minute,
+	})
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		w := serve(corsRequest(http.MethodGet, "https://app.example.com"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, RequestIDHeader, w.Header().Get("Access-Control-Expose-Headers"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("wildcard subdomain", func(t *testing.T) {
+		w := serve(corsRequest(http.MethodGet, "https://tenant.example.org"))
+		assert.Equal(t, "https://tenant.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = serve(corsRequest(http.MethodGet, "https://example.org"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "the parent domain is not a subdomain")
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		w := serve(corsRequest(http.MethodGet, "https://evil.example.net"))
+		assert.Equal(t, http.StatusOK, w.Code, "the request is served, browsers withhold it")
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = serve(corsRequest(http.MethodOptions, "https://evil.example.net"))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		w := serve(corsRequest(http.MethodOptions, "https://app.example.com"))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Authorization, Content-Type, X-Request-ID", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("same-origin request", func(t *testing.T) {
+		w := serve(corsRequest(http.MethodGet, ""))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Vary"))
+	})
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	h := newCORSService(t, CORSConfig{AllowOrigins: []string{"*"}})
+	w := httptest.NewRecorder()
+	h.Engine.ServeHTTP(w, corsRequest(http.MethodGet, "https://anywhere.test"))
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_CredentialsNeverForAnyOrigin(t *testing.T) {
+	_, err := New(newMockService(t), "v1", Option{CORS: &CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}})
+	assert.ErrorIs(t, err, ErrCORSCredentialsAnyOrigin)
+
+	// Used directly, the middleware shares credentials with listed origins only
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORS(CORSConfig{AllowOrigins: []string{"*", "https://app.example.com"}, AllowCredentials: true}))
+	r.GET("/api/v1/widgets", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, corsRequest(http.MethodGet, "https://evil.test"))
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, corsRequest(http.MethodGet, "https://app.example.com"))
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestNew_WithoutCORS(t *testing.T) {
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	h.Engine.ServeHTTP(w, corsRequest(http.MethodGet, "https://app.example.com"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
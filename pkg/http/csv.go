@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// csvFlushEvery is the number of rows buffered between flushes to the client.
+const csvFlushEvery = 100
+
+// StreamCSV streams a CSV export without buffering it in memory. The header row is
+// written first, then every row passed to yield. yield returns false once the client
+// has gone away or a write failed, at which point rows should stop producing. A
+// client disconnect is logged at debug level, other write failures as errors.
+// The attachment is named export.csv unless the handler set Content-Disposition.
+func StreamCSV(c *gin.Context, headers []string, rows func(yield func([]string) bool)) {
+	if c.Writer.Header().Get("Content-Disposition") == "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "export.csv"}))
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	ctx := c.Request.Context()
+
+	var err error
+	flush := func() {
+		w.Flush()
+		if err = w.Error(); err == nil {
+			c.Writer.Flush()
+		}
+	}
+
+	if len(headers) > 0 {
+		if err = w.Write(headers); err != nil {
+			LoggerFromContext(c).Error("failed to write CSV header: %v", err)
+			return
+		}
+	}
+
+	written := 0
+	rows(func(row []string) bool {
+		if err != nil || ctx.Err() != nil {
+			return false
+		}
+		if err = w.Write(row); err != nil {
+			return false
+		}
+		written++
+		if written%csvFlushEvery == 0 {
+			flush()
+		}
+		return err == nil
+	})
+
+	if err == nil && ctx.Err() == nil {
+		flush()
+	}
+	switch {
+	case err != nil && !clientGone(c, err):
+		LoggerFromContext(c).Error("CSV export aborted after %d rows: %v", written, err)
+	case err != nil || ctx.Err() != nil:
+		LoggerFromContext(c).Debug("CSV export stopped after %d rows: client disconnected", written)
+	}
+}
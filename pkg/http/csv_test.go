@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamCSV_Escaping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/export", func(c *gin.Context) {
+		StreamCSV(c, []string{"id", "note"}, func(yield func([]string) bool) {
+			yield([]string{"1", "plain"})
+			yield([]string{"2", "has, comma"})
+			yield([]string{"3", `has "quotes"`})
+			yield([]string{"4", "multi\nline"})
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=export.csv", rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,note\n1,plain\n2,\"has, comma\"\n3,\"has \"\"quotes\"\"\"\n4,\"multi\nline\"\n", rec.Body.String())
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4", "multi\nline"}, records[4])
+}
+
+func TestStreamCSV_StreamsAndFlushes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/export", func(c *gin.Context) {
+		c.Header("Content-Disposition", "attachment; filename=users.csv")
+		StreamCSV(c, []string{"n"}, func(yield func([]string) bool) {
+			for i := 0; i < csvFlushEvery*3; i++ {
+				if !yield([]string{fmt.Sprint(i)}) {
+					return
+				}
+			}
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "attachment; filename=users.csv", rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, csvFlushEvery*3+1, strings.Count(rec.Body.String(), "\n"))
+}
+
+func TestStreamCSV_StopsOnCancellation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	produced := 0
+	r := gin.New()
+	r.GET("/export", func(c *gin.Context) {
+		StreamCSV(c, []string{"n"}, func(yield func([]string) bool) {
+			for i := 0; i < 1000; i++ {
+				if i == 10 {
+					cancel() // client goes away mid-export
+				}
+				if !yield([]string{fmt.Sprint(i)}) {
+					return
+				}
+				produced++
+			}
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 10, produced)
+}
+
+func TestStreamCSV_WriteFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"client disconnect", errBrokenPipe, "DEBUG CSV export stopped after 100 rows: client disconnected"},
+		{"write error", errors.New("disk quota exceeded"), "ERROR CSV export aborted after 100 rows: disk quota exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			produced := 0
+			StreamCSV(newFailingContext(0, tt.err, sink), []string{"n"}, func(yield func([]string) bool) {
+				for i := 0; i < 1000; i++ {
+					if !yield([]string{fmt.Sprint(i)}) {
+						return
+					}
+					produced++
+				}
+			})
+
+			// The first flush fails, which ends the export
+			assert.Equal(t, csvFlushEvery-1, produced)
+			lines := sink.Lines()
+			require.Len(t, lines, 1)
+			assert.Equal(t, tt.want, lines[0])
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// DebugConfigPath serves the effective configuration on the admin listener when
+// DEBUG_CONFIG=true.
+const DebugConfigPath = "/debug/config"
+
+// debugConfigEnabled reports whether the debug config endpoint should be mounted.
+func debugConfigEnabled() bool {
+	return os.Getenv("DEBUG_CONFIG") == "true"
+}
+
+// DebugConfigHandler returns the effective framework settings and svc.Config, masked with logging.RedactSecrets: struct fields tagged `secret:"true"`
+// or `log:"redact"` and fields or map keys whose names look like secrets are replaced
+// with logging.Mask.
+func DebugConfigHandler(svc *service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"framework": frameworkConfig(svc),
+			"app":       logging.RedactSecrets(svc.Config),
+		})
+	}
+}
+
+// frameworkConfig collects the settings this library reads from the environment.
+func frameworkConfig(svc *service.Service) map[string]any {
+	cfg := map[string]any{
+		"service":          svc.Name,
+		"version":          svc.Version,
+		"port":             svc.Port,
+		"protocols":        os.Getenv("SERVICE_PROTOCOL"),
+		"service_deps":     svc.DependencyNames(),
+		"max_header_bytes": maxHeaderBytes(svc),
+		"slow_request":     slowRequestThreshold(svc).String(),
+		"database": map[string]any{
+			"host":     os.Getenv("DB_HOST"),
+			"port":     os.Getenv("DB_PORT"),
+			"name":     os.Getenv("DB_NAME"),
+			"user":     os.Getenv("DB_USER"),
+			"password": os.Getenv("DB_PASSWORD"),
+			"ssl_mode": os.Getenv("DB_SSL_MODE"),
+		},
+	}
+	return logging.RedactSecrets(cfg)
+}
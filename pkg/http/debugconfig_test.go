@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type appConfig struct {
+	Region       string        `json:"region"`
+	Timeout      time.Duration `json:"timeout"`
+	StripeKey    string        `json:"stripe_key"`
+	SigningSeed  string        `json:"signing_seed" secret:"true"`
+	Passphrase   string        `json:"passphrase" log:"redact"`
+	WebhookToken string        `json:"webhook_token"`
+	Internal     string        `json:"-"`
+	Flags        map[string]string
+}
+
+func TestDebugConfigEndpoint(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "hunter2")
+
+	svc := newMockService(t)
+	svc.Name = "billing"
+	svc.Config = appConfig{Region: "us-east-1", StripeKey: "sk_live_123", SigningSeed: "seed", Passphrase: "open sesame"}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		h, err := New(svc, "v1")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugConfigPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("enabled without an admin listener", func(t *testing.T) {
+		t.Setenv("DEBUG_CONFIG", "true")
+		h, err := New(svc, "v1")
+		require.NoError(t, err)
+		assert.Nil(t, h.Admin)
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugConfigPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DEBUG_CONFIG", "true")
+		t.Setenv("ADMIN_PORT", "0")
+		h, err := New(svc, "v1")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugConfigPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, "debug endpoints stay off the public listener")
+
+		w = httptest.NewRecorder()
+		h.Admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugConfigPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "hunter2")
+		assert.NotContains(t, w.Body.String(), "sk_live_123")
+
+		var body struct {
+			Framework map[string]any `json:"framework"`
+			App       map[string]any `json:"app"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		assert.Equal(t, "billing", body.Framework["service"])
+		db := body.Framework["database"].(map[string]any)
+		assert.Equal(t, "db.internal", db["host"])
+		assert.Equal(t, logging.Mask, db["password"])
+		assert.Equal(t, "", db["user"])
+
+		assert.Equal(t, "us-east-1", body.App["region"])
+		assert.Equal(t, logging.Mask, body.App["stripe_key"])
+		assert.Equal(t, logging.Mask, body.App["signing_seed"])
+		assert.Equal(t, logging.Mask, body.App["passphrase"])
+		assert.Equal(t, "", body.App["webhook_token"], "unset secrets are reported as empty")
+	})
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugRoutesPath lists the registered routes on the admin listener when DEBUG_ROUTES=true.
+const DebugRoutesPath = "/debug/routes"
+
+// RouteEntry describes one registered route.
+type RouteEntry struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// debugRoutesEnabled reports whether the debug routes endpoint should be mounted.
+func debugRoutesEnabled() bool {
+	return os.Getenv("DEBUG_ROUTES") == "true"
+}
+
+// DebugRoutesHandler lists the engine's routes, sorted by path and method. Routes
+// are read on every request, so routes mounted after New, such as gateways, are
+// included. New serves it on the admin listener, listing the public engine's routes.
+func DebugRoutesHandler(engine *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routes := engine.Routes()
+		entries := make([]RouteEntry, 0, len(routes))
+		for _, r := range routes {
+			entries = append(entries, RouteEntry{Method: r.Method, Path: r.Path, Handler: r.Handler})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Path != entries[j].Path {
+				return entries[i].Path < entries[j].Path
+			}
+			return entries[i].Method < entries[j].Method
+		})
+
+		c.JSON(http.StatusOK, gin.H{"routes": entries})
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugRoutesEndpoint(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		h, err := New(newMockService(t), "v1")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugRoutesPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DEBUG_ROUTES", "true")
+		t.Setenv("ADMIN_PORT", "0")
+		h, err := New(newMockService(t), "v1")
+		require.NoError(t, err)
+		h.Mount("/gateway", http.NotFoundHandler())
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugRoutesPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, "debug endpoints stay off the public listener")
+
+		w = httptest.NewRecorder()
+		h.Admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugRoutesPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Routes []RouteEntry `json:"routes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		byPath := map[string]RouteEntry{}
+		for _, r := range body.Routes {
+			byPath[r.Method+" "+r.Path] = r
+		}
+		ping, ok := byPath["GET /api/v1/ping"]
+		require.True(t, ok, "registered handlers are listed")
+		assert.NotEmpty(t, ping.Handler)
+		assert.NotContains(t, byPath, "GET "+DebugRoutesPath, "admin endpoints are not public routes")
+		assert.Contains(t, byPath, "POST /gateway/*path", "routes mounted after New are listed")
+	})
+}
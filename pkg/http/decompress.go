@@ -0,0 +1,194 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/metrics"
+)
+
+// ErrDecompressionLimit is returned from the request body once a decompression limit is hit.
+var ErrDecompressionLimit = errors.New("decompressed request body exceeds limit")
+
+// Decompression defaults.
+const (
+	defaultMaxDecompressionRatio = 100
+	// ratioCheckThreshold is the decompressed size from which the ratio cap applies,
+	// so small, highly repetitive bodies are not rejected.
+	ratioCheckThreshold = 64 << 10
+)
+
+// Decompression outcomes recorded by DecompressMiddleware.
+const (
+	DecompressionAccepted = "accepted"
+	DecompressionRejected = "rejected"
+)
+
+var (
+	decompressedRequests = metrics.Default.NewCounterVec(
+		"http_decompressed_requests_total",
+		"Compressed HTTP request bodies, by encoding and outcome.",
+		"encoding", "outcome",
+	)
+	compressedBytes = metrics.Default.NewCounterVec(
+		"http_request_compressed_bytes_total",
+		"Compressed bytes read from HTTP request bodies, by encoding.",
+		"encoding",
+	)
+	decompressedBytes = metrics.Default.NewCounterVec(
+		"http_request_decompressed_bytes_total",
+		"Decompressed bytes read from HTTP request bodies, by encoding.",
+		"encoding",
+	)
+)
+
+// DecompressConfig configures DecompressMiddleware.
+type DecompressConfig struct {
+	// MaxRatio caps decompressed/compressed size. Defaults to 100; negative disables it.
+	MaxRatio float64
+	// MaxBytes caps the decompressed size. Zero means no limit.
+	MaxBytes int64
+}
+
+// DecompressMiddleware transparently decompresses gzip and deflate request bodies.
+// It aborts with 413 as soon as the body exceeds MaxBytes or inflates beyond
+// MaxRatio while the handler is reading it, guarding against zip bombs. Outcomes
+// and sizes are recorded in the http_decompressed_* and http_request_*_bytes metrics.
+// Register it through svc.HTTPMiddleware at route.PhaseContext.
+func DecompressMiddleware(cfg DecompressConfig) gin.HandlerFunc {
+	if cfg.MaxRatio == 0 {
+		cfg.MaxRatio = defaultMaxDecompressionRatio
+	}
+
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding != "gzip" && encoding != "deflate" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		compressed := &countingReader{r: c.Request.Body}
+		var inflated io.ReadCloser
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(compressed)
+			if err != nil {
+				abortWithError(c, err, "invalid gzip request body", http.StatusBadRequest)
+				return
+			}
+			inflated = zr
+		} else {
+			inflated = flate.NewReader(compressed)
+		}
+
+		writer := &rejectableWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		body := &limitedInflater{r: inflated, compressed: compressed, cfg: cfg}
+		body.onLimit = func(err error) {
+			abortWithError(c, err, "request body too large", http.StatusRequestEntityTooLarge)
+			writer.rejected = true
+		}
+
+		original := c.Request.Body
+		c.Request.Body = body
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		defer original.Close()
+
+		c.Next()
+
+		outcome := DecompressionAccepted
+		if writer.rejected {
+			outcome = DecompressionRejected
+		}
+		decompressedRequests.WithLabelValues(encoding, outcome).Inc()
+		compressedBytes.WithLabelValues(encoding).Add(float64(compressed.n))
+		decompressedBytes.WithLabelValues(encoding).Add(float64(body.n))
+		LoggerFromContext(c).Debug("decompressed %s request body: compressed=%d decompressed=%d", encoding, compressed.n, body.n)
+	}
+}
+
+// countingReader counts bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// limitedInflater enforces the decompression limits while the body is read.
+type limitedInflater struct {
+	r          io.ReadCloser
+	compressed *countingReader
+	cfg        DecompressConfig
+	n          int64
+	err        error
+	onLimit    func(error)
+}
+
+func (l *limitedInflater) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+
+	if l.cfg.MaxBytes > 0 && l.n > l.cfg.MaxBytes {
+		return 0, l.trip(fmt.Errorf("%w of %d bytes", ErrDecompressionLimit, l.cfg.MaxBytes))
+	}
+	if l.cfg.MaxRatio > 0 && l.n > ratioCheckThreshold && l.compressed.n > 0 {
+		if ratio := float64(l.n) / float64(l.compressed.n); ratio > l.cfg.MaxRatio {
+			return 0, l.trip(fmt.Errorf("%w: ratio %.0f exceeds %.0f", ErrDecompressionLimit, ratio, l.cfg.MaxRatio))
+		}
+	}
+	return n, err
+}
+
+func (l *limitedInflater) trip(err error) error {
+	l.err = err
+	l.onLimit(err)
+	return err
+}
+
+func (l *limitedInflater) Close() error {
+	return l.r.Close()
+}
+
+// rejectableWriter drops the handler's output once the request has been rejected,
+// so the 413 written mid-read is the response the client sees.
+type rejectableWriter struct {
+	gin.ResponseWriter
+	rejected bool
+}
+
+func (w *rejectableWriter) WriteHeader(code int) {
+	if !w.rejected {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *rejectableWriter) Write(b []byte) (int, error) {
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *rejectableWriter) WriteString(s string) (int, error) {
+	if w.rejected {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, p []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(p)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func newDecompressEngine(cfg DecompressConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(DecompressMiddleware(cfg))
+	r.POST("/upload", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"size": len(body)})
+	})
+	return r
+}
+
+func postBody(r http.Handler, encoding string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDecompressMiddleware_RejectsHighRatioPayload(t *testing.T) {
+	rejected := decompressedRequests.WithLabelValues("gzip", DecompressionRejected).Value()
+	inflated := decompressedBytes.WithLabelValues("gzip").Value()
+	r := newDecompressEngine(DecompressConfig{MaxRatio: 50})
+
+	bomb := gzipped(t, make([]byte, 10<<20))
+	w := postBody(r, "gzip", bomb)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large")
+	assert.NotContains(t, w.Body.String(), "size", "the handler's response must be discarded")
+	assert.Equal(t, rejected+1, decompressedRequests.WithLabelValues("gzip", DecompressionRejected).Value())
+	assert.Less(t, decompressedBytes.WithLabelValues("gzip").Value()-inflated, float64(10<<20), "decompression stops mid-stream")
+}
+
+func TestDecompressMiddleware_PassesNormalPayload(t *testing.T) {
+	accepted := decompressedRequests.WithLabelValues("gzip", DecompressionAccepted).Value()
+	rejected := decompressedRequests.WithLabelValues("gzip", DecompressionRejected).Value()
+	deflated := compressedBytes.WithLabelValues("gzip").Value()
+	inflated := decompressedBytes.WithLabelValues("gzip").Value()
+	r := newDecompressEngine(DecompressConfig{})
+
+	random := make([]byte, 32<<10)
+	_, _ = rand.Read(random)
+	payload := []byte(strings.Repeat(`{"id":"`+hex.EncodeToString(random[:16])+`"},`, 100) + hex.EncodeToString(random))
+	compressed := gzipped(t, payload)
+
+	w := postBody(r, "gzip", compressed)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"size":%d}`, len(payload)), w.Body.String())
+
+	assert.Equal(t, accepted+1, decompressedRequests.WithLabelValues("gzip", DecompressionAccepted).Value())
+	assert.Equal(t, rejected, decompressedRequests.WithLabelValues("gzip", DecompressionRejected).Value())
+	assert.Equal(t, deflated+float64(len(compressed)), compressedBytes.WithLabelValues("gzip").Value())
+	assert.Equal(t, inflated+float64(len(payload)), decompressedBytes.WithLabelValues("gzip").Value())
+}
+
+func TestDecompressMiddleware_MaxBytesAndEncodings(t *testing.T) {
+	r := newDecompressEngine(DecompressConfig{MaxBytes: 1024})
+
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	_, _ = fw.Write([]byte("hello"))
+	_ = fw.Close()
+	assert.Equal(t, http.StatusOK, postBody(r, "deflate", buf.Bytes()).Code)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBody(r, "gzip", gzipped(t, make([]byte, 4096))).Code)
+	assert.Equal(t, http.StatusBadRequest, postBody(r, "gzip", []byte("not gzip")).Code)
+
+	// Uncompressed bodies pass through untouched
+	w := postBody(r, "", make([]byte, 4096))
+	assert.JSONEq(t, `{"size":4096}`, w.Body.String())
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeDownload streams content as a file attachment named name.
+// It delegates to http.ServeContent, so Range and If-Range requests are honored
+// and interrupted downloads can be resumed with a 206 Partial Content response.
+func ServeDownload(c *gin.Context, name string, modtime time.Time, content io.ReadSeeker) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	c.Header("Content-Disposition", disposition)
+	c.Header("Accept-Ranges", "bytes")
+	http.ServeContent(c.Writer, c.Request, name, modtime, content)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+)
+
+// EnvelopeKey is the gin context key set by ResponseEnvelopeMiddleware.
+const EnvelopeKey = "responseEnvelope"
+
+// Meta accompanies every enveloped response.
+type Meta struct {
+	RequestID string    `json:"request_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// envelopeEnabled reports whether RESPONSE_ENVELOPE=true asks for every response
+// written through Respond and the framework's errors to be enveloped.
+func envelopeEnabled() bool {
+	return os.Getenv("RESPONSE_ENVELOPE") == "true"
+}
+
+// ResponseEnvelopeMiddleware turns on envelopes for the request: Respond then writes
+// {"data": obj, "meta": {...}} for successes and {"error": obj, "meta": {...}} for
+// error statuses, and framework errors become {"error": {"message", "details"}, "meta"}.
+// New installs it when RESPONSE_ENVELOPE=true.
+func ResponseEnvelopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(EnvelopeKey, true)
+		c.Next()
+	}
+}
+
+// Respond writes obj as JSON with code, enveloping it when enabled for the request.
+func Respond(c *gin.Context, code int, obj any) {
+	if !c.GetBool(EnvelopeKey) {
+		c.JSON(code, obj)
+		return
+	}
+
+	key := "data"
+	if code >= 400 {
+		key = "error"
+	}
+	c.JSON(code, gin.H{key: obj, "meta": responseMeta(c)})
+}
+
+// abortWithJSON aborts with the {"error", "details"} body used across the
+// package, enveloped as {"error": {"message", "details"}, "meta"} when enabled.
+func abortWithJSON(c *gin.Context, code int, body gin.H) {
+	if !c.GetBool(EnvelopeKey) {
+		c.AbortWithStatusJSON(code, body)
+		return
+	}
+
+	inner := gin.H{}
+	for k, v := range body {
+		if k == "error" {
+			k = "message"
+		}
+		inner[k] = v
+	}
+	c.AbortWithStatusJSON(code, gin.H{"error": inner, "meta": responseMeta(c)})
+}
+
+func responseMeta(c *gin.Context) Meta {
+	return Meta{RequestID: c.GetString(logging.RequestIDKey), Timestamp: time.Now().UTC()}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type envelope struct {
+	Data  map[string]any `json:"data"`
+	Error map[string]any `json:"error"`
+	Meta  *Meta          `json:"meta"`
+}
+
+func newEnvelopeService(t *testing.T) *HTTPService {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodGet, Path: "/widgets/1", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			Respond(c, http.StatusOK, gin.H{"id": 1})
+		}}},
+		{Method: http.MethodGet, Path: "/widgets/2", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			Respond(c, http.StatusNotFound, gin.H{"code": "not_found"})
+		}}},
+		{Method: http.MethodPost, Path: "/widgets/:id", Handler: []gin.HandlerFunc{TypedHandler(func(context.Context, greetRequest) (greetResponse, int, error) {
+			return greetResponse{}, http.StatusConflict, errors.New("widget exists")
+		})}},
+	}
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	return h
+}
+
+func serveEnvelope(t *testing.T, h *HTTPService, method, path, body string) (*httptest.ResponseRecorder, envelope) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(RequestIDHeader, "req-1")
+	h.Engine.ServeHTTP(rec, req)
+
+	var env envelope
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	return rec, env
+}
+
+func TestRespond_WithoutEnvelopeWritesRawObject(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newEnvelopeService(t).Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/widgets/1", nil))
+	assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+}
+
+func TestRespond_EnvelopesSuccess(t *testing.T) {
+	t.Setenv("RESPONSE_ENVELOPE", "true")
+	rec, env := serveEnvelope(t, newEnvelopeService(t), http.MethodGet, "/api/v1/widgets/1", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]any{"id": float64(1)}, env.Data)
+	assert.Nil(t, env.Error)
+	require.NotNil(t, env.Meta)
+	assert.Equal(t, "req-1", env.Meta.RequestID)
+	assert.WithinDuration(t, time.Now(), env.Meta.Timestamp, time.Minute)
+}
+
+func TestRespond_EnvelopesErrorStatus(t *testing.T) {
+	t.Setenv("RESPONSE_ENVELOPE", "true")
+	rec, env := serveEnvelope(t, newEnvelopeService(t), http.MethodGet, "/api/v1/widgets/2", "")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Nil(t, env.Data)
+	assert.Equal(t, map[string]any{"code": "not_found"}, env.Error)
+	assert.Equal(t, "req-1", env.Meta.RequestID)
+}
+
+func TestRespond_EnvelopesFrameworkErrors(t *testing.T) {
+	t.Setenv("RESPONSE_ENVELOPE", "true")
+	h := newEnvelopeService(t)
+
+	rec, env := serveEnvelope(t, h, http.MethodPost, "/api/v1/widgets/9", `{"name":"gear"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, map[string]any{"message": "widget exists"}, env.Error)
+	assert.Equal(t, "req-1", env.Meta.RequestID)
+
+	rec, env = serveEnvelope(t, h, http.MethodPost, "/api/v1/widgets/9", `{`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid request", env.Error["details"])
+	assert.Contains(t, env.Error["message"], "invalid JSON body")
+}
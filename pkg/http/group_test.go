@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tag appends name to the X-Chain response header, recording the middleware that ran.
+func tag(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("X-Chain", name)
+		c.Next()
+	}
+}
+
+func TestNew_RegistersRouteTree(t *testing.T) {
+	t.Setenv("AUTO_OPTIONS", "true")
+	ok := func(c *gin.Context) { c.String(http.StatusOK, c.FullPath()) }
+
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{Method: http.MethodGet, Path: "/status", Handler: []gin.HandlerFunc{ok}}}
+	svc.HTTPGroups = []*route.Group{{
+		Prefix:     "/admin",
+		Middleware: []gin.HandlerFunc{tag("admin")},
+		Routes:     []*route.Handler{{Method: http.MethodGet, Path: "/users/:id", Handler: []gin.HandlerFunc{ok}}},
+		Groups: []*route.Group{{
+			Prefix:     "/reports",
+			Middleware: []gin.HandlerFunc{tag("reports")},
+			Routes: []*route.Handler{
+				{Method: http.MethodGet, Path: "/daily", Handler: []gin.HandlerFunc{ok}},
+				{Method: http.MethodPost, Path: "/daily", Handler: []gin.HandlerFunc{ok}},
+			},
+		}},
+	}}
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	tests := []struct {
+		method, path, route, chain string
+	}{
+		{http.MethodGet, "/api/v1/status", "/api/v1/status", ""},
+		{http.MethodGet, "/api/v1/admin/users/42", "/api/v1/admin/users/:id", "admin"},
+		{http.MethodGet, "/api/v1/admin/reports/daily", "/api/v1/admin/reports/daily", "admin,reports"},
+		{http.MethodPost, "/api/v1/admin/reports/daily", "/api/v1/admin/reports/daily", "admin,reports"},
+	}
+	for _, tt := range tests {
+		rec := serve(tt.method, tt.path)
+		assert.Equal(t, http.StatusOK, rec.Code, tt.path)
+		assert.Equal(t, tt.route, rec.Body.String())
+		assert.Equal(t, tt.chain, strings.Join(rec.Header().Values("X-Chain"), ","), tt.path)
+	}
+
+	// Group routes are known to the automatic OPTIONS handling by their full path
+	rec := serve(http.MethodOptions, "/api/v1/admin/reports/daily")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET, OPTIONS, POST", rec.Header().Get("Allow"))
+}
+
+func TestNew_RejectsConflictingRouteTree(t *testing.T) {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{Method: http.MethodGet, Path: "/admin/users", Handler: []gin.HandlerFunc{func(*gin.Context) {}}}}
+	svc.HTTPGroups = []*route.Group{{
+		Prefix: "/admin",
+		Routes: []*route.Handler{{Method: http.MethodGet, Path: "/users", Handler: []gin.HandlerFunc{func(*gin.Context) {}}}},
+	}}
+
+	_, err := New(svc, "v1")
+	assert.EqualError(t, err, "conflicting routes: GET /admin/users duplicates GET /admin/users")
+}
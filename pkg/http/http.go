@@ -4,61 +4,377 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	ctxmw "github.com/ranorsolutions/http-common-go/pkg/middleware/context"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/metrics"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/ranorsolutions/svc-common-go/pkg/tracing"
 )
 
 type HTTPService struct {
-	Engine  *gin.Engine
-	Server  *http.Server
-	Service *service.Service
+	Engine     *gin.Engine
+	Server     *http.Server
+	Service    *service.Service
+	RequestLog *RequestLogBuffer
+	Latency    *LatencyTracker
+	// Admin and AdminServer serve the debug endpoints when ADMIN_PORT is set; both are
+	// nil otherwise.
+	Admin       *gin.Engine
+	AdminServer *http.Server
+}
+
+// Option configures optional built-in behavior of New.
+type Option struct {
+	// CORS, when set, answers preflight requests and adds CORS headers to responses.
+	CORS *CORSConfig
+	// Compression, when set, compresses responses for clients that accept gzip or deflate.
+	Compression *CompressionConfig
 }
 
 // New creates a Gin HTTP service wrapping a given `service.Service`.
 // It auto-registers all handlers defined in svc.HTTPHandlers and mounts them under /api/{version}.
-func New(svc *service.Service, version string) (*HTTPService, error) {
+// Middleware from svc.HTTPMiddleware is installed alongside the built-in middleware,
+// ordered by phase regardless of registration order.
+func New(svc *service.Service, version string, opts ...Option) (*HTTPService, error) {
 	if svc == nil {
 		return nil, fmt.Errorf("service cannot be nil")
 	}
 
+	for _, opt := range opts {
+		if opt.CORS != nil {
+			if err := opt.CORS.validate(); err != nil {
+				return nil, fmt.Errorf("invalid CORS config: %w", err)
+			}
+		}
+	}
+
+	var requestLog *RequestLogBuffer
+	if size := requestLogBufferSize(svc); size > 0 {
+		requestLog = NewRequestLogBuffer(size)
+	}
+	var latency *LatencyTracker
+	if debugLatencyEnabled() {
+		latency = NewLatencyTracker()
+	}
+
+	// Keep large integers bound into interface{} values exact; this is process-wide in gin
+	if jsonUseNumber() {
+		enableDecoderUseNumber()
+	}
+
+	var admin *gin.Engine
+	var adminServer *http.Server
+	if port := adminPort(); port != "" {
+		admin, adminServer = newAdminServer(svc, port)
+	}
+
 	engine := gin.New()
-	engine.Use(ctxmw.GinContextToContextMiddleware())
-	engine.Use(gin.Recovery())
+	if !redirectTrailingSlash(svc) {
+		engine.RedirectTrailingSlash = false
+		engine.RedirectFixedPath = false
+	}
+	for _, mw := range route.SortMiddleware(middleware(svc, opts, requestLog, latency)) {
+		engine.Use(mw.Handler)
+	}
+
+	// Reject conflicting routes up front instead of letting gin panic on them
+	if err := route.Validate(svc.HTTPHandlers, svc.HTTPGroups); err != nil {
+		return nil, err
+	}
 
 	group := engine.Group(fmt.Sprintf("/api/%s", version))
-	for _, route := range svc.HTTPHandlers {
-		switch route.Method {
-		case http.MethodGet:
-			group.GET(route.Path, route.Handler...)
-		case http.MethodPut:
-			group.PUT(route.Path, route.Handler...)
-		case http.MethodPost:
-			group.POST(route.Path, route.Handler...)
-		case http.MethodDelete:
-			group.DELETE(route.Path, route.Handler...)
-		default:
-			svc.Logger.Warn("unrecognized HTTP method for route %s", route.Path)
+	var registered pathMethods
+	if err := registerRoutes(group, "", svc.HTTPHandlers, &registered); err != nil {
+		return nil, err
+	}
+	if err := registerGroups(group, "", svc.HTTPGroups, &registered); err != nil {
+		return nil, err
+	}
+
+	// Answer OPTIONS with the methods each path supports, unless a route handles it itself
+	if autoOptionsEnabled() {
+		for _, path := range registered.paths {
+			if methods := registered.methods[path]; !slices.Contains(methods, http.MethodOptions) {
+				group.OPTIONS(path, AllowHandler(methods))
+			}
 		}
 	}
 
-	server := &http.Server{Handler: engine}
+	// Answer readiness probes with the service's readiness flag and health checks
+	if readyzEnabled() {
+		engine.GET(ReadyzPath, ReadyzHandler(svc))
+	}
+
+	// Expose the redacted effective configuration for debugging config drift
+	if debugConfigEnabled() {
+		mountDebug(svc, admin, DebugConfigPath, DebugConfigHandler(svc))
+	}
+
+	// Expose metrics for Prometheus scraping
+	if metricsEnabled() {
+		engine.GET(MetricsPath, gin.WrapH(metrics.Default.Handler()))
+	}
+
+	// Expose recent requests for post-hoc inspection without enabling full body logging
+	if requestLog != nil {
+		mountDebug(svc, admin, DebugRequestsPath, RequestLogHandler(requestLog))
+	}
+
+	// Report per-route latency percentiles for quick diagnostics without a metrics stack
+	if latency != nil {
+		mountDebug(svc, admin, DebugLatencyPath, LatencyHandler(latency))
+	}
+
+	// List the registered routes to confirm what a deployment serves
+	if debugRoutesEnabled() {
+		mountDebug(svc, admin, DebugRoutesPath, DebugRoutesHandler(engine))
+	}
+
+	// Answer crawler and browser probes at the root so they don't log as 404s
+	if robotsEnabled() {
+		engine.GET(RobotsPath, RobotsHandler(DisallowAllRobots))
+	}
+	if faviconEnabled() {
+		engine.GET(FaviconPath, FaviconHandler(favicon(svc)))
+	}
+
+	server := &http.Server{
+		Handler:        engine,
+		MaxHeaderBytes: maxHeaderBytes(svc),
+	}
 
 	return &HTTPService{
-		Server:  server,
-		Engine:  engine,
-		Service: svc,
+		Server:      server,
+		Engine:      engine,
+		Service:     svc,
+		RequestLog:  requestLog,
+		Latency:     latency,
+		Admin:       admin,
+		AdminServer: adminServer,
 	}, nil
 }
 
+// registerGroups mounts each group, and the groups nested in it, as a gin group under
+// parent, so group middleware runs before the handlers of every route inside it.
+// prefix is the groups' path relative to the API root, used to record their routes.
+func registerGroups(parent *gin.RouterGroup, prefix string, groups []*route.Group, registered *pathMethods) error {
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+		group := parent.Group(g.Prefix, g.Middleware...)
+		path := route.JoinPath(prefix, g.Prefix)
+		if err := registerRoutes(group, path, g.Routes, registered); err != nil {
+			return err
+		}
+		if err := registerGroups(group, path, g.Groups, registered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerRoutes registers routes on group along with the middleware their options
+// call for.
+func registerRoutes(group *gin.RouterGroup, prefix string, routes []*route.Handler, registered *pathMethods) error {
+	for _, h := range routes {
+		if h == nil {
+			continue
+		}
+		path := route.JoinPath(prefix, h.Path)
+		handlers := h.Handler
+		if h.Schema != nil {
+			handlers = append([]gin.HandlerFunc{SchemaMiddleware(h.Schema)}, handlers...)
+		}
+		if h.Streaming {
+			if h.Schema != nil {
+				return fmt.Errorf("invalid route %s: streaming routes cannot validate a schema", path)
+			}
+			handlers = append([]gin.HandlerFunc{streamingMiddleware()}, handlers...)
+		}
+		if h.BodyOptional {
+			handlers = append([]gin.HandlerFunc{bodyOptionalMiddleware()}, handlers...)
+		}
+		if h.MaxConcurrent > 0 {
+			handlers = append([]gin.HandlerFunc{ConcurrencyLimitMiddleware(h.MaxConcurrent, h.ConcurrencyWait)}, handlers...)
+		}
+
+		method, err := routeMethod(h.Method)
+		if err != nil {
+			return fmt.Errorf("invalid route %s: %w", path, err)
+		}
+		if method == route.MethodAny {
+			group.Any(h.Path, handlers...)
+			for _, m := range route.AnyMethods {
+				registered.add(path, m)
+			}
+			continue
+		}
+		group.Handle(method, h.Path, handlers...)
+		registered.add(path, method)
+	}
+	return nil
+}
+
+// Mount serves every request under prefix with handler, e.g. a gRPC gateway mux.
+// The full request path is forwarded so handlers matching absolute paths keep working.
+func (s *HTTPService) Mount(prefix string, handler http.Handler) {
+	prefix = strings.TrimRight(prefix, "/")
+	s.Engine.Any(prefix+"/*path", gin.WrapH(handler))
+}
+
 // ListenAndServe starts serving requests on the given listener.
 func (s *HTTPService) ListenAndServe(l net.Listener) error {
 	s.Service.Logger.Info("HTTP server listening on %s", formatAddr(l.Addr().String()))
 	return s.Server.Serve(l)
 }
 
+// middleware returns the built-in middleware followed by the service's own.
+func middleware(svc *service.Service, opts []Option, requestLog *RequestLogBuffer, latency *LatencyTracker) []*route.Middleware {
+	builtin := []*route.Middleware{
+		{Name: "recovery", Phase: route.PhaseRecovery, Handler: RecoveryMiddleware(svc)},
+		{Name: "gin-context", Phase: route.PhaseContext, Handler: ctxmw.GinContextToContextMiddleware()},
+		{Name: "log-context", Phase: route.PhaseContext, Priority: 1, Handler: LogContextMiddleware(svc)},
+		{Name: "service-context", Phase: route.PhaseContext, Priority: 2, Handler: ServiceContextMiddleware(svc)},
+		{Name: "tracing", Phase: route.PhaseContext, Priority: 6, Handler: TracingMiddleware(tracing.NewSampler(svc))},
+	}
+	if envelopeEnabled() {
+		builtin = append(builtin, &route.Middleware{Name: "response-envelope", Phase: route.PhaseContext, Priority: 3, Handler: ResponseEnvelopeMiddleware()})
+	}
+	for _, opt := range opts {
+		// Answer preflights before auth so browsers can discover what they may send
+		if opt.CORS != nil {
+			builtin = append(builtin, &route.Middleware{Name: "cors", Phase: route.PhaseContext, Priority: 4, Handler: CORS(*opt.CORS)})
+		}
+		if opt.Compression != nil {
+			builtin = append(builtin, &route.Middleware{Name: "compression", Phase: route.PhaseContext, Priority: 5, Handler: Compression(opt.Compression.Level, opt.Compression.MinLength)})
+		}
+	}
+	if multiTenantEnabled() {
+		builtin = append(builtin, &route.Middleware{Name: "tenant", Phase: route.PhaseAuth, Handler: TenantMiddleware(tenantOptionsFromEnv())})
+	}
+	if accessLogEnabled() {
+		builtin = append(builtin, &route.Middleware{Name: "access-log", Phase: route.PhaseLogging, Priority: -1, Handler: AccessLogMiddleware(accessLogOptionsFromEnv(svc))})
+	}
+	if latency != nil {
+		builtin = append(builtin, &route.Middleware{Name: "latency", Phase: route.PhaseLogging, Priority: -2, Handler: LatencyMiddleware(latency)})
+	}
+	if threshold := slowRequestThreshold(svc); threshold > 0 {
+		builtin = append(builtin, &route.Middleware{Name: "slow-request", Phase: route.PhaseLogging, Handler: SlowRequestMiddleware(threshold)})
+	}
+	if requestLog != nil {
+		builtin = append(builtin, &route.Middleware{Name: "request-log", Phase: route.PhaseLogging, Priority: 1, Handler: RequestLogMiddleware(requestLog, 0)})
+	}
+	return append(builtin, svc.HTTPMiddleware...)
+}
+
+// routeMethods lists the verbs accepted on route.Handler.Method.
+var routeMethods = map[string]bool{
+	route.MethodAny:    true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// routeMethod normalizes a declared route method, so "get" and " Get " register as GET.
+func routeMethod(raw string) (string, error) {
+	method := strings.ToUpper(strings.TrimSpace(raw))
+	if !routeMethods[method] {
+		return "", fmt.Errorf("unsupported HTTP method %q", raw)
+	}
+	return method, nil
+}
+
+// slowRequestThreshold reads the slow request threshold in milliseconds from SLOW_REQUEST_MS.
+// Zero (the default) disables slow request logging.
+func slowRequestThreshold(svc *service.Service) time.Duration {
+	raw := os.Getenv("SLOW_REQUEST_MS")
+	if raw == "" {
+		return 0
+	}
+
+	ms, err := strconv.Atoi(raw)
+	if err != nil || ms < 0 {
+		svc.Logger.Warn("invalid SLOW_REQUEST_MS %q, slow request logging disabled", raw)
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// MetricsPath serves metrics.Default when METRICS_ENABLED=true.
+const MetricsPath = "/metrics"
+
+// metricsEnabled reports whether the metrics endpoint should be mounted.
+func metricsEnabled() bool {
+	return os.Getenv("METRICS_ENABLED") == "true"
+}
+
+// redirectTrailingSlash reads HTTP_TRAILING_SLASH. "redirect" (the default) keeps gin's
+// 301/307 redirects between /foo/ and /foo; "notfound" disables them, along with
+// fixed-path redirects, so mismatched paths return 404 instead.
+func redirectTrailingSlash(svc *service.Service) bool {
+	switch raw := os.Getenv("HTTP_TRAILING_SLASH"); raw {
+	case "", "redirect":
+		return true
+	case "notfound":
+		return false
+	default:
+		svc.Logger.Warn("invalid HTTP_TRAILING_SLASH %q, using redirect", raw)
+		return true
+	}
+}
+
+// maxHeaderBytes reads the request header size limit from MAX_HEADER_BYTES.
+// Requests exceeding it are rejected by net/http with 431 Request Header Fields Too Large.
+func maxHeaderBytes(svc *service.Service) int {
+	raw := os.Getenv("MAX_HEADER_BYTES")
+	if raw == "" {
+		return http.DefaultMaxHeaderBytes
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		svc.Logger.Warn("invalid MAX_HEADER_BYTES %q, using default of %d", raw, http.DefaultMaxHeaderBytes)
+		return http.DefaultMaxHeaderBytes
+	}
+	return n
+}
+
+// abortWithError logs err through the request logger and aborts with the same JSON
+// error shape as service.HandleErr, enveloped when RESPONSE_ENVELOPE is on.
+func abortWithError(c *gin.Context, err error, message string, code int) {
+	abortWithLog(c, LoggerFromContext(c).Error, err, message, code)
+}
+
+// abortWithWarning is abortWithError for rejections caused by the client, logged at Warn.
+func abortWithWarning(c *gin.Context, err error, message string, code int) {
+	abortWithLog(c, LoggerFromContext(c).Warn, err, message, code)
+}
+
+func abortWithLog(c *gin.Context, log func(format string, args ...interface{}), err error, message string, code int) {
+	if message == "" {
+		log("%s", logging.RedactText(err.Error()))
+		abortWithJSON(c, code, gin.H{"error": err.Error()})
+		return
+	}
+
+	log("%s: %s", message, logging.RedactText(err.Error()))
+	abortWithJSON(c, code, gin.H{"error": err.Error(), "details": message})
+}
+
 // formatAddr normalizes the listener address for readable logs.
 func formatAddr(addr string) string {
 	re := regexp.MustCompile(`\[::\]`)
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	"github.com/ranorsolutions/svc-common-go/pkg/route"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newMockService(t *testing.T) *service.Service {
@@ -78,3 +81,230 @@ func TestListenAndServeLogsMessage(t *testing.T) {
 	err := h.ListenAndServe(l)
 	assert.Error(t, err) // server closed
 }
+
+func TestMaxHeaderBytes_Default(t *testing.T) {
+	t.Setenv("MAX_HEADER_BYTES", "")
+	h, err := New(newMockService(t), "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, http.DefaultMaxHeaderBytes, h.Server.MaxHeaderBytes)
+}
+
+func TestMaxHeaderBytes_Invalid(t *testing.T) {
+	t.Setenv("MAX_HEADER_BYTES", "lots")
+	h, err := New(newMockService(t), "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, http.DefaultMaxHeaderBytes, h.Server.MaxHeaderBytes)
+}
+
+func TestMaxHeaderBytes_RejectsOversizedHeaders(t *testing.T) {
+	t.Setenv("MAX_HEADER_BYTES", "1024")
+	h, err := New(newMockService(t), "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, h.Server.MaxHeaderBytes)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = h.ListenAndServe(l) }()
+	defer h.Server.Close()
+
+	url := "http://" + l.Addr().String() + "/api/v1/ping"
+
+	// Small headers pass through
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A huge cookie exceeds the limit (net/http allows 4096 bytes of slack)
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Cookie", "session="+strings.Repeat("x", 16*1024))
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
+
+func TestNew_MiddlewareOrderedByPhase(t *testing.T) {
+	svc := newMockService(t)
+
+	var order []string
+	record := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			order = append(order, name)
+			c.Next()
+		}
+	}
+	svc.HTTPMiddleware = []*route.Middleware{
+		{Name: "auth", Phase: route.PhaseAuth, Handler: record("auth")},
+		{Name: "logging", Phase: route.PhaseLogging, Handler: record("logging")},
+		{Name: "rate-limit", Phase: route.PhaseRateLimit, Handler: record("rate-limit")},
+	}
+
+	h, err := New(svc, "v1")
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, []string{"logging", "rate-limit", "auth"}, order)
+}
+
+func TestNew_RecoveryRunsBeforeCustomMiddleware(t *testing.T) {
+	svc := newMockService(t)
+	svc.HTTPMiddleware = []*route.Middleware{
+		{Name: "panics", Phase: route.PhaseAuth, Handler: func(c *gin.Context) { panic("boom") }},
+	}
+
+	h, err := New(svc, "v1")
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestMount_ForwardsFullPath(t *testing.T) {
+	h, err := New(newMockService(t), "v1")
+	assert.NoError(t, err)
+
+	h.Mount("/v1/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/users/42", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "/v1/users/42", rec.Body.String())
+}
+
+func TestNew_TrailingSlash(t *testing.T) {
+	cases := []struct {
+		mode string
+		code int
+	}{
+		{"", http.StatusMovedPermanently},
+		{"redirect", http.StatusMovedPermanently},
+		{"notfound", http.StatusNotFound},
+		{"bogus", http.StatusMovedPermanently},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			t.Setenv("HTTP_TRAILING_SLASH", tc.mode)
+			h, err := New(newMockService(t), "v1")
+			assert.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ping/", nil))
+			assert.Equal(t, tc.code, rec.Code)
+
+			// Exact paths are unaffected
+			rec = httptest.NewRecorder()
+			h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
+func TestNew_MetricsEndpoint(t *testing.T) {
+	h, err := New(newMockService(t), "v1")
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	t.Setenv("METRICS_ENABLED", "true")
+	h, err = New(newMockService(t), "v1")
+	assert.NoError(t, err)
+	rec = httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestNew_NormalizesRouteMethods(t *testing.T) {
+	svc := newMockService(t)
+	ok := []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }}
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: "get", Path: "/lower", Handler: ok},
+		{Method: " Post ", Path: "/mixed", Handler: ok},
+		{Method: "patch", Path: "/patch", Handler: ok},
+	}
+
+	h, err := New(svc, "v1")
+	assert.NoError(t, err)
+
+	for method, path := range map[string]string{http.MethodGet: "/lower", http.MethodPost: "/mixed", http.MethodPatch: "/patch"} {
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, httptest.NewRequest(method, "/api/v1"+path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "%s %s", method, path)
+	}
+}
+
+func TestNew_HeadAndOptionsRoutes(t *testing.T) {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodHead, Path: "/files/:id", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Header("Content-Length", "42") }}},
+		{Method: http.MethodOptions, Path: "/files/:id", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusNoContent) }}},
+	}
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/api/v1/files/1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "42", rec.Header().Get("Content-Length"))
+
+	rec = httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/api/v1/files/1", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestNew_AnyMethodRoute(t *testing.T) {
+	t.Setenv("AUTO_OPTIONS", "true")
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: "any", Path: "/webhooks", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.String(http.StatusOK, c.Request.Method) }}},
+	}
+	h, err := New(svc, "v1")
+	require.NoError(t, err, "automatic OPTIONS must not collide with the catch-all route")
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete, http.MethodOptions} {
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, httptest.NewRequest(method, "/api/v1/webhooks", nil))
+		assert.Equal(t, http.StatusOK, rec.Code, method)
+		assert.Equal(t, method, rec.Body.String())
+	}
+
+	// Declaring a method the catch-all already serves is a conflict
+	svc.HTTPHandlers = append(svc.HTTPHandlers, &route.Handler{Method: http.MethodPatch, Path: "/webhooks"})
+	_, err = New(svc, "v1")
+	assert.EqualError(t, err, "conflicting routes: PATCH /webhooks duplicates PATCH /webhooks")
+}
+
+func TestNew_RejectsUnknownRouteMethod(t *testing.T) {
+	svc := newMockService(t)
+	svc.HTTPHandlers = append(svc.HTTPHandlers, &route.Handler{Method: "FETCH", Path: "/items"})
+
+	h, err := New(svc, "v1")
+	assert.Nil(t, h)
+	assert.EqualError(t, err, `invalid route /items: unsupported HTTP method "FETCH"`)
+}
+
+func TestAbortWithError_RedactsLoggedSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &recordingSink{}
+	r := gin.New()
+	r.Use(withSink(sink))
+	r.GET("/sync", func(c *gin.Context) {
+		abortWithError(c, errors.New("dial postgres://app:hunter2@db/app: token=abc123"), "sync failed", http.StatusBadGateway)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sync", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Len(t, sink.Lines(), 1)
+	assert.Contains(t, sink.Lines()[0], "sync failed: dial postgres://app:[REDACTED]@db/app: token=[REDACTED]")
+	assert.NotContains(t, sink.Lines()[0], "hunter2")
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Optimistic concurrency errors reported by CheckIfMatch.
+var (
+	ErrPreconditionFailed   = errors.New("resource has been modified")
+	ErrPreconditionRequired = errors.New("missing If-Match header")
+)
+
+// CheckIfMatch implements optimistic concurrency for updates: it compares the request's
+// If-Match header against etag, the current version of the resource, and reports
+// whether the update may proceed. On a mismatch it aborts with 412 Precondition Failed
+// and sends the current ETag; without the header it aborts with 428 Precondition
+// Required when required is set and lets the request through otherwise.
+// etag may be given bare or quoted; weak tags never match, as If-Match uses strong
+// comparison, and "*" matches any current version.
+func CheckIfMatch(c *gin.Context, etag string, required bool) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if required {
+			abortWithWarning(c, ErrPreconditionRequired, "updates must be conditional on the current ETag", http.StatusPreconditionRequired)
+			return false
+		}
+		return true
+	}
+
+	current := quoteETag(etag)
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if !strings.HasPrefix(tag, "W/") && tag == current {
+			return true
+		}
+	}
+
+	c.Header("ETag", current)
+	abortWithWarning(c, ErrPreconditionFailed, "the resource changed since it was read", http.StatusPreconditionFailed)
+	return false
+}
+
+// quoteETag wraps a bare version in quotes, leaving quoted and weak tags as they are.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return `"` + etag + `"`
+}
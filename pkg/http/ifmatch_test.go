@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveIfMatch(etag string, required bool, ifMatch string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.PUT("/docs/1", func(c *gin.Context) {
+		if !CheckIfMatch(c, etag, required) {
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/docs/1", nil)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCheckIfMatch_Matching(t *testing.T) {
+	for _, header := range []string{`"v2"`, `"v1", "v2"`, `*`} {
+		assert.Equal(t, http.StatusNoContent, serveIfMatch("v2", true, header).Code, header)
+	}
+	assert.Equal(t, http.StatusNoContent, serveIfMatch(`"v2"`, true, `"v2"`).Code)
+}
+
+func TestCheckIfMatch_Mismatching(t *testing.T) {
+	rec := serveIfMatch("v3", true, `"v2"`)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, `"v3"`, rec.Header().Get("ETag"))
+	assert.Contains(t, rec.Body.String(), ErrPreconditionFailed.Error())
+
+	// Weak tags never satisfy If-Match
+	assert.Equal(t, http.StatusPreconditionFailed, serveIfMatch("v2", true, `W/"v2"`).Code)
+}
+
+func TestCheckIfMatch_Missing(t *testing.T) {
+	rec := serveIfMatch("v1", true, "")
+	assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrPreconditionRequired.Error())
+
+	assert.Equal(t, http.StatusNoContent, serveIfMatch("v1", false, "").Code)
+}
+
+func TestCheckIfMatch_LogsRejectionsAsWarnings(t *testing.T) {
+	sink := &recordingSink{}
+	r := gin.New()
+	r.PUT("/docs/1", withSink(sink), func(c *gin.Context) { CheckIfMatch(c, "v3", true) })
+
+	for _, ifMatch := range []string{"", `"v2"`} {
+		req := httptest.NewRequest(http.MethodPut, "/docs/1", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Len(t, sink.Lines(), 2)
+	for _, line := range sink.Lines() {
+		assert.True(t, strings.HasPrefix(line, "WARN "), line)
+	}
+}
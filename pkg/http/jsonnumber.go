@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxExactFloat is 2^53; from there on a float64 may be a rounded integer.
+const maxExactFloat = 1 << 53
+
+// jsonUseNumber reports whether JSON_USE_NUMBER=true asks for JSON numbers bound
+// into interface{} values to be decoded as json.Number instead of float64.
+func jsonUseNumber() bool {
+	return os.Getenv("JSON_USE_NUMBER") == "true"
+}
+
+// useNumberOnce guards gin's process-wide decoder setting, so services created while
+// others are already serving do not write it under their handlers.
+var useNumberOnce sync.Once
+
+// enableDecoderUseNumber makes gin decode JSON numbers bound into interface{} values
+// as json.Number, setting it once no matter how many services are created.
+func enableDecoderUseNumber() {
+	useNumberOnce.Do(func() { binding.EnableDecoderUseNumber = true })
+}
+
+// JSONInt64 extracts an int64 from a decoded JSON value. It accepts json.Number,
+// decimal strings, Go integers, and whole float64 values below 2^53, so larger IDs
+// are rejected rather than silently corrupted when the body was not decoded with
+// JSON_USE_NUMBER.
+func JSONInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q: %w", n, err)
+		}
+		return i, nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q: %w", n, err)
+		}
+		return i, nil
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) >= maxExactFloat {
+			return 0, fmt.Errorf("number %v cannot be represented exactly as an integer", n)
+		}
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", v)
+	}
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeID is 2^53 + 1, the smallest positive integer a float64 cannot hold.
+const largeID = "9007199254740993"
+
+func useNumber(t *testing.T) {
+	orig := binding.EnableDecoderUseNumber
+	t.Cleanup(func() {
+		binding.EnableDecoderUseNumber = orig
+		useNumberOnce = sync.Once{}
+	})
+	t.Setenv("JSON_USE_NUMBER", "true")
+}
+
+func TestNew_JSONUseNumberPreservesLargeIntegers(t *testing.T) {
+	useNumber(t)
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{
+		Method: http.MethodPost,
+		Path:   "/echo",
+		Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			var body map[string]any
+			require.NoError(t, c.ShouldBindJSON(&body))
+			id, err := JSONInt64(body["id"])
+			require.NoError(t, err)
+			assert.Equal(t, int64(9007199254740993), id)
+			c.JSON(http.StatusOK, body)
+		}},
+	}}
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/echo", strings.NewReader(`{"id":`+largeID+`}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":`+largeID+`}`, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), largeID)
+}
+
+func TestNew_JSONUseNumberConcurrentServices(t *testing.T) {
+	useNumber(t)
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{
+		Method: http.MethodPost,
+		Path:   "/echo",
+		Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			var body map[string]any
+			_ = c.ShouldBindJSON(&body)
+			c.JSON(http.StatusOK, body)
+		}},
+	}}
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	// Creating more services while the first serves must not rewrite gin's setting under it
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := New(svc, "v1")
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/echo", strings.NewReader(`{"id":`+largeID+`}`)))
+			assert.Contains(t, rec.Body.String(), largeID)
+		}()
+	}
+	wg.Wait()
+}
+
+type attrsRequest struct {
+	Attrs map[string]any `json:"attrs"`
+}
+
+func TestBindAndValidate_UseNumber(t *testing.T) {
+	useNumber(t)
+	binding.EnableDecoderUseNumber = true
+
+	r := gin.New()
+	r.POST("/attrs", TypedHandler(func(_ context.Context, req attrsRequest) (map[string]any, int, error) {
+		return req.Attrs, http.StatusOK, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/attrs", strings.NewReader(`{"attrs":{"id":`+largeID+`}}`)))
+	assert.Equal(t, `{"id":`+largeID+`}`, rec.Body.String())
+}
+
+func TestBindAndValidate_DefaultLosesPrecision(t *testing.T) {
+	r := gin.New()
+	r.POST("/attrs", TypedHandler(func(_ context.Context, req attrsRequest) (map[string]any, int, error) {
+		_, err := JSONInt64(req.Attrs["id"])
+		assert.Error(t, err, "float64 beyond 2^53 is rejected instead of corrupted")
+		return req.Attrs, http.StatusOK, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/attrs", strings.NewReader(`{"attrs":{"id":`+largeID+`}}`)))
+	assert.NotContains(t, rec.Body.String(), largeID)
+}
+
+func TestJSONInt64(t *testing.T) {
+	for _, v := range []any{json.Number(largeID), largeID, int64(9007199254740993)} {
+		got, err := JSONInt64(v)
+		require.NoError(t, err, "%T", v)
+		assert.Equal(t, int64(9007199254740993), got)
+	}
+
+	got, err := JSONInt64(float64(42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), got)
+
+	for _, v := range []any{1.5, float64(1 << 60), json.Number("1.5"), "abc", true, nil} {
+		_, err := JSONInt64(v)
+		assert.Error(t, err, "%v", v)
+	}
+}
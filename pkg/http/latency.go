@@ -0,0 +1,152 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugLatencyPath serves per-route latency percentiles on the admin listener when
+// DEBUG_LATENCY=true.
+const DebugLatencyPath = "/debug/latency"
+
+// Latencies are bucketed on a logarithmic scale from latencyMin to latencyMax, each
+// bucket latencyGrowth times wider than the last, so percentiles are reported within
+// about 1% of the observed value in a fixed ~9KB per route.
+const (
+	latencyMin    = time.Microsecond
+	latencyMax    = time.Hour
+	latencyGrowth = 1.02
+)
+
+var latencyBuckets = int(math.Ceil(math.Log(float64(latencyMax/latencyMin))/math.Log(latencyGrowth))) + 1
+
+// maxLatencyRoutes caps the routes tracked by a LatencyTracker.
+const maxLatencyRoutes = 1000
+
+// debugLatencyEnabled reports whether New should track and serve route latencies.
+func debugLatencyEnabled() bool {
+	return os.Getenv("DEBUG_LATENCY") == "true"
+}
+
+// RouteLatency reports the latency percentiles of one route, in milliseconds.
+type RouteLatency struct {
+	Method string  `json:"method"`
+	Route  string  `json:"route"`
+	Count  uint64  `json:"count"`
+	P50    float64 `json:"p50_ms"`
+	P90    float64 `json:"p90_ms"`
+	P99    float64 `json:"p99_ms"`
+}
+
+// latencyHistogram counts latencies into logarithmic buckets.
+type latencyHistogram struct {
+	counts []uint64
+	count  uint64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	if d > latencyMin {
+		i = int(math.Log(float64(d)/float64(latencyMin)) / math.Log(latencyGrowth))
+	}
+	h.counts[min(i, len(h.counts)-1)]++
+	h.count++
+}
+
+// quantile returns the midpoint of the bucket holding the q-th observation.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	var seen uint64
+	for i, n := range h.counts {
+		if seen += n; n > 0 && seen >= rank {
+			return time.Duration(float64(latencyMin) * math.Pow(latencyGrowth, float64(i)+0.5))
+		}
+	}
+	return 0
+}
+
+// LatencyTracker keeps a latency histogram per route template in bounded memory.
+// It is safe for concurrent use.
+type LatencyTracker struct {
+	mu     sync.Mutex
+	routes map[string]*latencyHistogram
+}
+
+// NewLatencyTracker creates an empty tracker.
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{routes: map[string]*latencyHistogram{}}
+}
+
+// Observe records a request to route taking d. Once maxLatencyRoutes routes are
+// tracked, requests to new routes are ignored.
+func (t *LatencyTracker) Observe(method, route string, d time.Duration) {
+	key := method + " " + route
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.routes[key]
+	if !ok {
+		if len(t.routes) >= maxLatencyRoutes {
+			return
+		}
+		h = &latencyHistogram{counts: make([]uint64, latencyBuckets)}
+		t.routes[key] = h
+	}
+	h.observe(d)
+}
+
+// Latencies returns the percentiles of every tracked route, sorted by route and method.
+func (t *LatencyTracker) Latencies() []RouteLatency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	out := make([]RouteLatency, 0, len(t.routes))
+	for key, h := range t.routes {
+		method, route, _ := strings.Cut(key, " ")
+		out = append(out, RouteLatency{
+			Method: method,
+			Route:  route,
+			Count:  h.count,
+			P50:    ms(h.quantile(0.5)),
+			P90:    ms(h.quantile(0.9)),
+			P99:    ms(h.quantile(0.99)),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Route != out[j].Route {
+			return out[i].Route < out[j].Route
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// LatencyMiddleware records the latency of every request into t by route template.
+// Unmatched requests and debug endpoints are not recorded.
+func LatencyMiddleware(t *LatencyTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" || strings.HasPrefix(route, "/debug/") {
+			return
+		}
+		t.Observe(c.Request.Method, route, time.Since(start))
+	}
+}
+
+// LatencyHandler serves the percentiles tracked by t.
+func LatencyHandler(t *LatencyTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"routes": t.Latencies()})
+	}
+}
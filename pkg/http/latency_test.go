@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyTracker_Percentiles(t *testing.T) {
+	tr := NewLatencyTracker()
+
+	// 1ms..1000ms uniformly, in shuffled order: p50 ~ 500ms, p90 ~ 900ms, p99 ~ 990ms
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, i := range r.Perm(1000) {
+		tr.Observe(http.MethodGet, "/users/:id", time.Duration(i+1)*time.Millisecond)
+	}
+	tr.Observe(http.MethodPost, "/users", 5*time.Millisecond)
+
+	latencies := tr.Latencies()
+	require.Len(t, latencies, 2)
+	got := latencies[1]
+	assert.Equal(t, http.MethodGet, got.Method)
+	assert.Equal(t, "/users/:id", got.Route)
+	assert.EqualValues(t, 1000, got.Count)
+	assert.InEpsilon(t, 500, got.P50, 0.02)
+	assert.InEpsilon(t, 900, got.P90, 0.02)
+	assert.InEpsilon(t, 990, got.P99, 0.02)
+
+	assert.Equal(t, "/users", latencies[0].Route)
+	assert.InEpsilon(t, 5, latencies[0].P99, 0.02)
+}
+
+func TestLatencyTracker_Bounded(t *testing.T) {
+	tr := NewLatencyTracker()
+	for i := 0; i < maxLatencyRoutes+10; i++ {
+		tr.Observe(http.MethodGet, "/r/"+time.Duration(i).String(), time.Millisecond)
+	}
+	assert.Len(t, tr.Latencies(), maxLatencyRoutes)
+
+	// Out-of-range latencies land in the edge buckets
+	tr = NewLatencyTracker()
+	tr.Observe(http.MethodGet, "/fast", 0)
+	tr.Observe(http.MethodGet, "/slow", 48*time.Hour)
+	latencies := tr.Latencies()
+	assert.Less(t, latencies[0].P50, 0.01)
+	assert.GreaterOrEqual(t, latencies[1].P50, float64(time.Hour/time.Millisecond))
+}
+
+func TestNew_DebugLatency(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		h, err := New(newMockService(t), "v1")
+		require.NoError(t, err)
+		assert.Nil(t, h.Latency)
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugLatencyPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DEBUG_LATENCY", "true")
+		t.Setenv("ADMIN_PORT", "0")
+		svc := newMockService(t)
+		svc.HTTPHandlers = []*route.Handler{
+			{Method: http.MethodGet, Path: "/users/:id", Handler: []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }}},
+		}
+		h, err := New(svc, "v1")
+		require.NoError(t, err)
+
+		for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/missing"} {
+			h.Engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		w := httptest.NewRecorder()
+		h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugLatencyPath, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, "debug endpoints stay off the public listener")
+
+		w = httptest.NewRecorder()
+		h.Admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugLatencyPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Routes []RouteLatency `json:"routes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Routes, 1, "unmatched requests and debug endpoints are not tracked")
+		assert.Equal(t, "/api/v1/users/:id", body.Routes[0].Route)
+		assert.EqualValues(t, 2, body.Routes[0].Count)
+	})
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// RequestIDHeader is the header used to read and echo the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// LogContextMiddleware assigns every request an ID (reusing an incoming X-Request-ID)
+// and stores a contextual logger on the request context, so gRPC calls made with
+// c.Request.Context() propagate the request fields downstream.
+func LogContextMiddleware(svc *service.Service) gin.HandlerFunc {
+	var sink logging.Sink
+	if svc != nil {
+		sink = svc.LogSink()
+	}
+
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Set(logging.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		l := logging.New(sink).
+			With(logging.FieldRequestID, id).
+			With(logging.FieldRoute, c.FullPath())
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), l))
+
+		c.Next()
+	}
+}
+
+// LoggerFromContext returns a logger pre-populated with the request ID, the
+// authenticated user ID (when set) and the matched route template.
+func LoggerFromContext(c *gin.Context) *logging.Logger {
+	return logging.FromContext(c.Request.Context()).
+		With(logging.FieldRequestID, c.GetString(logging.RequestIDKey)).
+		With(logging.FieldUserID, c.GetString(logging.UserIDKey)).
+		With(logging.FieldRoute, c.FullPath())
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogContextMiddleware_PopulatesLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newMockService(t)
+
+	var got *logging.Logger
+	r := gin.New()
+	r.Use(LogContextMiddleware(svc))
+	r.GET("/users/:id", func(c *gin.Context) {
+		c.Set(logging.UserIDKey, "user-1")
+		got = LoggerFromContext(c)
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set(RequestIDHeader, "req-abc")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-abc", rec.Header().Get(RequestIDHeader))
+	if assert.NotNil(t, got) {
+		assert.Equal(t, "req-abc", got.Field(logging.FieldRequestID))
+		assert.Equal(t, "user-1", got.Field(logging.FieldUserID))
+		assert.Equal(t, "/users/:id", got.Field(logging.FieldRoute))
+	}
+}
+
+func TestLogContextMiddleware_GeneratesRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newMockService(t)
+
+	var fromCtx string
+	r := gin.New()
+	r.Use(LogContextMiddleware(svc))
+	r.GET("/ping", func(c *gin.Context) {
+		fromCtx = logging.FromContext(c.Request.Context()).Field(logging.FieldRequestID)
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	id := rec.Header().Get(RequestIDHeader)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, id, fromCtx)
+}
+
+// --- Recording sink shared by middleware tests ---
+
+type recordingSink struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (s *recordingSink) record(level, format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (s *recordingSink) Info(format string, args ...interface{})  { s.record("INFO", format, args...) }
+func (s *recordingSink) Warn(format string, args ...interface{})  { s.record("WARN", format, args...) }
+func (s *recordingSink) Error(format string, args ...interface{}) { s.record("ERROR", format, args...) }
+func (s *recordingSink) Debug(format string, args ...interface{}) { s.record("DEBUG", format, args...) }
+
+func (s *recordingSink) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+// withSink installs a contextual logger writing to sink, standing in for LogContextMiddleware.
+func withSink(sink logging.Sink) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logging.New(sink)))
+		c.Next()
+	}
+}
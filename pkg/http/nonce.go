@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NonceHeader carries the single-use nonce checked by NonceMiddleware.
+const NonceHeader = "X-Request-Nonce"
+
+// maxNonceLength bounds the nonces accepted, so stores are not filled with huge keys.
+const maxNonceLength = 128
+
+// Nonce errors reported by NonceMiddleware.
+var (
+	ErrMissingNonce  = errors.New("missing " + NonceHeader + " header")
+	ErrInvalidNonce  = errors.New("request nonce is too long")
+	ErrReplayedNonce = errors.New("request nonce has already been used")
+)
+
+// NonceStore records nonces for the replay window. Implementations must make
+// Add atomic, so concurrent requests with the same nonce cannot both succeed.
+type NonceStore interface {
+	// Add records nonce and reports whether it was unseen within the store's window.
+	Add(ctx context.Context, nonce string) (bool, error)
+}
+
+// NonceMiddleware rejects requests reusing a nonce within the store's window: a
+// missing or oversized nonce is answered with 400, a replay with 409, and a store
+// failure with 503. Use it on sensitive mutating routes; unlike idempotency keys,
+// replays are refused rather than answered with the original response.
+func NonceMiddleware(store NonceStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		nonce := c.GetHeader(NonceHeader)
+		if nonce == "" {
+			abortWithWarning(c, ErrMissingNonce, "a unique request nonce is required", http.StatusBadRequest)
+			return
+		}
+		if len(nonce) > maxNonceLength {
+			abortWithWarning(c, ErrInvalidNonce, "nonces are limited to 128 characters", http.StatusBadRequest)
+			return
+		}
+
+		fresh, err := store.Add(c.Request.Context(), nonce)
+		if err != nil {
+			abortWithError(c, err, "unable to verify request nonce", http.StatusServiceUnavailable)
+			return
+		}
+		if !fresh {
+			abortWithWarning(c, ErrReplayedNonce, "replayed request rejected", http.StatusConflict)
+			return
+		}
+		c.Next()
+	}
+}
+
+// MemoryNonceStore is an in-memory NonceStore remembering nonces for a fixed TTL.
+// It only protects a single replica; use a shared store when running several.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	seen   map[string]time.Time
+	now    func() time.Time
+	nextGC time.Time
+}
+
+// NewMemoryNonceStore creates a store rejecting nonces reused within ttl.
+func NewMemoryNonceStore(ttl time.Duration) *MemoryNonceStore {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &MemoryNonceStore{ttl: ttl, seen: map[string]time.Time{}, now: time.Now}
+}
+
+// Add records nonce, reporting false if it was recorded less than ttl ago.
+func (m *MemoryNonceStore) Add(_ context.Context, nonce string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.After(m.nextGC) {
+		for n, expires := range m.seen {
+			if !now.Before(expires) {
+				delete(m.seen, n)
+			}
+		}
+		m.nextGC = now.Add(m.ttl)
+	}
+
+	if expires, ok := m.seen[nonce]; ok && now.Before(expires) {
+		return false, nil
+	}
+	m.seen[nonce] = now.Add(m.ttl)
+	return true, nil
+}
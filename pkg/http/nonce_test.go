@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNonceEngine(store NonceStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/transfers", NonceMiddleware(store), func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return r
+}
+
+func postWithNonce(r *gin.Engine, nonce string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/transfers", nil)
+	if nonce != "" {
+		req.Header.Set(NonceHeader, nonce)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNonceMiddleware_RejectsReplay(t *testing.T) {
+	r := newNonceEngine(NewMemoryNonceStore(time.Minute))
+
+	assert.Equal(t, http.StatusCreated, postWithNonce(r, "n-1").Code)
+
+	rec := postWithNonce(r, "n-1")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrReplayedNonce.Error())
+
+	assert.Equal(t, http.StatusCreated, postWithNonce(r, "n-2").Code)
+}
+
+func TestNonceMiddleware_MissingOrOversizedNonce(t *testing.T) {
+	r := newNonceEngine(NewMemoryNonceStore(time.Minute))
+	assert.Equal(t, http.StatusBadRequest, postWithNonce(r, "").Code)
+	assert.Equal(t, http.StatusBadRequest, postWithNonce(r, strings.Repeat("x", maxNonceLength+1)).Code)
+}
+
+type failingNonceStore struct{}
+
+func (failingNonceStore) Add(context.Context, string) (bool, error) {
+	return false, errors.New("redis unavailable")
+}
+
+func TestNonceMiddleware_StoreFailure(t *testing.T) {
+	assert.Equal(t, http.StatusServiceUnavailable, postWithNonce(newNonceEngine(failingNonceStore{}), "n-1").Code)
+}
+
+func TestNonceMiddleware_LogsRejectionsAsWarnings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &recordingSink{}
+	r := gin.New()
+	r.POST("/transfers", withSink(sink), NonceMiddleware(NewMemoryNonceStore(time.Minute)), func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	postWithNonce(r, "n-1")
+	postWithNonce(r, "n-1")
+	postWithNonce(r, "")
+	require.Len(t, sink.Lines(), 2)
+	for _, line := range sink.Lines() {
+		assert.True(t, strings.HasPrefix(line, "WARN "), line)
+	}
+}
+
+func TestMemoryNonceStore_ExpiresAfterTTL(t *testing.T) {
+	store := NewMemoryNonceStore(time.Minute)
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return clock }
+
+	fresh, _ := store.Add(context.Background(), "n-1")
+	assert.True(t, fresh)
+
+	clock = clock.Add(59 * time.Second)
+	fresh, _ = store.Add(context.Background(), "n-1")
+	assert.False(t, fresh, "replay within the window")
+
+	clock = clock.Add(2 * time.Second)
+	fresh, _ = store.Add(context.Background(), "n-1")
+	assert.True(t, fresh, "nonce may be reused once the window has passed")
+
+	clock = clock.Add(2 * time.Minute)
+	_, _ = store.Add(context.Background(), "n-2")
+	assert.NotContains(t, store.seen, "n-1", "expired nonces are purged")
+}
+
+func TestMemoryNonceStore_ConcurrentReplay(t *testing.T) {
+	r := newNonceEngine(NewMemoryNonceStore(time.Minute))
+
+	var created atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if postWithNonce(r, "same").Code == http.StatusCreated {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), created.Load())
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// autoOptionsEnabled reports whether AUTO_OPTIONS=true asks New to answer OPTIONS
+// on every route path that does not declare its own OPTIONS handler.
+func autoOptionsEnabled() bool {
+	return os.Getenv("AUTO_OPTIONS") == "true"
+}
+
+// AllowHandler answers OPTIONS with 204 and an Allow header listing methods,
+// plus OPTIONS itself.
+func AllowHandler(methods []string) gin.HandlerFunc {
+	allowed := append([]string{http.MethodOptions}, methods...)
+	slices.Sort(allowed)
+	allow := strings.Join(slices.Compact(allowed), ", ")
+
+	return func(c *gin.Context) {
+		c.Header("Allow", allow)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// pathMethods collects the methods registered on each route path, in registration order.
+type pathMethods struct {
+	paths   []string
+	methods map[string][]string
+}
+
+func (p *pathMethods) add(path, method string) {
+	if p.methods == nil {
+		p.methods = map[string][]string{}
+	}
+	if _, ok := p.methods[path]; !ok {
+		p.paths = append(p.paths, path)
+	}
+	p.methods[path] = append(p.methods[path], method)
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOptionsService(t *testing.T) *HTTPService {
+	svc := newMockService(t)
+	ok := []gin.HandlerFunc{func(c *gin.Context) { c.Status(http.StatusOK) }}
+	svc.HTTPHandlers = []*route.Handler{
+		{Method: http.MethodGet, Path: "/users/:id", Handler: ok},
+		{Method: http.MethodPut, Path: "/users/:id", Handler: ok},
+		{Method: "delete", Path: "/users/:id", Handler: ok},
+		{Method: http.MethodGet, Path: "/users", Handler: ok},
+		{Method: http.MethodPost, Path: "/users", Handler: ok},
+		{Method: http.MethodGet, Path: "/custom", Handler: ok},
+		{Method: http.MethodOptions, Path: "/custom", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Header("Allow", "custom")
+			c.Status(http.StatusOK)
+		}}},
+	}
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	return h
+}
+
+func options(h *HTTPService, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, path, nil))
+	return rec
+}
+
+func TestAutoOptions_ListsAllowedMethods(t *testing.T) {
+	t.Setenv("AUTO_OPTIONS", "true")
+	h := newOptionsService(t)
+
+	rec := options(h, "/api/v1/users/42")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "DELETE, GET, OPTIONS, PUT", rec.Header().Get("Allow"))
+
+	rec = options(h, "/api/v1/users")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET, OPTIONS, POST", rec.Header().Get("Allow"))
+}
+
+func TestAutoOptions_ExplicitHandlerWins(t *testing.T) {
+	t.Setenv("AUTO_OPTIONS", "true")
+	rec := options(newOptionsService(t), "/api/v1/custom")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "custom", rec.Header().Get("Allow"))
+}
+
+func TestAutoOptions_Disabled(t *testing.T) {
+	t.Setenv("AUTO_OPTIONS", "")
+	rec := options(newOptionsService(t), "/api/v1/users")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Allow"))
+}
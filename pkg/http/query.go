@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryNormalizeConfig configures QueryNormalizeMiddleware.
+type QueryNormalizeConfig struct {
+	// LowercaseKeys lowercases every parameter name, so ?Status= and ?status= are read alike.
+	LowercaseKeys bool
+	// LowercaseValues lists parameters whose values are lowercased, e.g. enum-like filters.
+	LowercaseValues []string
+	// Preserve lists parameters left exactly as sent, e.g. cursors, tokens or signatures.
+	Preserve []string
+}
+
+// QueryNormalizeMiddleware trims whitespace from query values and applies the
+// configured lowercasing before handlers read them. Parameter names are matched
+// case-insensitively against LowercaseValues and Preserve. Parameters merged by
+// LowercaseKeys are combined in the sorted order of their original names, so the
+// rewritten query is deterministic.
+// Register it through svc.HTTPMiddleware at route.PhaseContext, ahead of anything
+// calling c.Query, since gin caches the parsed query on first use.
+func QueryNormalizeMiddleware(cfg QueryNormalizeConfig) gin.HandlerFunc {
+	lowerValues := lowerSet(cfg.LowercaseValues)
+	preserve := lowerSet(cfg.Preserve)
+
+	return func(c *gin.Context) {
+		if c.Request.URL.RawQuery == "" {
+			c.Next()
+			return
+		}
+
+		query := c.Request.URL.Query()
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		normalized := url.Values{}
+		for _, key := range keys {
+			values := query[key]
+			lower := strings.ToLower(key)
+			if _, ok := preserve[lower]; ok {
+				normalized[key] = append(normalized[key], values...)
+				continue
+			}
+
+			name := key
+			if cfg.LowercaseKeys {
+				name = lower
+			}
+			_, lowerValue := lowerValues[lower]
+			for _, v := range values {
+				v = strings.TrimSpace(v)
+				if lowerValue {
+					v = strings.ToLower(v)
+				}
+				normalized[name] = append(normalized[name], v)
+			}
+		}
+
+		c.Request.URL.RawQuery = normalized.Encode()
+		c.Next()
+	}
+}
+
+func lowerSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		set[strings.ToLower(n)] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func normalizeQuery(t *testing.T, cfg QueryNormalizeConfig, rawQuery string) url.Values {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var got url.Values
+	r := gin.New()
+	r.Use(QueryNormalizeMiddleware(cfg))
+	r.GET("/items", func(c *gin.Context) {
+		got = c.Request.URL.Query()
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?"+rawQuery, nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	return got
+}
+
+func TestQueryNormalize_TrimsValues(t *testing.T) {
+	got := normalizeQuery(t, QueryNormalizeConfig{}, "name=%20Alice%20&tag=%09a&tag=b%20")
+	assert.Equal(t, "Alice", got.Get("name"))
+	assert.Equal(t, []string{"a", "b"}, got["tag"])
+}
+
+func TestQueryNormalize_LowercasesSelectedValues(t *testing.T) {
+	cfg := QueryNormalizeConfig{LowercaseValues: []string{"status"}}
+	got := normalizeQuery(t, cfg, "Status=%20ACTIVE&name=Alice")
+	assert.Equal(t, "active", got.Get("Status"))
+	assert.Equal(t, "Alice", got.Get("name"))
+}
+
+func TestQueryNormalize_LowercasesKeys(t *testing.T) {
+	cfg := QueryNormalizeConfig{LowercaseKeys: true}
+	got := normalizeQuery(t, cfg, "Status=Active&STATUS=Pending")
+	assert.Equal(t, []string{"Pending", "Active"}, got["status"])
+	assert.NotContains(t, got, "Status")
+}
+
+func TestQueryNormalize_DeterministicMerge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var raw string
+	r := gin.New()
+	r.Use(QueryNormalizeMiddleware(QueryNormalizeConfig{LowercaseKeys: true}))
+	r.GET("/items", func(c *gin.Context) {
+		raw = c.Request.URL.RawQuery
+		c.Status(http.StatusNoContent)
+	})
+
+	for i := 0; i < 50; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items?status=c&Status=b&STATUS=a&sTatus=d", nil))
+		require.Equal(t, "status=a&status=b&status=d&status=c", raw)
+	}
+}
+
+func TestQueryNormalize_PreservesWhitelisted(t *testing.T) {
+	cfg := QueryNormalizeConfig{LowercaseKeys: true, LowercaseValues: []string{"cursor"}, Preserve: []string{"cursor"}}
+	got := normalizeQuery(t, cfg, "Cursor=%20AbC%3D%3D%20&Q=%20X")
+	assert.Equal(t, " AbC== ", got.Get("Cursor"))
+	assert.Equal(t, "X", got.Get("q"))
+}
+
+func TestQueryNormalize_EmptyQuery(t *testing.T) {
+	assert.Empty(t, normalizeQuery(t, QueryNormalizeConfig{LowercaseKeys: true}, ""))
+}
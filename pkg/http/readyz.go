@@ -0,0 +1,38 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// ReadyzPath answers readiness probes when READYZ_ENABLED=true.
+const ReadyzPath = "/readyz"
+
+// readyzTimeout bounds the health checks run for a single probe.
+var readyzTimeout = 5 * time.Second
+
+// readyzEnabled reports whether the readiness endpoint should be mounted.
+func readyzEnabled() bool {
+	return os.Getenv("READYZ_ENABLED") == "true"
+}
+
+// ReadyzHandler answers 200 while svc is ready and its health checks pass, and 503
+// with the reason otherwise, e.g. while it is marked not ready with svc.SetReady.
+func ReadyzHandler(svc *service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readyzTimeout)
+		defer cancel()
+
+		c.Header("Cache-Control", "no-store")
+		if err := svc.CheckReady(ctx); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	}
+}
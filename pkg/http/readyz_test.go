@@ -0,0 +1,40 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_ReadyzDisabledByDefault(t *testing.T) {
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, getPath(t, h, ReadyzPath).Code)
+}
+
+func TestNew_ReadyzReflectsSetReady(t *testing.T) {
+	t.Setenv("READYZ_ENABLED", "true")
+	svc := newMockService(t)
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	rec := getPath(t, h, ReadyzPath)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ready"}`, rec.Body.String())
+
+	svc.SetReady(false)
+	rec = getPath(t, h, ReadyzPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"not ready","error":"service is not ready"}`, rec.Body.String())
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	svc.SetReady(true)
+	svc.RegisterHealthCheck("cache", func(context.Context) error { return errors.New("rebuilding") })
+	rec = getPath(t, h, ReadyzPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"not ready","error":"health check cache failed: rebuilding"}`, rec.Body.String())
+}
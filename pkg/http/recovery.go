@@ -0,0 +1,34 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// RecoveryMiddleware answers handler panics with 500 like gin.Recovery and, when
+// svc.PanicSink is set, also reports them there with the request's metadata.
+func RecoveryMiddleware(svc *service.Service) gin.HandlerFunc {
+	if svc == nil || svc.PanicSink == nil {
+		return gin.Recovery()
+	}
+
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		service.ReportPanic(c.Request.Context(), svc.PanicSink, service.PanicRecord{
+			Time:      time.Now().UTC(),
+			Protocol:  "http",
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			RequestID: c.GetString(logging.RequestIDKey),
+			UserID:    c.GetString(logging.UserIDKey),
+			Message:   fmt.Sprint(recovered),
+			Stack:     string(debug.Stack()),
+		})
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
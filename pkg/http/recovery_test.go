@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPanicSink chan service.PanicRecord
+
+func (s recordingPanicSink) RecordPanic(_ context.Context, rec service.PanicRecord) error {
+	s <- rec
+	return nil
+}
+
+func TestNew_ReportsPanicsToSink(t *testing.T) {
+	sink := make(recordingPanicSink, 1)
+	svc := newMockService(t)
+	svc.PanicSink = sink
+	svc.HTTPHandlers = []*route.Handler{{
+		Method: http.MethodPost,
+		Path:   "/users/:id",
+		Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Set(logging.UserIDKey, "user-9")
+			panic("boom")
+		}},
+	}}
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/42", nil)
+	req.Header.Set(RequestIDHeader, "req-7")
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	select {
+	case p := <-sink:
+		assert.Equal(t, "http", p.Protocol)
+		assert.Equal(t, http.MethodPost, p.Method)
+		assert.Equal(t, "/api/v1/users/42", p.Path)
+		assert.Equal(t, "req-7", p.RequestID)
+		assert.Equal(t, "user-9", p.UserID)
+		assert.Equal(t, "boom", p.Message)
+		assert.Contains(t, p.Stack, "recovery_test.go")
+		assert.False(t, p.Time.IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+}
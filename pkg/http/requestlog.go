@@ -0,0 +1,223 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// DebugRequestsPath serves the retained request log on the admin listener when
+// REQUEST_LOG_BUFFER is set.
+const DebugRequestsPath = "/debug/requests"
+
+// defaultRequestLogBodyBytes caps how much of each body is retained.
+const defaultRequestLogBodyBytes = 4 << 10
+
+// RequestLogEntry is a retained request/response pair, with secrets in its query,
+// headers and bodies masked.
+type RequestLogEntry struct {
+	Time            time.Time     `json:"time"`
+	RequestID       string        `json:"request_id,omitempty"`
+	Method          string        `json:"method"`
+	Path            string        `json:"path"`
+	Status          int           `json:"status"`
+	Duration        time.Duration `json:"duration"`
+	RequestHeaders  http.Header   `json:"request_headers,omitempty"`
+	ResponseHeaders http.Header   `json:"response_headers,omitempty"`
+	RequestBody     string        `json:"request_body,omitempty"`
+	ResponseBody    string        `json:"response_body,omitempty"`
+	Truncated       bool          `json:"truncated,omitempty"`
+}
+
+// RequestLogBuffer is a fixed-size ring buffer of the most recent request log entries.
+// It is safe for concurrent use.
+type RequestLogBuffer struct {
+	mu      sync.Mutex
+	entries []RequestLogEntry
+	next    int
+	full    bool
+}
+
+// NewRequestLogBuffer creates a buffer retaining the last size entries.
+func NewRequestLogBuffer(size int) *RequestLogBuffer {
+	if size <= 0 {
+		size = 1
+	}
+	return &RequestLogBuffer{entries: make([]RequestLogEntry, size)}
+}
+
+// Add appends e, evicting the oldest entry when the buffer is full.
+func (b *RequestLogBuffer) Add(e RequestLogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Entries returns the retained entries, oldest first.
+func (b *RequestLogBuffer) Entries() []RequestLogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		return append([]RequestLogEntry(nil), b.entries[:b.next]...)
+	}
+	out := make([]RequestLogEntry, 0, len(b.entries))
+	out = append(out, b.entries[b.next:]...)
+	return append(out, b.entries[:b.next]...)
+}
+
+// RequestLogMiddleware records every request into buf, keeping at most maxBody bytes
+// of each request and response body. Headers with secret-looking names, such as
+// Authorization, Cookie and Set-Cookie, and secrets in the query and bodies are
+// masked before they are retained. Debug endpoints are not recorded, and request
+// bodies of streaming routes are omitted.
+func RequestLogMiddleware(buf *RequestLogBuffer, maxBody int) gin.HandlerFunc {
+	if maxBody <= 0 {
+		maxBody = defaultRequestLogBodyBytes
+	}
+
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/debug/") {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		reqBody := &boundedBuffer{max: maxBody}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = &teeReadCloser{Reader: io.TeeReader(c.Request.Body, reqBody), Closer: c.Request.Body}
+		}
+		w := &boundedCaptureWriter{ResponseWriter: c.Writer, body: &boundedBuffer{max: maxBody}}
+		c.Writer = w
+
+		c.Next()
+
+		// Streamed bodies are opaque to the log, keep only the response
+		if c.GetBool(StreamingKey) {
+			reqBody = &boundedBuffer{max: maxBody}
+		}
+
+		path := c.Request.URL.Path
+		if query := c.Request.URL.RawQuery; query != "" {
+			path += "?" + logging.RedactText(query)
+		}
+		buf.Add(RequestLogEntry{
+			Time:            start,
+			RequestID:       c.GetString(logging.RequestIDKey),
+			Method:          c.Request.Method,
+			Path:            path,
+			Status:          w.Status(),
+			Duration:        time.Since(start),
+			RequestHeaders:  redactHeaders(c.Request.Header),
+			ResponseHeaders: redactHeaders(w.Header()),
+			RequestBody:     redactBody(reqBody.String()),
+			ResponseBody:    redactBody(w.body.String()),
+			Truncated:       reqBody.truncated || w.body.truncated,
+		})
+	}
+}
+
+// redactHeaders copies h with the values of secret-looking headers masked.
+func redactHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for name := range out {
+		if logging.Sensitive(name) {
+			out[name] = []string{logging.Mask}
+		}
+	}
+	return out
+}
+
+// redactBody masks secrets in a retained body: by field name for complete JSON
+// documents, and as text for anything else, including truncated JSON.
+func redactBody(body string) string {
+	var doc any
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err == nil && !dec.More() {
+		if redacted, err := json.Marshal(logging.RedactSecrets(doc)); err == nil {
+			return string(redacted)
+		}
+	}
+	return logging.RedactText(body)
+}
+
+// RequestLogHandler serves the retained entries, oldest first.
+func RequestLogHandler(buf *RequestLogBuffer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"entries": buf.Entries()})
+	}
+}
+
+// requestLogBufferSize reads the number of retained request log entries from
+// REQUEST_LOG_BUFFER. Zero (the default) disables request log retention.
+func requestLogBufferSize(svc *service.Service) int {
+	raw := os.Getenv("REQUEST_LOG_BUFFER")
+	if raw == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		svc.Logger.Warn("invalid REQUEST_LOG_BUFFER %q, request log retention disabled", raw)
+		return 0
+	}
+	return n
+}
+
+// boundedBuffer keeps the first max bytes written to it.
+type boundedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// boundedCaptureWriter copies the start of the response body while writing it.
+type boundedCaptureWriter struct {
+	gin.ResponseWriter
+	body *boundedBuffer
+}
+
+func (w *boundedCaptureWriter) Write(b []byte) (int, error) {
+	_, _ = w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *boundedCaptureWriter) WriteString(s string) (int, error) {
+	_, _ = w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
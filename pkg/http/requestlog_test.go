@@ -0,0 +1,147 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogBuffer_RetainsMostRecent(t *testing.T) {
+	buf := NewRequestLogBuffer(3)
+	assert.Empty(t, buf.Entries())
+
+	for i := 1; i <= 5; i++ {
+		buf.Add(RequestLogEntry{Path: fmt.Sprintf("/%d", i)})
+	}
+
+	entries := buf.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "/3", entries[0].Path)
+	assert.Equal(t, "/5", entries[2].Path)
+}
+
+func TestRequestLogBuffer_Concurrent(t *testing.T) {
+	buf := NewRequestLogBuffer(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf.Add(RequestLogEntry{})
+			_ = buf.Entries()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, buf.Entries(), 10)
+}
+
+func TestRequestLogMiddleware_CapturesBoundedBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := NewRequestLogBuffer(5)
+	r := gin.New()
+	r.Use(RequestLogMiddleware(buf, 8))
+	r.POST("/echo", func(c *gin.Context) {
+		var body map[string]any
+		require.NoError(t, c.ShouldBindJSON(&body))
+		c.JSON(http.StatusCreated, body)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"name":"ada lovelace"}`)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"name":"ada lovelace"}`, w.Body.String(), "handlers still see the full bodies")
+
+	entries := buf.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, http.MethodPost, entries[0].Method)
+	assert.Equal(t, http.StatusCreated, entries[0].Status)
+	assert.Equal(t, `{"name":`, entries[0].RequestBody)
+	assert.Equal(t, `{"name":`, entries[0].ResponseBody)
+	assert.True(t, entries[0].Truncated)
+}
+
+func TestNew_RequestLogEndpoint(t *testing.T) {
+	svc := newMockService(t)
+	svc.HTTPHandlers = append(svc.HTTPHandlers, &route.Handler{
+		Method:  http.MethodGet,
+		Path:    "/items/:id",
+		Handler: []gin.HandlerFunc{func(c *gin.Context) { c.String(http.StatusOK, c.Param("id")) }},
+	})
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	assert.Nil(t, h.RequestLog, "retention is disabled by default")
+
+	t.Setenv("REQUEST_LOG_BUFFER", "2")
+	t.Setenv("ADMIN_PORT", "0")
+	h, err = New(svc, "v1")
+	require.NoError(t, err)
+	require.NotNil(t, h.RequestLog)
+
+	for _, id := range []string{"a", "b", "c"} {
+		h.Engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/items/"+id, nil))
+	}
+
+	w := httptest.NewRecorder()
+	h.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugRequestsPath, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "debug endpoints stay off the public listener")
+
+	w = httptest.NewRecorder()
+	h.Admin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DebugRequestsPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Entries []RequestLogEntry `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Entries, 2)
+	assert.Equal(t, "/api/v1/items/b", body.Entries[0].Path)
+	assert.Equal(t, "c", body.Entries[1].ResponseBody)
+	assert.NotEmpty(t, body.Entries[1].RequestID)
+}
+
+func TestRequestLogMiddleware_RedactsSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := NewRequestLogBuffer(5)
+	r := gin.New()
+	r.Use(RequestLogMiddleware(buf, 0))
+	r.POST("/login", func(c *gin.Context) {
+		var body map[string]any
+		require.NoError(t, c.ShouldBindJSON(&body))
+		c.SetCookie("session", "s3ss10n", 60, "/", "", true, true)
+		c.JSON(http.StatusOK, gin.H{"user": "ada", "access_token": "eyJhbGci", "count": 12345678901234567})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/login?next=/home&api_key=k1", strings.NewReader(`{"user":"ada","password":"hunter2"}`))
+	req.Header.Set("Authorization", "Bearer abc123")
+	req.Header.Set("Cookie", "session=old")
+	req.Header.Set("Accept", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := buf.Entries()
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, "/login?next=/home&api_key=[REDACTED]", e.Path)
+	assert.Equal(t, "[REDACTED]", e.RequestHeaders.Get("Authorization"))
+	assert.Equal(t, "[REDACTED]", e.RequestHeaders.Get("Cookie"))
+	assert.Equal(t, "application/json", e.RequestHeaders.Get("Accept"))
+	assert.Equal(t, "[REDACTED]", e.ResponseHeaders.Get("Set-Cookie"))
+	assert.JSONEq(t, `{"user":"ada","password":"[REDACTED]"}`, e.RequestBody)
+	assert.JSONEq(t, `{"user":"ada","access_token":"[REDACTED]","count":12345678901234567}`, e.ResponseBody)
+	assert.Equal(t, "Bearer abc123", req.Header.Get("Authorization"), "the request itself is untouched")
+}
+
+func TestRedactBody_TruncatedJSON(t *testing.T) {
+	assert.Equal(t, `{"user":"ada","token":"[REDACTED]","bio":"lo`, redactBody(`{"user":"ada","token":"abc","bio":"lo`))
+	assert.Equal(t, "plain text", redactBody("plain text"))
+	assert.Equal(t, "", redactBody(""))
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// Paths answered when SERVE_ROBOTS_TXT=true and SERVE_FAVICON=true respectively.
+const (
+	RobotsPath  = "/robots.txt"
+	FaviconPath = "/favicon.ico"
+)
+
+// DisallowAllRobots asks every crawler to stay away, which suits API-only services.
+const DisallowAllRobots = "User-agent: *\nDisallow: /\n"
+
+// RobotsHandler serves body as robots.txt.
+func RobotsHandler(body string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.String(http.StatusOK, body)
+	}
+}
+
+// FaviconHandler serves icon, or 204 No Content when icon is empty, so browser
+// and probe favicon requests stop showing up as 404s.
+func FaviconHandler(icon []byte) gin.HandlerFunc {
+	contentType := ""
+	if len(icon) > 0 {
+		contentType = http.DetectContentType(icon)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=86400")
+		if len(icon) == 0 {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.Data(http.StatusOK, contentType, icon)
+	}
+}
+
+// robotsEnabled reports whether robots.txt should be served.
+func robotsEnabled() bool {
+	return os.Getenv("SERVE_ROBOTS_TXT") == "true"
+}
+
+// faviconEnabled reports whether the favicon should be served.
+func faviconEnabled() bool {
+	return os.Getenv("SERVE_FAVICON") == "true"
+}
+
+// favicon reads the icon from FAVICON_PATH. An unset or unreadable path yields
+// no icon, answered with 204.
+func favicon(svc *service.Service) []byte {
+	path := os.Getenv("FAVICON_PATH")
+	if path == "" {
+		return nil
+	}
+
+	icon, err := os.ReadFile(path)
+	if err != nil {
+		svc.Logger.Warn("unable to read FAVICON_PATH %q, serving 204: %v", path, err)
+		return nil
+	}
+	return icon
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getPath(t *testing.T, h *HTTPService, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestNew_ProbeEndpointsDisabledByDefault(t *testing.T) {
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, getPath(t, h, RobotsPath).Code)
+	assert.Equal(t, http.StatusNotFound, getPath(t, h, FaviconPath).Code)
+}
+
+func TestNew_RobotsTxt(t *testing.T) {
+	t.Setenv("SERVE_ROBOTS_TXT", "true")
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	rec := getPath(t, h, RobotsPath)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, DisallowAllRobots, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestNew_FaviconNoContent(t *testing.T) {
+	t.Setenv("SERVE_FAVICON", "true")
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	rec := getPath(t, h, FaviconPath)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+}
+
+func TestNew_FaviconFromFile(t *testing.T) {
+	icon := []byte("\x00\x00\x01\x00\x01\x00fake-icon")
+	path := filepath.Join(t.TempDir(), "favicon.ico")
+	require.NoError(t, os.WriteFile(path, icon, 0o600))
+	t.Setenv("SERVE_FAVICON", "true")
+	t.Setenv("FAVICON_PATH", path)
+
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	rec := getPath(t, h, FaviconPath)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, icon, rec.Body.Bytes())
+	assert.Equal(t, "image/x-icon", rec.Header().Get("Content-Type"))
+}
+
+func TestFavicon_UnreadablePath(t *testing.T) {
+	t.Setenv("FAVICON_PATH", filepath.Join(t.TempDir(), "missing.ico"))
+	assert.Nil(t, favicon(newMockService(t)))
+}
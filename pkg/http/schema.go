@@ -0,0 +1,52 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/jsonschema"
+)
+
+// ErrSchemaViolation is reported when a request body does not match its route's schema.
+var ErrSchemaViolation = errors.New("request body does not match schema")
+
+// SchemaMiddleware validates the JSON request body against schema before the
+// handler runs. Invalid bodies are rejected with 400 and the usual error shape,
+// plus a "violations" list of {path, message} with JSON Pointer paths. The body
+// is restored afterwards so handlers can bind it as usual. New installs it for
+// any route.Handler with Schema set.
+func SchemaMiddleware(schema *jsonschema.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			var err error
+			if body, err = io.ReadAll(c.Request.Body); err != nil {
+				abortWithError(c, err, "unable to read request body", http.StatusBadRequest)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		var violations []jsonschema.Violation
+		if len(bytes.TrimSpace(body)) == 0 {
+			violations = []jsonschema.Violation{{Path: "", Message: "request body is required"}}
+		} else {
+			violations = schema.ValidateJSON(body)
+		}
+		if len(violations) == 0 {
+			c.Next()
+			return
+		}
+
+		LoggerFromContext(c).Warn("%v: %d violation(s)", ErrSchemaViolation, len(violations))
+		abortWithJSON(c, http.StatusBadRequest, gin.H{
+			"error":      ErrSchemaViolation.Error(),
+			"details":    fmt.Sprintf("%d schema violation(s)", len(violations)),
+			"violations": violations,
+		})
+	}
+}
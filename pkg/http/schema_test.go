@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/jsonschema"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var widgetSchema = jsonschema.MustCompile(`{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"size": {"type": "integer", "minimum": 1}
+	}
+}`)
+
+func newSchemaService(t *testing.T) *HTTPService {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{
+		Method: http.MethodPost,
+		Path:   "/widgets",
+		Schema: widgetSchema,
+		Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			var body map[string]any
+			require.NoError(t, c.ShouldBindJSON(&body))
+			c.JSON(http.StatusCreated, body)
+		}},
+	}}
+
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+	return h
+}
+
+func postWidget(h *HTTPService, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/widgets", strings.NewReader(body)))
+	return rec
+}
+
+func TestSchemaMiddleware_AcceptsValidBody(t *testing.T) {
+	rec := postWidget(newSchemaService(t), `{"name":"gear","size":3}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"name":"gear","size":3}`, rec.Body.String())
+}
+
+func TestSchemaMiddleware_RejectsInvalidBody(t *testing.T) {
+	rec := postWidget(newSchemaService(t), `{"size":0}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp struct {
+		Error      string                 `json:"error"`
+		Details    string                 `json:"details"`
+		Violations []jsonschema.Violation `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, ErrSchemaViolation.Error(), resp.Error)
+	assert.Equal(t, "2 schema violation(s)", resp.Details)
+	assert.Equal(t, []jsonschema.Violation{
+		{Path: "/name", Message: "is required"},
+		{Path: "/size", Message: "must be >= 1"},
+	}, resp.Violations)
+}
+
+func TestSchemaMiddleware_RejectsMissingOrMalformedBody(t *testing.T) {
+	h := newSchemaService(t)
+	assert.Equal(t, http.StatusBadRequest, postWidget(h, "").Code)
+
+	rec := postWidget(h, `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid JSON")
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignedURLEngine() *gin.Engine {
+	engine := gin.New()
+	engine.GET("/files/:name", SignedURLMiddleware(), func(c *gin.Context) { c.String(http.StatusOK, c.Param("name")) })
+	return engine
+}
+
+func serveSigned(engine *gin.Engine, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestSignedURL_Valid(t *testing.T) {
+	t.Setenv("SIGNED_URL_SECRET", "s3cret")
+	engine := newSignedURLEngine()
+
+	signed := SignURL("/files/report.pdf", time.Now().Add(time.Minute))
+	assert.Contains(t, signed, SignedURLExpiresParam+"=")
+	assert.Contains(t, signed, SignedURLSignatureParam+"=")
+
+	rec := serveSigned(engine, signed)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "report.pdf", rec.Body.String())
+
+	// Existing query parameters are kept and signed
+	rec = serveSigned(engine, SignURL("/files/report.pdf?inline=true", time.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSignedURL_Expired(t *testing.T) {
+	t.Setenv("SIGNED_URL_SECRET", "s3cret")
+	rec := serveSigned(newSignedURLEngine(), SignURL("/files/report.pdf", time.Now().Add(-time.Second)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrSignedURLExpired.Error())
+}
+
+func TestSignedURL_Tampered(t *testing.T) {
+	t.Setenv("SIGNED_URL_SECRET", "s3cret")
+	engine := newSignedURLEngine()
+	signed := SignURL("/files/report.pdf?inline=true", time.Now().Add(time.Minute))
+
+	cases := map[string]string{
+		"path":      strings.Replace(signed, "report.pdf", "secrets.pdf", 1),
+		"query":     strings.Replace(signed, "inline=true", "inline=false", 1),
+		"added":     signed + "&extra=1",
+		"expiry":    strings.Replace(signed, SignedURLExpiresParam+"=", SignedURLExpiresParam+"=9", 1),
+		"signature": signed[:len(signed)-2] + "xx",
+		"unsigned":  "/files/report.pdf",
+	}
+	for name, target := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := serveSigned(engine, target)
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Contains(t, rec.Body.String(), ErrSignedURLInvalid.Error())
+		})
+	}
+
+	// A different secret invalidates previously signed URLs
+	t.Setenv("SIGNED_URL_SECRET", "rotated")
+	assert.Equal(t, http.StatusForbidden, serveSigned(engine, signed).Code)
+}
+
+func TestSignedURL_Unconfigured(t *testing.T) {
+	t.Setenv("SIGNED_URL_SECRET", "")
+	rec := serveSigned(newSignedURLEngine(), SignURL("/files/report.pdf", time.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrSignedURLUnconfigured.Error())
+}
+
+func TestSignedURL_LogsRejectionsAsWarnings(t *testing.T) {
+	t.Setenv("SIGNED_URL_SECRET", "s3cret")
+	sink := &recordingSink{}
+	engine := gin.New()
+	engine.GET("/files/:name", withSink(sink), SignedURLMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serveSigned(engine, "/files/report.pdf")
+	serveSigned(engine, SignURL("/files/report.pdf", time.Now().Add(-time.Second)))
+	require.Len(t, sink.Lines(), 2)
+	for _, line := range sink.Lines() {
+		assert.True(t, strings.HasPrefix(line, "WARN "), line)
+	}
+}
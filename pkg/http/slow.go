@@ -0,0 +1,34 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SlowRequestKey is set on the gin context when a request was logged as slow,
+// so access logging can avoid reporting the same request twice.
+const SlowRequestKey = "slowRequestLogged"
+
+// SlowRequestMiddleware logs a warning for every request taking longer than threshold,
+// including the method, route template, status and duration.
+func SlowRequestMiddleware(threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		elapsed := time.Since(start)
+		if elapsed < threshold {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		c.Set(SlowRequestKey, true)
+		LoggerFromContext(c).Warn("slow request: %s %s status=%d duration=%s threshold=%s",
+			c.Request.Method, route, c.Writer.Status(), elapsed.Round(time.Millisecond), threshold)
+	}
+}
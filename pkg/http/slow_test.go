@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSlowEngine(sink *recordingSink) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withSink(sink), SlowRequestMiddleware(20*time.Millisecond))
+	r.GET("/slow/:id", func(c *gin.Context) {
+		time.Sleep(40 * time.Millisecond)
+		c.Status(http.StatusAccepted)
+	})
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestSlowRequestMiddleware_LogsSlowRequests(t *testing.T) {
+	sink := &recordingSink{}
+	r := newSlowEngine(sink)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow/1", nil))
+
+	lines := sink.Lines()
+	if assert.Len(t, lines, 1) {
+		assert.Contains(t, lines[0], "WARN ")
+		assert.Contains(t, lines[0], "slow request: GET /slow/:id status=202")
+		assert.Contains(t, lines[0], "threshold=20ms")
+	}
+}
+
+func TestSlowRequestMiddleware_IgnoresFastRequests(t *testing.T) {
+	sink := &recordingSink{}
+	r := newSlowEngine(sink)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Empty(t, sink.Lines())
+}
+
+func TestSlowRequestThreshold(t *testing.T) {
+	svc := newMockService(t)
+
+	t.Setenv("SLOW_REQUEST_MS", "")
+	assert.Equal(t, time.Duration(0), slowRequestThreshold(svc))
+
+	t.Setenv("SLOW_REQUEST_MS", "250")
+	assert.Equal(t, 250*time.Millisecond, slowRequestThreshold(svc))
+
+	t.Setenv("SLOW_REQUEST_MS", "slow")
+	assert.Equal(t, time.Duration(0), slowRequestThreshold(svc))
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// SSEErrorEvent is the event name sent when the upstream stream fails.
+const SSEErrorEvent = "error"
+
+// StreamReceiver is the receiving half of a gRPC server-stream, as implemented
+// by generated <Method>Client stream types.
+type StreamReceiver[T any] interface {
+	Recv() (T, error)
+}
+
+// StreamSSE forwards every message received from stream to the client as an SSE
+// event named event, encoding protobuf messages with protojson and anything else
+// as JSON. It returns nil when the stream ends cleanly and the request context
+// error when the HTTP client goes away or the stream is cancelled. Any other
+// stream error is sent as a final SSEErrorEvent with its gRPC code and message
+// before being returned. A client disconnect is logged at debug level and ends the
+// stream with context.Canceled; other write failures are logged as errors.
+//
+// Pass the request context (c.Request.Context()) to the gRPC call so a disconnect
+// cancels the upstream stream.
+func StreamSSE[T any](c *gin.Context, event string, stream StreamReceiver[T]) error {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := c.Request.Context().Err(); ctxErr != nil {
+				LoggerFromContext(c).Debug("SSE client disconnected: %v", ctxErr)
+				return ctxErr
+			}
+			st := status.Convert(err)
+			if st.Code() == codes.Canceled {
+				return context.Canceled
+			}
+			_ = writeSSE(c, SSEErrorEvent, gin.H{"code": st.Code().String(), "message": st.Message()})
+			return err
+		}
+
+		if err := writeSSE(c, event, msg); err != nil {
+			if clientGone(c, err) {
+				LoggerFromContext(c).Debug("SSE client disconnected: %v", err)
+				return context.Canceled
+			}
+			LoggerFromContext(c).Error("failed to write SSE event: %v", err)
+			return err
+		}
+	}
+}
+
+// sseFieldReplacer keeps event names on a single line, as gin's SSE encoder does.
+var sseFieldReplacer = strings.NewReplacer("\n", "\\n", "\r", "\\r")
+
+func writeSSE(c *gin.Context, event string, msg any) error {
+	var (
+		data []byte
+		err  error
+	)
+	if m, ok := msg.(proto.Message); ok {
+		data, err = protojson.Marshal(m)
+	} else {
+		data, err = json.Marshal(msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Write the frame in one call: gin's SSE encoder drops write errors, which is how
+	// a client going away surfaces. JSON data never contains raw newlines.
+	if _, err := c.Writer.WriteString("event:" + sseFieldReplacer.Replace(event) + "\ndata:" + string(data) + "\n\n"); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
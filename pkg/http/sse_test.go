@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type fakeStream[T any] struct {
+	msgs []T
+	err  error
+}
+
+func (f *fakeStream[T]) Recv() (T, error) {
+	var zero T
+	if len(f.msgs) == 0 {
+		if f.err != nil {
+			return zero, f.err
+		}
+		return zero, io.EOF
+	}
+	msg := f.msgs[0]
+	f.msgs = f.msgs[1:]
+	return msg, nil
+}
+
+func serveSSE[T any](t *testing.T, ctx context.Context, stream StreamReceiver[T]) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	err := StreamSSE(c, "update", stream)
+	return w, err
+}
+
+func TestStreamSSE_ForwardsProtoMessages(t *testing.T) {
+	stream := &fakeStream[*wrapperspb.StringValue]{msgs: []*wrapperspb.StringValue{
+		wrapperspb.String("one"), wrapperspb.String("two"),
+	}}
+
+	w, err := serveSSE[*wrapperspb.StringValue](t, context.Background(), stream)
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "event:update\ndata:\"one\"\n\nevent:update\ndata:\"two\"\n\n", w.Body.String())
+}
+
+func TestStreamSSE_ForwardsPlainValues(t *testing.T) {
+	stream := &fakeStream[map[string]int]{msgs: []map[string]int{{"n": 1}}}
+
+	w, err := serveSSE[map[string]int](t, context.Background(), stream)
+	require.NoError(t, err)
+	assert.Equal(t, "event:update\ndata:{\"n\":1}\n\n", w.Body.String())
+}
+
+func TestStreamSSE_StreamErrorSendsErrorEvent(t *testing.T) {
+	upstream := status.Error(codes.Unavailable, "backend down")
+	stream := &fakeStream[*wrapperspb.StringValue]{msgs: []*wrapperspb.StringValue{wrapperspb.String("one")}, err: upstream}
+
+	w, err := serveSSE[*wrapperspb.StringValue](t, context.Background(), stream)
+	assert.Equal(t, upstream, err)
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "event:update\ndata:\"one\"\n\n"))
+	assert.Contains(t, body, "event:error\n")
+	assert.Contains(t, body, `"code":"Unavailable"`)
+	assert.Contains(t, body, `"message":"backend down"`)
+}
+
+func TestStreamSSE_CancellationEndsSilently(t *testing.T) {
+	stream := &fakeStream[*wrapperspb.StringValue]{err: status.Error(codes.Canceled, "context canceled")}
+	w, err := serveSSE[*wrapperspb.StringValue](t, context.Background(), stream)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotContains(t, w.Body.String(), "event:error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream = &fakeStream[*wrapperspb.StringValue]{err: errors.New("transport closed")}
+	w, err = serveSSE[*wrapperspb.StringValue](t, ctx, stream)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, w.Body.String())
+}
+
+// errBrokenPipe is what writing to a connection the client closed returns.
+var errBrokenPipe = &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+
+// failingWriter accepts limit bytes, then fails every write with err.
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	limit int
+	err   error
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if w.Body.Len()+len(b) > w.limit {
+		return 0, w.err
+	}
+	return w.ResponseRecorder.Write(b)
+}
+
+func (w *failingWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+// newFailingContext returns a context writing to a failingWriter and logging to sink.
+func newFailingContext(limit int, err error, sink *recordingSink) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(&failingWriter{ResponseRecorder: httptest.NewRecorder(), limit: limit, err: err})
+	ctx := logging.NewContext(context.Background(), logging.New(sink))
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	return c
+}
+
+func TestStreamSSE_ClientDisconnect(t *testing.T) {
+	msgs := make([]*wrapperspb.StringValue, 5)
+	for i := range msgs {
+		msgs[i] = wrapperspb.String("msg")
+	}
+	stream := &fakeStream[*wrapperspb.StringValue]{msgs: msgs}
+	sink := &recordingSink{}
+
+	// The client hangs up after the first event
+	c := newFailingContext(len("event:update\ndata:\"msg\"\n\n"), errBrokenPipe, sink)
+	err := StreamSSE(c, "update", stream)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, stream.msgs, 3, "the stream stops at the first failed write")
+	lines := sink.Lines()
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "DEBUG SSE client disconnected: write tcp: write: broken pipe")
+}
+
+func TestStreamSSE_WriteError(t *testing.T) {
+	stream := &fakeStream[*wrapperspb.StringValue]{msgs: []*wrapperspb.StringValue{wrapperspb.String("one")}}
+	sink := &recordingSink{}
+	writeErr := errors.New("disk quota exceeded")
+
+	err := StreamSSE(newFailingContext(0, writeErr, sink), "update", stream)
+	assert.ErrorIs(t, err, writeErr)
+	lines := sink.Lines()
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "ERROR failed to write SSE event: disk quota exceeded")
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamingKey is set on the gin context for routes declared with route.Handler.Streaming.
+const StreamingKey = "streamingRoute"
+
+// Streaming body errors returned while reading a StreamBody reader.
+var (
+	ErrBodyTooLarge = errors.New("request body exceeds limit")
+	ErrBodyTimeout  = errors.New("request body was not received in time")
+)
+
+// clientGone reports whether err, returned while writing a streamed response,
+// means the client went away rather than the write genuinely failing.
+func clientGone(c *gin.Context, err error) bool {
+	return c.Request.Context().Err() != nil ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, net.ErrClosed)
+}
+
+// streamingMiddleware marks the request as streaming, so body-capturing middleware
+// such as the request log leave the body alone.
+func streamingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(StreamingKey, true)
+		c.Next()
+	}
+}
+
+// StreamBody exposes the request body for incremental processing, e.g. a CSV import
+// fed to csv.NewReader, without buffering it. Reads fail with ErrBodyTooLarge once
+// more than maxBytes arrive, and with ErrBodyTimeout once timeout has elapsed; zero
+// disables either limit. The deadline also interrupts blocked reads where the
+// underlying connection supports it. The body is read after any decompression, so
+// maxBytes bounds the decompressed size.
+func StreamBody(c *gin.Context, maxBytes int64, timeout time.Duration) io.Reader {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return http.NoBody
+	}
+
+	s := &streamReader{r: c.Request.Body, max: maxBytes}
+	if timeout > 0 {
+		s.deadline = time.Now().Add(timeout)
+		_ = http.NewResponseController(c.Writer).SetReadDeadline(s.deadline)
+	}
+	return s
+}
+
+// streamReader enforces StreamBody's size and time limits.
+type streamReader struct {
+	r        io.Reader
+	max      int64
+	n        int64
+	deadline time.Time
+	err      error
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if !s.deadline.IsZero() && !time.Now().Before(s.deadline) {
+		s.err = ErrBodyTimeout
+		return 0, s.err
+	}
+
+	// Read one byte past the limit, so a body of exactly max bytes is accepted
+	if s.max > 0 && int64(len(p)) > s.max-s.n+1 {
+		p = p[:s.max-s.n+1]
+	}
+	n, err := s.r.Read(p)
+	s.n += int64(n)
+
+	if s.max > 0 && s.n > s.max {
+		s.err = fmt.Errorf("%w of %d bytes", ErrBodyTooLarge, s.max)
+		return n - int(s.n-s.max), s.err
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		s.err = fmt.Errorf("%w: %w", ErrBodyTimeout, err)
+		return n, s.err
+	}
+	return n, err
+}
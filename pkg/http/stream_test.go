@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/jsonschema"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countBody streams the request body through StreamBody and reports its size.
+func countBody(maxBytes int64, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n, err := io.CopyBuffer(io.Discard, StreamBody(c, maxBytes, timeout), make([]byte, 32<<10))
+		switch {
+		case errors.Is(err, ErrBodyTooLarge):
+			c.String(http.StatusRequestEntityTooLarge, "%d", n)
+		case errors.Is(err, ErrBodyTimeout):
+			c.String(http.StatusRequestTimeout, "%d", n)
+		case err != nil:
+			c.String(http.StatusBadRequest, "%v", err)
+		default:
+			c.String(http.StatusOK, "%d", n)
+		}
+	}
+}
+
+func TestStreamBody_ProcessesLargeBodyIncrementally(t *testing.T) {
+	t.Setenv("REQUEST_LOG_BUFFER", "5")
+	svc := newMockService(t)
+
+	// The handler sees the first chunk while the client is still blocked writing the rest
+	firstChunk := make(chan struct{})
+	svc.HTTPHandlers = []*route.Handler{{
+		Method:    http.MethodPost,
+		Path:      "/import",
+		Streaming: true,
+		Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			body := StreamBody(c, 0, 0)
+			chunk := make([]byte, 1024)
+			_, err := io.ReadFull(body, chunk)
+			require.NoError(t, err)
+			close(firstChunk)
+
+			n, err := io.Copy(io.Discard, body)
+			require.NoError(t, err)
+			c.String(http.StatusOK, "%d", n+int64(len(chunk)))
+		}},
+	}}
+	h, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	const size = 64 << 20
+	pr, pw := io.Pipe()
+	go func() {
+		chunk := bytes.Repeat([]byte("a,b,c\n"), 1<<10)
+		written := 0
+		for written < size {
+			n, _ := pw.Write(chunk[:min(len(chunk), size-written)])
+			written += n
+			if written == len(chunk) {
+				<-firstChunk
+			}
+		}
+		pw.Close()
+	}()
+
+	rec := httptest.NewRecorder()
+	h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/import", pr))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "67108864", rec.Body.String())
+
+	// The request log keeps the response but not the streamed body
+	entries := h.RequestLog.Entries()
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0].RequestBody)
+	assert.False(t, entries[0].Truncated)
+	assert.Equal(t, "67108864", entries[0].ResponseBody)
+}
+
+func TestStreamBody_MaxBytes(t *testing.T) {
+	r := gin.New()
+	r.POST("/import", countBody(1024, 0))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(strings.Repeat("x", 1024))))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1024", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(strings.Repeat("x", 1<<20))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, "1024", rec.Body.String())
+}
+
+func TestStreamBody_Timeout(t *testing.T) {
+	r := gin.New()
+	r.POST("/import", countBody(0, 100*time.Millisecond))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	// Send part of the body, then stall
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, "POST /import HTTP/1.1\r\nHost: test\r\nContent-Length: 1000\r\n\r\n0123456789")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode)
+}
+
+func TestStreamBody_NoBody(t *testing.T) {
+	r := gin.New()
+	r.POST("/import", countBody(10, time.Second))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/import", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Body.String())
+}
+
+func TestNew_RejectsStreamingRouteWithSchema(t *testing.T) {
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{
+		Method:    http.MethodPost,
+		Path:      "/import",
+		Streaming: true,
+		Schema:    jsonschema.MustCompile(`{"type":"object"}`),
+	}}
+
+	h, err := New(svc, "v1")
+	assert.Nil(t, h)
+	assert.EqualError(t, err, "invalid route /import: streaming routes cannot validate a schema")
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHeader carries the tenant of a request in multi-tenant mode.
+const TenantHeader = "X-Tenant-ID"
+
+// TenantKey is the gin context key holding the tenant resolved by TenantMiddleware.
+const TenantKey = "tenantID"
+
+// maxTenantLength bounds the tenant identifiers accepted.
+const maxTenantLength = 128
+
+// Tenant errors reported by TenantMiddleware.
+var (
+	ErrMissingTenant  = errors.New("missing " + TenantHeader + " header")
+	ErrInvalidTenant  = errors.New("tenant identifier is too long")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrTenantMismatch = errors.New("tenant does not match the authenticated token")
+)
+
+// TenantOptions configures TenantMiddleware. With neither Allowed nor Lookup set,
+// any tenant is accepted.
+type TenantOptions struct {
+	// Allowed lists the accepted tenants.
+	Allowed []string
+	// Lookup reports whether a tenant exists, e.g. by querying a tenants table.
+	// It is consulted for tenants not in Allowed.
+	Lookup func(ctx context.Context, tenant string) (bool, error)
+	// FromToken returns the tenant of the authenticated token, such as
+	// firebase.TokenTenant. Requests without the header use it, and requests whose
+	// header names another tenant are rejected.
+	FromToken func(c *gin.Context) (string, bool)
+}
+
+// multiTenantEnabled reports whether MULTI_TENANT=true asks New to require a tenant
+// on every request.
+func multiTenantEnabled() bool {
+	return os.Getenv("MULTI_TENANT") == "true"
+}
+
+// tenantOptionsFromEnv reads the comma-separated tenant allow-list from TENANT_IDS.
+func tenantOptionsFromEnv() TenantOptions {
+	var opts TenantOptions
+	for _, tenant := range strings.Split(os.Getenv("TENANT_IDS"), ",") {
+		if tenant = strings.TrimSpace(tenant); tenant != "" {
+			opts.Allowed = append(opts.Allowed, tenant)
+		}
+	}
+	return opts
+}
+
+// TenantMiddleware requires every request to name its tenant through TenantHeader
+// or its token, and stores it for TenantFromContext. A missing tenant is answered
+// with 400, an unknown or mismatched one with 403, and a failed lookup with 503.
+// Probes, metrics scrapes and debug endpoints are exempt. Install it after the
+// authentication middleware when FromToken is set.
+func TenantMiddleware(opts TenantOptions) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(opts.Allowed))
+	for _, tenant := range opts.Allowed {
+		allowed[tenant] = true
+	}
+
+	return func(c *gin.Context) {
+		if tenantExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		tenant := c.GetHeader(TenantHeader)
+		if opts.FromToken != nil {
+			if fromToken, ok := opts.FromToken(c); ok && fromToken != "" {
+				if tenant != "" && tenant != fromToken {
+					abortWithWarning(c, ErrTenantMismatch, "tenant rejected", http.StatusForbidden)
+					return
+				}
+				tenant = fromToken
+			}
+		}
+
+		if tenant == "" {
+			abortWithWarning(c, ErrMissingTenant, "a tenant is required", http.StatusBadRequest)
+			return
+		}
+		if len(tenant) > maxTenantLength {
+			abortWithWarning(c, ErrInvalidTenant, "tenant identifiers are limited to 128 characters", http.StatusBadRequest)
+			return
+		}
+
+		known := (len(allowed) == 0 && opts.Lookup == nil) || allowed[tenant]
+		if !known && opts.Lookup != nil {
+			var err error
+			if known, err = opts.Lookup(c.Request.Context(), tenant); err != nil {
+				abortWithError(c, err, "unable to verify tenant", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		if !known {
+			abortWithWarning(c, ErrUnknownTenant, "tenant rejected", http.StatusForbidden)
+			return
+		}
+
+		c.Set(TenantKey, tenant)
+		c.Next()
+	}
+}
+
+// tenantExempt reports whether path is a service endpoint rather than tenant data:
+// health and readiness probes, metrics, robots and favicon probes, and debug endpoints.
+func tenantExempt(path string) bool {
+	switch path {
+	case "/healthz", ReadyzPath, MetricsPath, RobotsPath, FaviconPath:
+		return true
+	}
+	return strings.HasPrefix(path, "/debug/")
+}
+
+// TenantFromContext returns the tenant resolved by TenantMiddleware, or "" when it did not run.
+func TenantFromContext(c *gin.Context) string {
+	return c.GetString(TenantKey)
+}
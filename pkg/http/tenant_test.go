@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lookup := func(_ context.Context, tenant string) (bool, error) {
+		if tenant == "flaky" {
+			return false, errors.New("tenants table unavailable")
+		}
+		return tenant == "globex", nil
+	}
+	fromToken := func(c *gin.Context) (string, bool) {
+		tenant := c.GetHeader("X-Test-Token-Tenant")
+		return tenant, tenant != ""
+	}
+
+	r := gin.New()
+	r.Use(TenantMiddleware(TenantOptions{Allowed: []string{"acme"}, Lookup: lookup, FromToken: fromToken}))
+	r.GET("/orders", func(c *gin.Context) { c.String(http.StatusOK, TenantFromContext(c)) })
+
+	tests := []struct {
+		name        string
+		header      string
+		tokenTenant string
+		code        int
+		want        string
+	}{
+		{"allow-listed header", "acme", "", http.StatusOK, "acme"},
+		{"looked up header", "globex", "", http.StatusOK, "globex"},
+		{"derived from token", "", "acme", http.StatusOK, "acme"},
+		{"header matching token", "acme", "acme", http.StatusOK, "acme"},
+		{"missing", "", "", http.StatusBadRequest, "missing X-Tenant-ID header"},
+		{"too long", strings.Repeat("a", 129), "", http.StatusBadRequest, "tenant identifier is too long"},
+		{"unknown", "initech", "", http.StatusForbidden, "unknown tenant"},
+		{"header contradicting token", "globex", "acme", http.StatusForbidden, "tenant does not match the authenticated token"},
+		{"lookup failure", "flaky", "", http.StatusServiceUnavailable, "tenants table unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			if tt.tokenTenant != "" {
+				req.Header.Set("X-Test-Token-Tenant", tt.tokenTenant)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestTenantFromContext_WithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Empty(t, TenantFromContext(c))
+}
+
+func TestNew_MultiTenantMode(t *testing.T) {
+	t.Setenv("MULTI_TENANT", "true")
+	t.Setenv("TENANT_IDS", "acme, globex")
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	serve := func(tenant string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/anything", nil)
+		if tenant != "" {
+			req.Header.Set(TenantHeader, tenant)
+		}
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve("globex"))
+	assert.Equal(t, http.StatusBadRequest, serve(""))
+	assert.Equal(t, http.StatusForbidden, serve("initech"))
+}
+
+func TestNew_MultiTenantModeExemptsProbes(t *testing.T) {
+	t.Setenv("MULTI_TENANT", "true")
+	t.Setenv("READYZ_ENABLED", "true")
+	t.Setenv("METRICS_ENABLED", "true")
+	h, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	for _, path := range []string{ReadyzPath, MetricsPath} {
+		rec := httptest.NewRecorder()
+		h.Engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NotEqual(t, http.StatusBadRequest, rec.Code, path)
+		assert.NotContains(t, rec.Body.String(), ErrMissingTenant.Error(), path)
+	}
+}
+
+func TestTenantMiddleware_LogsRejectionsAsWarnings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &recordingSink{}
+	r := gin.New()
+	r.Use(withSink(sink), TenantMiddleware(TenantOptions{}))
+	r.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, sink.Lines(), 1)
+	assert.True(t, strings.HasPrefix(sink.Lines()[0], "WARN "), sink.Lines()[0])
+}
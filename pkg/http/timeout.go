@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrRequestTimeout is the error returned to clients whose request exceeded its timeout.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// TimeoutMiddleware bounds the rest of the chain to d. The request context carries
+// the deadline, and once it passes the client receives a 503 error, enveloped like
+// the package's other errors, immediately while the handler's output is discarded. The middleware still waits for the handler to
+// return before releasing the request, so handlers must honor ctx cancellation to
+// free their resources promptly. Responses are buffered until the handler returns,
+// which makes it unsuitable for streaming routes.
+func TimeoutMiddleware(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		orig := c.Writer
+		tw := &timeoutWriter{ResponseWriter: orig, ctx: ctx, header: http.Header{}, size: -1}
+		c.Writer = tw
+		c.Request = c.Request.WithContext(ctx)
+
+		done := make(chan interface{}, 1)
+		go func() {
+			// Hand panics back to this goroutine so recovery middleware sees them
+			defer func() { done <- recover() }()
+			c.Next()
+		}()
+
+		var p interface{}
+		finished := false
+		select {
+		case p = <-done:
+			finished = true
+		case <-ctx.Done():
+		}
+
+		timedOut := tw.timeOut(errors.Is(ctx.Err(), context.DeadlineExceeded))
+		if timedOut {
+			// The handler may still be running on c, so answer through a copy
+			cp := c.Copy()
+			cp.Writer = orig
+			abortWithError(cp, ErrRequestTimeout, fmt.Sprintf("request exceeded its %s timeout", d), http.StatusServiceUnavailable)
+			orig.Flush()
+		}
+
+		if !finished {
+			p = <-done
+		}
+		c.Writer = orig
+		if p != nil {
+			panic(p)
+		}
+		if timedOut {
+			c.Abort()
+			return
+		}
+		tw.flushTo(orig)
+	}
+}
+
+// timeoutWriter buffers a handler's response until it completes in time, and
+// rejects writes once ctx's deadline passes, which aborts gin's handler chain.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	size     int
+	timedOut bool
+}
+
+// timeOut marks the response as timed out when deadline is true, reporting whether
+// it is, including when a write was already rejected.
+func (w *timeoutWriter) timeOut(deadline bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = w.timedOut || deadline
+	return w.timedOut
+}
+
+// flushTo copies the buffered response to dst.
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for k, v := range w.header {
+		dst.Header()[k] = v
+	}
+	if w.status != 0 {
+		dst.WriteHeader(w.status)
+	}
+	if w.body.Len() > 0 {
+		_, _ = dst.Write(w.body.Bytes())
+	} else if w.status != 0 {
+		dst.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header { return w.header }
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size == -1 {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size == -1 {
+		w.size = 0
+	}
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.size == -1 {
+		w.size = 0
+	}
+	w.size += len(b)
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+func (w *timeoutWriter) Written() bool { return w.Size() != -1 }
+
+// Flush is a no-op: the response is only sent once the handler completes.
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("timeout middleware does not support hijacking")
+}
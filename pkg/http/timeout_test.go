@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTimeoutEngine(d time.Duration, handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	r.GET("/work", append([]gin.HandlerFunc{TimeoutMiddleware(d)}, handlers...)...)
+	return r
+}
+
+func TestTimeoutMiddleware_Fast(t *testing.T) {
+	r := newTimeoutEngine(time.Second, func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		c.Header("X-Has-Deadline", map[bool]string{true: "yes", false: "no"}[ok])
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+	assert.Equal(t, "yes", w.Header().Get("X-Has-Deadline"))
+}
+
+func TestTimeoutMiddleware_Slow(t *testing.T) {
+	var finished, after atomic.Bool
+	r := newTimeoutEngine(20*time.Millisecond, func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		c.JSON(http.StatusOK, gin.H{"late": true})
+		finished.Store(true)
+	}, func(c *gin.Context) { after.Store(true) })
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+
+	assert.Less(t, time.Since(start), time.Second, "the deadline cancels the handler")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error":"request timed out","details":"request exceeded its 20ms timeout"}`, w.Body.String())
+	assert.True(t, finished.Load(), "the handler has returned once the request is released")
+	assert.False(t, after.Load(), "writes after the deadline abort the chain")
+}
+
+func TestTimeoutMiddleware_SlowEnveloped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ResponseEnvelopeMiddleware())
+	r.GET("/work", TimeoutMiddleware(20*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var body struct {
+		Error map[string]string `json:"error"`
+		Meta  *Meta             `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "request timed out", body.Error["message"])
+	assert.NotNil(t, body.Meta)
+}
+
+func TestTimeoutMiddleware_Panic(t *testing.T) {
+	r := newTimeoutEngine(time.Second, func(*gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	require.NotPanics(t, func() { r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil)) })
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameter names read by ParseTimeRange.
+const (
+	TimeRangeFromParam = "from"
+	TimeRangeToParam   = "to"
+)
+
+// defaultTimeRangeWindow is used for a missing `from` when no maxWindow is set.
+const defaultTimeRangeWindow = 24 * time.Hour
+
+// ParseTimeRange parses the RFC3339 `from` and `to` query parameters. A missing `to`
+// defaults to now and a missing `from` defaults to maxWindow (or 24h) before `to`.
+// The range must satisfy from <= to and, when maxWindow > 0, span at most maxWindow.
+// On violation a 400 is written, the request is aborted and ok is false.
+func ParseTimeRange(c *gin.Context, maxWindow time.Duration) (from, to time.Time, ok bool) {
+	to = time.Now().UTC()
+	if raw := c.Query(TimeRangeToParam); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			abortWithWarning(c, fmt.Errorf("%q must be an RFC3339 timestamp", TimeRangeToParam), "invalid time range", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+
+	window := maxWindow
+	if window <= 0 {
+		window = defaultTimeRangeWindow
+	}
+	from = to.Add(-window)
+	if raw := c.Query(TimeRangeFromParam); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			abortWithWarning(c, fmt.Errorf("%q must be an RFC3339 timestamp", TimeRangeFromParam), "invalid time range", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		abortWithWarning(c, fmt.Errorf("%q must not be after %q", TimeRangeFromParam, TimeRangeToParam), "invalid time range", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	if maxWindow > 0 && to.Sub(from) > maxWindow {
+		abortWithWarning(c, fmt.Errorf("time range exceeds maximum window of %s", maxWindow), "invalid time range", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
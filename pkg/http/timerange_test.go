@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseRange(t *testing.T, query string, maxWindow time.Duration) (*httptest.ResponseRecorder, time.Time, time.Time, bool) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/reports?"+query, nil)
+	from, to, ok := ParseTimeRange(c, maxWindow)
+	return w, from, to, ok
+}
+
+func TestParseTimeRange_Valid(t *testing.T) {
+	w, from, to, ok := parseRange(t, "from=2025-01-01T00:00:00Z&to=2025-01-02T12:00:00Z", 7*24*time.Hour)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), to)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestParseTimeRange_Defaults(t *testing.T) {
+	_, from, to, ok := parseRange(t, "", time.Hour)
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now(), to, time.Minute)
+	assert.Equal(t, time.Hour, to.Sub(from))
+
+	_, from, to, ok = parseRange(t, "to=2025-01-02T00:00:00Z", 0)
+	require.True(t, ok)
+	assert.Equal(t, defaultTimeRangeWindow, to.Sub(from))
+}
+
+func TestParseTimeRange_Rejections(t *testing.T) {
+	cases := map[string]string{
+		"inverted":  "from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z",
+		"oversized": "from=2025-01-01T00:00:00Z&to=2025-03-01T00:00:00Z",
+		"bad from":  "from=yesterday&to=2025-01-01T00:00:00Z",
+		"bad to":    "to=2025-01-01",
+	}
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			w, _, _, ok := parseRange(t, query, 7*24*time.Hour)
+			assert.False(t, ok)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "invalid time range", body["details"])
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestParseTimeRange_LogsRejectionsAsWarnings(t *testing.T) {
+	sink := &recordingSink{}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/reports?from=yesterday", nil)
+	c.Request = req.WithContext(logging.NewContext(req.Context(), logging.New(sink)))
+
+	_, _, ok := ParseTimeRange(c, time.Hour)
+	require.False(t, ok)
+	require.Len(t, sink.Lines(), 1)
+	assert.True(t, strings.HasPrefix(sink.Lines()[0], "WARN "), sink.Lines()[0])
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/tracing"
+)
+
+// TracingMiddleware joins each request to the trace of its traceparent header, or
+// starts a new trace, sampled by sampler. Outbound calls made with the request
+// context, such as through Client, carry the trace and its sampling decision on.
+func TracingMiddleware(sampler *tracing.Sampler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, _ := sampler.Extract(c.Request.Context(), c.Request.Header)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
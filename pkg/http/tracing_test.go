@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_TracesRequests(t *testing.T) {
+	// The handler calls a downstream service, which sees the propagated trace
+	var downstream http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { downstream = r.Header.Clone() }))
+	defer srv.Close()
+	client := NewHTTPClient(ClientOptions{})
+
+	serve := func(t *testing.T, traceparent string) (trace.SpanContext, string) {
+		t.Helper()
+		var sc trace.SpanContext
+		svc := newMockService(t)
+		svc.HTTPHandlers = []*route.Handler{{Method: http.MethodGet, Path: "/orders", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+			sc = trace.SpanContextFromContext(c.Request.Context())
+			req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, srv.URL, nil)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			c.Status(http.StatusOK)
+		}}}}
+		h, err := New(svc, "v1")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		if traceparent != "" {
+			req.Header.Set("Traceparent", traceparent)
+		}
+		h.Engine.ServeHTTP(httptest.NewRecorder(), req)
+		return sc, downstream.Get("Traceparent")
+	}
+
+	t.Run("joins the caller's trace", func(t *testing.T) {
+		t.Setenv("TRACE_SAMPLE_RATE", "0")
+		sc, propagated := serve(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+		assert.True(t, sc.IsSampled(), "the caller's decision wins over the rate")
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", propagated)
+	})
+
+	t.Run("samples root traces by rate", func(t *testing.T) {
+		t.Setenv("TRACE_SAMPLE_RATE", "1")
+		sc, propagated := serve(t, "")
+		require.True(t, sc.IsValid())
+		assert.True(t, sc.IsSampled())
+		assert.Regexp(t, "^00-"+sc.TraceID().String()+"-[0-9a-f]{16}-01$", propagated)
+
+		t.Setenv("TRACE_SAMPLE_RATE", "0")
+		sc, propagated = serve(t, "")
+		require.True(t, sc.IsValid())
+		assert.False(t, sc.IsSampled())
+		assert.Regexp(t, "^00-"+sc.TraceID().String()+"-[0-9a-f]{16}-00$", propagated)
+	})
+}
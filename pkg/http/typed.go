@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+)
+
+// TypedFunc is a protocol-agnostic handler operating on decoded request values.
+// It returns the response, the HTTP status to send it with, and an optional error.
+type TypedFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, int, error)
+
+// TypedHandler adapts a TypedFunc into a gin handler. The request is bound from the
+// JSON body, query (`form` tags) and path (`uri` tags) and validated once via
+// `binding` tags before fn runs. Errors returned by fn with a 4xx status are sent
+// to the client as is; any other error is logged and answered with a generic
+// {"error":"internal error"}, with the status fn returned or 500.
+func TypedHandler[Req, Resp any](fn TypedFunc[Req, Resp]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Req
+		if err := BindAndValidate(c, &req); err != nil {
+			abortWithWarning(c, err, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		resp, code, err := fn(c.Request.Context(), req)
+		if err != nil {
+			if code >= http.StatusBadRequest && code < http.StatusInternalServerError {
+				abortWithWarning(c, err, "", code)
+				return
+			}
+			// Server errors may carry SQL or driver details, so only the log sees them
+			if code < http.StatusBadRequest {
+				code = http.StatusInternalServerError
+			}
+			LoggerFromContext(c).Error("%s", logging.RedactText(err.Error()))
+			abortWithJSON(c, code, gin.H{"error": "internal error"})
+			return
+		}
+
+		if code == 0 {
+			code = http.StatusOK
+		}
+		Respond(c, code, resp)
+	}
+}
+
+// ErrBindingConflict is returned by BindAndValidate when two parts of a request set
+// the same field to different values.
+var ErrBindingConflict = errors.New("conflicting request values")
+
+// BindAndValidate binds the JSON body, query parameters and path parameters of the
+// request into obj and validates the result. A field may be set by more than one of
+// them only when they agree; otherwise binding fails with ErrBindingConflict, so a
+// body cannot override the path a request was authorized for.
+func BindAndValidate(c *gin.Context, obj any) error {
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		dec := json.NewDecoder(c.Request.Body)
+		if binding.EnableDecoderUseNumber {
+			dec.UseNumber()
+		}
+		if err := dec.Decode(obj); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("invalid JSON body: %w", err)
+		}
+	}
+
+	bound := snapshot(obj)
+	if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+		return fmt.Errorf("invalid query parameters: %w", err)
+	}
+	if field, ok := conflict(bound, obj); ok {
+		return fmt.Errorf("%w: %s is set by both the body and the query", ErrBindingConflict, field)
+	}
+
+	if len(c.Params) > 0 {
+		bound = snapshot(obj)
+		params := make(map[string][]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+			return fmt.Errorf("invalid path parameters: %w", err)
+		}
+		if field, ok := conflict(bound, obj); ok {
+			return fmt.Errorf("%w: %s is set by both the path and the body or query", ErrBindingConflict, field)
+		}
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// snapshot returns a shallow copy of the struct obj points to, or an invalid value
+// when obj is not a pointer to a struct.
+func snapshot(obj any) reflect.Value {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	out := reflect.New(v.Elem().Type()).Elem()
+	out.Set(v.Elem())
+	return out
+}
+
+// conflict reports the first exported field that was already set in before and
+// holds a different value in the struct obj now points to.
+func conflict(before reflect.Value, obj any) (string, bool) {
+	if !before.IsValid() {
+		return "", false
+	}
+	after := reflect.ValueOf(obj).Elem()
+	t := before.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() || before.Field(i).IsZero() {
+			continue
+		}
+		if !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			return t.Field(i).Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type greetRequest struct {
+	ID    string `uri:"id" binding:"required"`
+	Name  string `json:"name" binding:"required"`
+	Shout bool   `form:"shout"`
+}
+
+type greetResponse struct {
+	Message string `json:"message"`
+}
+
+var errNotFound = errors.New("user not found")
+
+func greet(_ context.Context, req greetRequest) (greetResponse, int, error) {
+	if req.ID == "missing" {
+		return greetResponse{}, http.StatusNotFound, errNotFound
+	}
+
+	msg := "hello " + req.Name + " (" + req.ID + ")"
+	if req.Shout {
+		msg = strings.ToUpper(msg)
+	}
+	return greetResponse{Message: msg}, http.StatusCreated, nil
+}
+
+func newTypedEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/users/:id/greet", TypedHandler(greet))
+	return r
+}
+
+func TestTypedHandler_Success(t *testing.T) {
+	r := newTypedEngine()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42/greet?shout=true", strings.NewReader(`{"name":"ada"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"message":"HELLO ADA (42)"}`, rec.Body.String())
+}
+
+func TestTypedHandler_ValidationError(t *testing.T) {
+	r := newTypedEngine()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42/greet", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Name")
+	assert.Contains(t, rec.Body.String(), "invalid request")
+}
+
+func TestTypedHandler_MalformedBody(t *testing.T) {
+	r := newTypedEngine()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42/greet", strings.NewReader(`{"name":`))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid JSON body")
+}
+
+func TestTypedHandler_DomainError(t *testing.T) {
+	r := newTypedEngine()
+
+	req := httptest.NewRequest(http.MethodPost, "/users/missing/greet", strings.NewReader(`{"name":"ada"}`))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error":"user not found"}`, rec.Body.String())
+}
+
+func TestTypedHandler_ErrorWithoutStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/fail", TypedHandler(func(context.Context, struct{}) (any, int, error) {
+		return nil, 0, errors.New("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":"internal error"}`, rec.Body.String())
+}
+
+func TestTypedHandler_HidesServerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/fail", TypedHandler(func(context.Context, struct{}) (any, int, error) {
+		return nil, http.StatusServiceUnavailable, errors.New(`pq: relation "users" does not exist`)
+	}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"error":"internal error"}`, rec.Body.String())
+}
+
+func TestBindAndValidate_RejectsConflicts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	type filterRequest struct {
+		ID     string `uri:"id"`
+		Status string `json:"status" form:"status"`
+		Limit  int    `json:"limit" form:"limit"`
+	}
+	bind := func(target, body string, params gin.Params) (filterRequest, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		c.Params = params
+		var req filterRequest
+		return req, BindAndValidate(c, &req)
+	}
+
+	// Each part fills the fields the others leave unset
+	req, err := bind("/?limit=5", `{"status":"open"}`, gin.Params{{Key: "id", Value: "42"}})
+	assert.NoError(t, err)
+	assert.Equal(t, filterRequest{ID: "42", Status: "open", Limit: 5}, req)
+
+	// Agreeing values are not a conflict
+	_, err = bind("/?status=open", `{"status":"open"}`, nil)
+	assert.NoError(t, err)
+
+	_, err = bind("/?status=closed", `{"status":"open"}`, nil)
+	assert.ErrorIs(t, err, ErrBindingConflict)
+	assert.ErrorContains(t, err, "Status")
+
+	_, err = bind("/", `{"ID":"7"}`, gin.Params{{Key: "id", Value: "42"}})
+	assert.ErrorIs(t, err, ErrBindingConflict, "the body cannot override the path")
+}
+
+func TestTypedHandler_ConflictingBodyAndPath(t *testing.T) {
+	r := newTypedEngine()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users/42/greet", strings.NewReader(`{"name":"Ada","ID":"7"}`)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "conflicting request values")
+}
+
+func TestBindAndValidate_CustomValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newMockService(t)
+	err := svc.RegisterValidator("even_len", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String())%2 == 0
+	})
+	assert.NoError(t, err)
+
+	type evenRequest struct {
+		Code string `form:"code" binding:"even_len"`
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/?code=ab", nil)
+	assert.NoError(t, BindAndValidate(c, &evenRequest{}))
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/?code=abc", nil)
+	assert.Error(t, BindAndValidate(c, &evenRequest{}))
+}
@@ -0,0 +1,285 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	svcgrpc "github.com/ranorsolutions/svc-common-go/pkg/grpc"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UnaryOptions configures UnaryHandler.
+type UnaryOptions struct {
+	// FullMethod is the gRPC method the implementation serves, such as
+	// "/users.v1.Users/GetUser", reported to Interceptors in info.FullMethod. It is
+	// required with Interceptors, so method-specific rules apply over HTTP too.
+	FullMethod string
+	// Interceptors run around the implementation as on the gRPC server, e.g. the
+	// authentication interceptors passed to grpc.New. They see the request headers as
+	// incoming metadata. Logging, recovery and tracing are left to the HTTP middleware.
+	Interceptors []grpc.UnaryServerInterceptor
+}
+
+// UnaryHandler serves a unary gRPC method implementation over HTTP, so one
+// implementation backs both protocols:
+//
+//	grpcSvc.Register(func(s *grpc.Server) { pb.RegisterUsersServer(s, users) })
+//	svc.HTTPHandlers = append(svc.HTTPHandlers, &route.Handler{
+//		Method: http.MethodGet, Path: "/users/:id",
+//		Handler: []gin.HandlerFunc{http.UnaryHandler(users.GetUser)},
+//	})
+//
+// Protobuf requests are decoded from the JSON body with protojson, then fields named
+// like path and query parameters, by JSON or proto name, are set from them. Other
+// request types are bound like TypedHandler. The request is validated with
+// grpc.ValidateMessage, and the response is written with protojson for protobuf
+// messages. Status and context errors are answered with the HTTP status of their
+// code and message; other errors are logged and answered with a generic 500, so
+// their text does not reach clients. The implementation is called through the
+// interceptors of opts, if any.
+func UnaryHandler[Req, Resp any](fn func(context.Context, *Req) (Resp, error), opts ...UnaryOptions) gin.HandlerFunc {
+	var interceptors []grpc.UnaryServerInterceptor
+	var fullMethod string
+	for _, opt := range opts {
+		interceptors = append(interceptors, opt.Interceptors...)
+		if opt.FullMethod != "" {
+			fullMethod = opt.FullMethod
+		}
+	}
+	if len(interceptors) > 0 && fullMethod == "" {
+		panic("UnaryHandler: UnaryOptions.FullMethod is required with Interceptors")
+	}
+	call := chainUnary(fullMethod, interceptors, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return fn(ctx, req.(*Req))
+	})
+
+	return func(c *gin.Context) {
+		req := new(Req)
+		if err := bindUnaryRequest(c, req); err != nil {
+			abortWithError(c, err, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := svcgrpc.ValidateMessage(req); err != nil {
+			abortWithError(c, errors.New(status.Convert(err).Message()), "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		ctx := metadata.NewIncomingContext(c.Request.Context(), headerMetadata(c.Request.Header))
+		out, err := call(ctx, req)
+		if err != nil {
+			st, ok := status.FromError(err)
+			if !ok && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+				st, ok = status.FromContextError(err), true
+			}
+			if !ok {
+				LoggerFromContext(c).Error("%s", logging.RedactText(err.Error()))
+				abortWithJSON(c, http.StatusInternalServerError, gin.H{"error": "internal error"})
+				return
+			}
+			abortWithError(c, errors.New(st.Message()), "", HTTPStatusFromCode(st.Code()))
+			return
+		}
+		resp, _ := out.(Resp)
+
+		if m, ok := any(resp).(proto.Message); ok {
+			data, err := protojson.Marshal(m)
+			if err != nil {
+				abortWithError(c, err, "failed to encode response", http.StatusInternalServerError)
+				return
+			}
+			Respond(c, http.StatusOK, json.RawMessage(data))
+			return
+		}
+		Respond(c, http.StatusOK, resp)
+	}
+}
+
+// chainUnary returns handler wrapped in interceptors, the first outermost, as
+// grpc.ChainUnaryInterceptor runs them.
+func chainUnary(fullMethod string, interceptors []grpc.UnaryServerInterceptor, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	info := &grpc.UnaryServerInfo{FullMethod: fullMethod}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return handler
+}
+
+// headerMetadata converts request headers to gRPC metadata, with lower-cased keys.
+func headerMetadata(h http.Header) metadata.MD {
+	md := make(metadata.MD, len(h))
+	for name, values := range h {
+		md[strings.ToLower(name)] = append([]string(nil), values...)
+	}
+	return md
+}
+
+// bindUnaryRequest binds the request into req, a protobuf message or bindable struct.
+func bindUnaryRequest(c *gin.Context, req any) error {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return BindAndValidate(c, req)
+	}
+
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+		if len(body) > 0 {
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, m); err != nil {
+				return fmt.Errorf("invalid JSON body: %w", err)
+			}
+		}
+	}
+
+	msg := m.ProtoReflect()
+	for key, values := range c.Request.URL.Query() {
+		if err := setProtoField(msg, key, values); err != nil {
+			return fmt.Errorf("invalid query parameters: %w", err)
+		}
+	}
+	for _, p := range c.Params {
+		if err := setProtoField(msg, p.Key, []string{p.Value}); err != nil {
+			return fmt.Errorf("invalid path parameters: %w", err)
+		}
+	}
+	return nil
+}
+
+// setProtoField sets the scalar or repeated scalar field of msg named name from
+// values. Parameters matching no field are ignored.
+func setProtoField(msg protoreflect.Message, name string, values []string) error {
+	fields := msg.Descriptor().Fields()
+	fd := fields.ByJSONName(name)
+	if fd == nil {
+		fd = fields.ByName(protoreflect.Name(name))
+	}
+	if fd == nil || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return nil
+	}
+
+	if fd.IsList() {
+		list := msg.Mutable(fd).List()
+		for _, raw := range values {
+			v, err := protoScalar(fd, raw)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+
+	v, err := protoScalar(fd, values[len(values)-1])
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+// protoScalar parses raw as a value of the scalar field fd.
+func protoScalar(fd protoreflect.FieldDescriptor, raw string) (protoreflect.Value, error) {
+	var (
+		v   protoreflect.Value
+		err error
+	)
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), nil
+	case protoreflect.BytesKind:
+		var b []byte
+		b, err = base64.StdEncoding.DecodeString(raw)
+		v = protoreflect.ValueOfBytes(b)
+	case protoreflect.BoolKind:
+		var b bool
+		b, err = strconv.ParseBool(raw)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, err = strconv.ParseInt(raw, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var n int64
+		n, err = strconv.ParseInt(raw, 10, 64)
+		v = protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var n uint64
+		n, err = strconv.ParseUint(raw, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var n uint64
+		n, err = strconv.ParseUint(raw, 10, 64)
+		v = protoreflect.ValueOfUint64(n)
+	case protoreflect.FloatKind:
+		var f float64
+		f, err = strconv.ParseFloat(raw, 32)
+		v = protoreflect.ValueOfFloat32(float32(f))
+	case protoreflect.DoubleKind:
+		var f float64
+		f, err = strconv.ParseFloat(raw, 64)
+		v = protoreflect.ValueOfFloat64(f)
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(raw)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		var n int64
+		n, err = strconv.ParseInt(raw, 10, 32)
+		v = protoreflect.ValueOfEnum(protoreflect.EnumNumber(n))
+	default:
+		return v, fmt.Errorf("unsupported field %s", fd.Name())
+	}
+	if err != nil {
+		return v, fmt.Errorf("invalid value %q for %s", raw, fd.Name())
+	}
+	return v, nil
+}
+
+// HTTPStatusFromCode maps a gRPC status code to the HTTP status conventionally
+// used for it, as gRPC-HTTP gateways do.
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// healthImpl is one implementation served over both gRPC and HTTP.
+type healthImpl struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls atomic.Int32
+}
+
+func (h *healthImpl) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	h.calls.Add(1)
+	if req.Service != "users" {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func newUnaryClients(t *testing.T, impl *healthImpl) (grpc_health_v1.HealthClient, *gin.Engine) {
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, impl)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/health/:service", UnaryHandler(impl.Check))
+	r.POST("/health", UnaryHandler(impl.Check))
+	return grpc_health_v1.NewHealthClient(conn), r
+}
+
+func TestUnaryHandler_SharesImplementation(t *testing.T) {
+	impl := &healthImpl{}
+	client, r := newUnaryClients(t, impl)
+
+	grpcResp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "users"})
+	require.NoError(t, err)
+	want, err := protojson.Marshal(grpcResp)
+	require.NoError(t, err)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/health/users", nil),
+		httptest.NewRequest(http.MethodPost, "/health", strings.NewReader(`{"service":"users"}`)),
+		httptest.NewRequest(http.MethodPost, "/health?service=users", nil),
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, string(want), w.Body.String())
+	}
+	assert.EqualValues(t, 4, impl.calls.Load(), "both protocols invoke the same implementation")
+}
+
+func TestUnaryHandler_Errors(t *testing.T) {
+	impl := &healthImpl{}
+	client, r := newUnaryClients(t, impl)
+
+	_, grpcErr := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "billing"})
+	assert.Equal(t, codes.NotFound, status.Code(grpcErr))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/billing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"`+strings.ReplaceAll(status.Convert(grpcErr).Message(), `"`, `\"`)+`"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/health", strings.NewReader(`{"service":`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.EqualValues(t, 2, impl.calls.Load(), "invalid requests do not reach the implementation")
+}
+
+// echoRequest validates itself like protoc-gen-validate messages.
+type echoRequest struct {
+	Text string `json:"text"`
+}
+
+func (r *echoRequest) Validate() error {
+	if r.Text == "" {
+		return errors.New("text is required")
+	}
+	return nil
+}
+
+func TestUnaryHandler_PlainTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/echo", UnaryHandler(func(_ context.Context, req *echoRequest) (map[string]string, error) {
+		return map[string]string{"text": req.Text}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"text":"hi"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"text":"hi"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"text is required","details":"invalid request"}`, w.Body.String())
+}
+
+func TestUnaryHandler_HidesPlainErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &recordingSink{}
+	r := gin.New()
+	r.Use(withSink(sink))
+	r.POST("/plain", UnaryHandler(func(context.Context, *echoRequest) (map[string]string, error) {
+		return nil, errors.New("pq: relation \"users\" does not exist")
+	}))
+	r.POST("/deadline", UnaryHandler(func(context.Context, *echoRequest) (map[string]string, error) {
+		return nil, fmt.Errorf("query users: %w", context.DeadlineExceeded)
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/plain", strings.NewReader(`{"text":"hi"}`)))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error":"internal error"}`, w.Body.String())
+	require.Len(t, sink.Lines(), 1)
+	assert.Contains(t, sink.Lines()[0], `relation "users" does not exist`, "the cause is logged")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/deadline", strings.NewReader(`{"text":"hi"}`)))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestUnaryHandler_RunsInterceptors(t *testing.T) {
+	var methods []string
+	auth := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		methods = append(methods, info.FullMethod)
+		md, _ := metadata.FromIncomingContext(ctx)
+		if got := md.Get("authorization"); len(got) == 0 || got[0] != "Bearer ok" {
+			return nil, status.Error(codes.Unauthenticated, "missing credentials")
+		}
+		return handler(ctx, req)
+	}
+	tag := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		methods = append(methods, "tag")
+		return handler(ctx, req)
+	}
+
+	impl := &healthImpl{}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/health/:service", UnaryHandler(impl.Check, UnaryOptions{
+		FullMethod:   "/grpc.health.v1.Health/Check",
+		Interceptors: []grpc.UnaryServerInterceptor{auth, tag},
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/users", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Zero(t, impl.calls.Load(), "rejected calls do not reach the implementation")
+
+	req := httptest.NewRequest(http.MethodGet, "/health/users", nil)
+	req.Header.Set("Authorization", "Bearer ok")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 1, impl.calls.Load())
+	assert.Equal(t, []string{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Check", "tag"}, methods)
+
+	assert.PanicsWithValue(t, "UnaryHandler: UnaryOptions.FullMethod is required with Interceptors", func() {
+		UnaryHandler(impl.Check, UnaryOptions{Interceptors: []grpc.UnaryServerInterceptor{auth}})
+	})
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, HTTPStatusFromCode(codes.OK))
+	assert.Equal(t, http.StatusBadRequest, HTTPStatusFromCode(codes.InvalidArgument))
+	assert.Equal(t, http.StatusUnauthorized, HTTPStatusFromCode(codes.Unauthenticated))
+	assert.Equal(t, http.StatusTooManyRequests, HTTPStatusFromCode(codes.ResourceExhausted))
+	assert.Equal(t, http.StatusServiceUnavailable, HTTPStatusFromCode(codes.Unavailable))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatusFromCode(codes.Unknown))
+}
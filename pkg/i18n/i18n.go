@@ -0,0 +1,118 @@
+// Package i18n negotiates a per-request locale from Accept-Language and looks up
+// localized messages in a catalog.
+package i18n
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// ContextKey is the gin context key holding the negotiated locale.
+const ContextKey = "locale"
+
+// Catalog holds messages per locale. The fallback locale answers requests that
+// match no supported locale and keys missing from the negotiated one.
+// It is read-only once created and safe for concurrent use.
+type Catalog struct {
+	fallback string
+	locales  []string
+	messages map[string]map[string]string
+	matcher  language.Matcher
+}
+
+// NewCatalog creates a catalog from messages keyed by locale (BCP 47, e.g. "en",
+// "pt-BR") and then by message key. fallback must be one of the locales.
+func NewCatalog(fallback string, messages map[string]map[string]string) (*Catalog, error) {
+	fb, err := language.Parse(fallback)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fallback locale %q: %w", fallback, err)
+	}
+
+	// The matcher defaults to its first tag, so the fallback goes first
+	c := &Catalog{fallback: fb.String(), messages: map[string]map[string]string{}}
+	tags := []language.Tag{fb}
+	for locale, msgs := range messages {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale %q: %w", locale, err)
+		}
+		c.messages[tag.String()] = msgs
+		if tag != fb {
+			tags = append(tags, tag)
+		}
+	}
+	if _, ok := c.messages[c.fallback]; !ok {
+		return nil, fmt.Errorf("fallback locale %q has no messages", fallback)
+	}
+
+	for _, tag := range tags {
+		c.locales = append(c.locales, tag.String())
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+// Fallback returns the fallback locale.
+func (c *Catalog) Fallback() string {
+	return c.fallback
+}
+
+// Negotiate picks the supported locale best matching an Accept-Language header,
+// honoring q-values and regional variants ("en-GB" matches "en"), and returns
+// the fallback when nothing matches or the header is malformed.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return c.fallback
+	}
+
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return c.fallback
+	}
+	return c.locales[index]
+}
+
+// Message returns the message for key in locale, falling back to the fallback
+// locale and finally to key itself. With args the message is used as a format string.
+func (c *Catalog) Message(locale, key string, args ...any) string {
+	msg, ok := c.messages[locale][key]
+	if !ok {
+		if msg, ok = c.messages[c.fallback][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// T localizes key for the request's negotiated locale.
+func (c *Catalog) T(ctx *gin.Context, key string, args ...any) string {
+	locale := LocaleFromContext(ctx)
+	if locale == "" {
+		locale = c.fallback
+	}
+	return c.Message(locale, key, args...)
+}
+
+// LocaleMiddleware negotiates the request locale from Accept-Language, stores it
+// under ContextKey and announces it in Content-Language.
+// Register it through svc.HTTPMiddleware at route.PhaseContext.
+func LocaleMiddleware(catalog *Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := catalog.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(ContextKey, locale)
+		c.Header("Content-Language", locale)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// LocaleFromContext returns the locale negotiated by LocaleMiddleware, or "" without it.
+func LocaleFromContext(c *gin.Context) string {
+	return c.GetString(ContextKey)
+}
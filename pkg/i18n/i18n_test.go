@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCatalog(t *testing.T) *Catalog {
+	c, err := NewCatalog("en", map[string]map[string]string{
+		"en":    {"not_found": "%s not found", "greeting": "Hello"},
+		"fr":    {"not_found": "%s introuvable"},
+		"pt-BR": {"greeting": "Olá"},
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestNegotiate(t *testing.T) {
+	c := newCatalog(t)
+	for header, want := range map[string]string{
+		"fr":                      "fr",
+		"fr-CA,fr;q=0.9":          "fr",
+		"de;q=0.9, pt-BR;q=0.8":   "pt-BR",
+		"pt-BR;q=0.2, fr;q=0.8":   "fr",
+		"en-GB":                   "en",
+		"de, ja":                  "en",
+		"":                        "en",
+		"not a ;; valid header==": "en",
+	} {
+		assert.Equal(t, want, c.Negotiate(header), "Accept-Language %q", header)
+	}
+}
+
+func TestMessage_Fallbacks(t *testing.T) {
+	c := newCatalog(t)
+	assert.Equal(t, "widget introuvable", c.Message("fr", "not_found", "widget"))
+	assert.Equal(t, "Hello", c.Message("fr", "greeting"), "missing keys use the fallback locale")
+	assert.Equal(t, "Olá", c.Message("pt-BR", "greeting"))
+	assert.Equal(t, "unknown_key", c.Message("fr", "unknown_key"))
+}
+
+func TestNewCatalog_Errors(t *testing.T) {
+	_, err := NewCatalog("xx-invalid-!!", nil)
+	assert.Error(t, err)
+
+	_, err = NewCatalog("en", map[string]map[string]string{"fr": {}})
+	assert.ErrorContains(t, err, "fallback locale")
+
+	_, err = NewCatalog("en", map[string]map[string]string{"en": {}, "bad locale!": {}})
+	assert.ErrorContains(t, err, "invalid locale")
+}
+
+func TestLocaleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := newCatalog(t)
+
+	r := gin.New()
+	r.Use(LocaleMiddleware(c))
+	r.GET("/widgets/:id", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusNotFound, gin.H{"locale": LocaleFromContext(ctx), "error": c.T(ctx, "not_found", ctx.Param("id"))})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9,en;q=0.5")
+	r.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"locale":"fr","error":"42 introuvable"}`, w.Body.String())
+	assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/widgets/7", nil))
+	assert.JSONEq(t, `{"locale":"en","error":"7 not found"}`, w.Body.String())
+}
+
+func TestT_WithoutMiddlewareUsesFallback(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, "", LocaleFromContext(ctx))
+	assert.Equal(t, "Hello", newCatalog(t).T(ctx, "greeting"))
+}
@@ -0,0 +1,555 @@
+// Package jsonschema validates decoded JSON documents against a JSON Schema.
+//
+// It implements the validation keywords commonly used for request payloads:
+// type, enum, const, properties, required, additionalProperties, items,
+// minItems, maxItems, uniqueItems, minLength, maxLength, pattern, minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf
+// and not. Annotation keywords such as title, description and format are
+// accepted and ignored. References ($ref) are not supported and are rejected
+// by Compile so a schema is never silently under-enforced.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation describes one way a document fails its schema. Path is a JSON
+// Pointer to the offending value, "" for the document root.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Schema is a compiled JSON Schema. It is safe for concurrent use.
+type Schema struct {
+	types        []string
+	enum         []any
+	constant     *any
+	properties   map[string]*Schema
+	required     []string
+	additional   *Schema
+	noAdditional bool
+	items        *Schema
+	minItems     *int
+	maxItems     *int
+	uniqueItems  bool
+	minLength    *int
+	maxLength    *int
+	pattern      *regexp.Regexp
+	minimum      *float64
+	maximum      *float64
+	exclMinimum  *float64
+	exclMaximum  *float64
+	multipleOf   *float64
+	allOf        []*Schema
+	anyOf        []*Schema
+	oneOf        []*Schema
+	not          *Schema
+	// never is set for the boolean schema false.
+	never bool
+}
+
+// annotations are keywords accepted but not enforced.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "format": true, "readOnly": true, "writeOnly": true, "deprecated": true,
+}
+
+// Compile parses a JSON Schema document.
+func Compile(raw []byte) (*Schema, error) {
+	var doc any
+	if err := decode(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	return compile(doc, "")
+}
+
+// MustCompile is like Compile but panics on error, for schemas declared at package level.
+func MustCompile(raw string) *Schema {
+	s, err := Compile([]byte(raw))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ValidateJSON decodes raw and validates it, reporting malformed JSON as a root violation.
+func (s *Schema) ValidateJSON(raw []byte) []Violation {
+	var doc any
+	if err := decode(raw, &doc); err != nil {
+		return []Violation{{Path: "", Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	return s.Validate(doc)
+}
+
+// Validate checks a document decoded with json.Decoder.UseNumber, or from plain
+// encoding/json, and returns every violation found, ordered by path.
+func (s *Schema) Validate(doc any) []Violation {
+	var out []Violation
+	s.validate(doc, "", &out)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+func decode(raw []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
+
+func compile(doc any, path string) (*Schema, error) {
+	switch v := doc.(type) {
+	case bool:
+		return &Schema{never: !v}, nil
+	case map[string]any:
+		return compileObject(v, path)
+	default:
+		return nil, fmt.Errorf("schema at %q must be an object or boolean", path)
+	}
+}
+
+func compileObject(m map[string]any, path string) (*Schema, error) {
+	s := &Schema{}
+	for key, val := range m {
+		at := path + "/" + key
+		var err error
+		switch key {
+		case "type":
+			s.types, err = compileTypes(val, at)
+		case "enum":
+			list, ok := val.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array", at)
+			}
+			s.enum = list
+		case "const":
+			c := val
+			s.constant = &c
+		case "properties":
+			props, ok := val.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an object", at)
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, sub := range props {
+				if s.properties[name], err = compile(sub, at+"/"+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = compileStrings(val, at)
+		case "additionalProperties":
+			if b, ok := val.(bool); ok {
+				s.noAdditional = !b
+			} else {
+				s.additional, err = compile(val, at)
+			}
+		case "items":
+			s.items, err = compile(val, at)
+		case "minItems":
+			s.minItems, err = compileInt(val, at)
+		case "maxItems":
+			s.maxItems, err = compileInt(val, at)
+		case "uniqueItems":
+			b, ok := val.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a boolean", at)
+			}
+			s.uniqueItems = b
+		case "minLength":
+			s.minLength, err = compileInt(val, at)
+		case "maxLength":
+			s.maxLength, err = compileInt(val, at)
+		case "pattern":
+			p, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string", at)
+			}
+			if s.pattern, err = regexp.Compile(p); err != nil {
+				return nil, fmt.Errorf("%s: %w", at, err)
+			}
+		case "minimum":
+			s.minimum, err = compileNumber(val, at)
+		case "maximum":
+			s.maximum, err = compileNumber(val, at)
+		case "exclusiveMinimum":
+			s.exclMinimum, err = compileNumber(val, at)
+		case "exclusiveMaximum":
+			s.exclMaximum, err = compileNumber(val, at)
+		case "multipleOf":
+			if s.multipleOf, err = compileNumber(val, at); err == nil && *s.multipleOf <= 0 {
+				err = fmt.Errorf("%s must be greater than 0", at)
+			}
+		case "allOf":
+			s.allOf, err = compileList(val, at)
+		case "anyOf":
+			s.anyOf, err = compileList(val, at)
+		case "oneOf":
+			s.oneOf, err = compileList(val, at)
+		case "not":
+			s.not, err = compile(val, at)
+		default:
+			if !annotations[key] {
+				return nil, fmt.Errorf("unsupported schema keyword %s", at)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+var knownTypes = map[string]bool{"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true}
+
+func compileTypes(val any, at string) ([]string, error) {
+	var types []string
+	switch v := val.(type) {
+	case string:
+		types = []string{v}
+	case []any:
+		list, err := compileStrings(v, at)
+		if err != nil {
+			return nil, err
+		}
+		types = list
+	default:
+		return nil, fmt.Errorf("%s must be a string or array of strings", at)
+	}
+	for _, t := range types {
+		if !knownTypes[t] {
+			return nil, fmt.Errorf("%s: unknown type %q", at, t)
+		}
+	}
+	return types, nil
+}
+
+func compileStrings(val any, at string) ([]string, error) {
+	list, ok := val.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", at)
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings", at)
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+func compileList(val any, at string) ([]*Schema, error) {
+	list, ok := val.([]any)
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%s must be a non-empty array", at)
+	}
+	out := make([]*Schema, 0, len(list))
+	for i, item := range list {
+		sub, err := compile(item, at+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sub)
+	}
+	return out, nil
+}
+
+func compileNumber(val any, at string) (*float64, error) {
+	f, ok := number(val)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number", at)
+	}
+	return &f, nil
+}
+
+func compileInt(val any, at string) (*int, error) {
+	f, ok := number(val)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("%s must be a non-negative integer", at)
+	}
+	n := int(f)
+	return &n, nil
+}
+
+func (s *Schema) validate(v any, path string, out *[]Violation) {
+	if s.never {
+		addf(out, path, "value is not allowed")
+		return
+	}
+
+	if len(s.types) > 0 && !s.matchesType(v) {
+		addf(out, path, "expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+		return
+	}
+	if s.enum != nil && !contains(s.enum, v) {
+		addf(out, path, "value must be one of %s", formatValues(s.enum))
+	}
+	if s.constant != nil && !equal(*s.constant, v) {
+		addf(out, path, "value must be %s", formatValue(*s.constant))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		s.validateObject(val, path, out)
+	case []any:
+		s.validateArray(val, path, out)
+	case string:
+		s.validateString(val, path, out)
+	default:
+		if f, ok := number(v); ok {
+			s.validateNumber(f, path, out)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(v, path, out)
+	}
+	if len(s.anyOf) > 0 && s.countMatches(s.anyOf, v) == 0 {
+		addf(out, path, "value must match at least one of the allowed schemas")
+	}
+	if len(s.oneOf) > 0 {
+		if n := s.countMatches(s.oneOf, v); n != 1 {
+			addf(out, path, "value must match exactly one of the allowed schemas, matched %d", n)
+		}
+	}
+	if s.not != nil && s.not.matches(v) {
+		addf(out, path, "value must not match the disallowed schema")
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]any, path string, out *[]Violation) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			addf(out, path+"/"+escape(name), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		at := path + "/" + escape(name)
+		if sub, ok := s.properties[name]; ok {
+			sub.validate(obj[name], at, out)
+			continue
+		}
+		switch {
+		case s.noAdditional:
+			addf(out, at, "unknown property")
+		case s.additional != nil:
+			s.additional.validate(obj[name], at, out)
+		}
+	}
+}
+
+func (s *Schema) validateArray(arr []any, path string, out *[]Violation) {
+	if s.minItems != nil && len(arr) < *s.minItems {
+		addf(out, path, "must contain at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(arr) > *s.maxItems {
+		addf(out, path, "must contain at most %d items", *s.maxItems)
+	}
+	if s.uniqueItems {
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if equal(arr[i], arr[j]) {
+					addf(out, path+"/"+strconv.Itoa(i), "duplicates item %d", j)
+					break
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range arr {
+			s.items.validate(item, path+"/"+strconv.Itoa(i), out)
+		}
+	}
+}
+
+func (s *Schema) validateString(str, path string, out *[]Violation) {
+	n := utf8.RuneCountInString(str)
+	if s.minLength != nil && n < *s.minLength {
+		addf(out, path, "must be at least %d characters", *s.minLength)
+	}
+	if s.maxLength != nil && n > *s.maxLength {
+		addf(out, path, "must be at most %d characters", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		addf(out, path, "must match pattern %s", s.pattern)
+	}
+}
+
+func (s *Schema) validateNumber(f float64, path string, out *[]Violation) {
+	if s.minimum != nil && f < *s.minimum {
+		addf(out, path, "must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		addf(out, path, "must be <= %v", *s.maximum)
+	}
+	if s.exclMinimum != nil && f <= *s.exclMinimum {
+		addf(out, path, "must be > %v", *s.exclMinimum)
+	}
+	if s.exclMaximum != nil && f >= *s.exclMaximum {
+		addf(out, path, "must be < %v", *s.exclMaximum)
+	}
+	if s.multipleOf != nil {
+		if q := f / *s.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			addf(out, path, "must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+func (s *Schema) matches(v any) bool {
+	var out []Violation
+	s.validate(v, "", &out)
+	return len(out) == 0
+}
+
+func (s *Schema) countMatches(schemas []*Schema, v any) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub.matches(v) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Schema) matchesType(v any) bool {
+	actual := typeOf(v)
+	for _, t := range s.types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf names the JSON type of v, reporting whole numbers as "integer".
+func typeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	default:
+		if f, ok := number(val); ok {
+			if f == math.Trunc(f) && !math.IsInf(f, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equal compares JSON values, treating numbers by value regardless of representation.
+func equal(a, b any) bool {
+	if fa, ok := number(a); ok {
+		fb, ok := number(b)
+		return ok && fa == fb
+	}
+	switch av := a.(type) {
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, x := range av {
+			y, ok := bv[k]
+			if !ok || !equal(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func contains(list []any, v any) bool {
+	for _, item := range list {
+		if equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func formatValues(list []any) string {
+	parts := make([]string, 0, len(list))
+	for _, v := range list {
+		parts = append(parts, formatValue(v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escape encodes a property name as a JSON Pointer reference token.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func addf(out *[]Violation, path, format string, args ...any) {
+	*out = append(*out, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
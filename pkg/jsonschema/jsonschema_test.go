@@ -0,0 +1,113 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "order",
+	"type": "object",
+	"required": ["id", "items"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string", "pattern": "^ord_[a-z0-9]+$"},
+		"status": {"enum": ["open", "closed"]},
+		"note": {"type": ["string", "null"], "maxLength": 5},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["sku", "qty"],
+				"properties": {
+					"sku": {"type": "string", "minLength": 1},
+					"qty": {"type": "integer", "minimum": 1, "maximum": 100},
+					"price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01}
+				}
+			}
+		}
+	}
+}`
+
+func TestValidate_ValidDocument(t *testing.T) {
+	s := MustCompile(orderSchema)
+	assert.Empty(t, s.ValidateJSON([]byte(`{"id":"ord_1","status":"open","note":null,"items":[{"sku":"a","qty":2,"price":9.99}]}`)))
+}
+
+func TestValidate_ReportsEveryViolationByPath(t *testing.T) {
+	s := MustCompile(orderSchema)
+	got := s.ValidateJSON([]byte(`{"id":"ORD-1","status":"pending","extra":true,"items":[{"sku":"","qty":1.5},{"qty":0,"price":0}]}`))
+
+	assert.Equal(t, []Violation{
+		{Path: "/extra", Message: "unknown property"},
+		{Path: "/id", Message: "must match pattern ^ord_[a-z0-9]+$"},
+		{Path: "/items/0/qty", Message: "expected integer, got number"},
+		{Path: "/items/0/sku", Message: "must be at least 1 characters"},
+		{Path: "/items/1/price", Message: "must be > 0"},
+		{Path: "/items/1/qty", Message: "must be >= 1"},
+		{Path: "/items/1/sku", Message: "is required"},
+		{Path: "/status", Message: `value must be one of "open", "closed"`},
+	}, got)
+}
+
+func TestValidate_Types(t *testing.T) {
+	s := MustCompile(`{"type":"object","properties":{"n":{"type":"number"},"b":{"type":"boolean"}}}`)
+	assert.Empty(t, s.ValidateJSON([]byte(`{"n":3,"b":false}`)))
+	assert.Equal(t, []Violation{{Path: "", Message: "expected object, got array"}}, s.ValidateJSON([]byte(`[]`)))
+	assert.Equal(t, []Violation{{Path: "/b", Message: "expected boolean, got string"}}, s.ValidateJSON([]byte(`{"b":"yes"}`)))
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	s := MustCompile(`{"oneOf":[{"type":"string"},{"type":"integer"}],"not":{"const":"forbidden"}}`)
+	assert.Empty(t, s.ValidateJSON([]byte(`"ok"`)))
+	assert.Empty(t, s.ValidateJSON([]byte(`7`)))
+	assert.Len(t, s.ValidateJSON([]byte(`true`)), 1)
+	assert.Equal(t, []Violation{{Path: "", Message: "value must not match the disallowed schema"}}, s.ValidateJSON([]byte(`"forbidden"`)))
+
+	s = MustCompile(`{"anyOf":[{"minimum":10},{"maximum":0}],"allOf":[{"type":"integer"}]}`)
+	assert.Empty(t, s.ValidateJSON([]byte(`11`)))
+	assert.Len(t, s.ValidateJSON([]byte(`5`)), 1)
+}
+
+func TestValidate_ArraysAndAdditionalSchemas(t *testing.T) {
+	s := MustCompile(`{"type":"object","additionalProperties":{"type":"array","uniqueItems":true,"maxItems":2}}`)
+	assert.Empty(t, s.ValidateJSON([]byte(`{"tags":["a","b"]}`)))
+	assert.Equal(t, []Violation{
+		{Path: "/tags", Message: "must contain at most 2 items"},
+		{Path: "/tags/2", Message: "duplicates item 0"},
+	}, s.ValidateJSON([]byte(`{"tags":["a","b","a"]}`)))
+}
+
+func TestValidate_BooleanSchemasAndPlainDecoding(t *testing.T) {
+	s := MustCompile(`{"properties":{"gone":false},"required":["n"]}`)
+	assert.Equal(t, []Violation{{Path: "/gone", Message: "value is not allowed"}}, s.Validate(map[string]any{"n": 1.0, "gone": 1}))
+	assert.Empty(t, MustCompile(`true`).Validate("anything"))
+}
+
+func TestValidateJSON_Malformed(t *testing.T) {
+	got := MustCompile(`{}`).ValidateJSON([]byte(`{"a":`))
+	require.Len(t, got, 1)
+	assert.Equal(t, "", got[0].Path)
+	assert.Contains(t, got[0].Message, "invalid JSON")
+}
+
+func TestCompile_RejectsUnsupportedOrInvalidSchemas(t *testing.T) {
+	for _, raw := range []string{
+		`{"$ref":"#/$defs/x"}`,
+		`{"type":"uuid"}`,
+		`{"minLength":-1}`,
+		`{"pattern":"("}`,
+		`{"anyOf":[]}`,
+		`{"properties":{"a":"string"}}`,
+		`["not a schema"]`,
+		`{`,
+	} {
+		_, err := Compile([]byte(raw))
+		assert.Error(t, err, raw)
+	}
+	assert.Panics(t, func() { MustCompile(`{"$ref":"#"}`) })
+}
@@ -0,0 +1,202 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchSink defaults.
+const (
+	defaultBatchQueueSize     = 4096
+	defaultBatchSize          = 256
+	defaultBatchFlushInterval = time.Second
+)
+
+// BatchOptions configures NewBatchSink. Zero values use the defaults.
+type BatchOptions struct {
+	// QueueSize bounds the entries waiting to be flushed. Defaults to 4096.
+	QueueSize int
+	// BatchSize is the number of entries that triggers a flush. Defaults to 256.
+	BatchSize int
+	// FlushInterval bounds how long an entry waits before it is flushed. Defaults to 1s.
+	FlushInterval time.Duration
+}
+
+type batchLevel int
+
+const (
+	batchInfo batchLevel = iota
+	batchWarn
+	batchError
+	batchDebug
+)
+
+type batchEntry struct {
+	level batchLevel
+	msg   string
+}
+
+// BatchSink is a Sink that queues entries and writes them to the wrapped sink from a
+// background goroutine, in batches flushed when BatchSize entries are queued or every
+// FlushInterval. Logging never blocks: once the queue is full, entries are dropped
+// and counted, and the count is reported to the wrapped sink on the next flush.
+// Close flushes what is queued; entries logged after Close are written synchronously.
+type BatchSink struct {
+	next    Sink
+	opts    BatchOptions
+	queue   chan batchEntry
+	dropped atomic.Int64
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	closing   chan struct{}
+	done      chan struct{}
+}
+
+// NewBatchSink starts a BatchSink writing to next.
+func NewBatchSink(next Sink, opts BatchOptions) *BatchSink {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultBatchQueueSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultBatchFlushInterval
+	}
+
+	b := &BatchSink{
+		next:    next,
+		opts:    opts,
+		queue:   make(chan batchEntry, opts.QueueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Info queues an informational message.
+func (b *BatchSink) Info(format string, args ...interface{}) {
+	b.enqueue(batchInfo, format, args)
+}
+
+// Warn queues a warning.
+func (b *BatchSink) Warn(format string, args ...interface{}) {
+	b.enqueue(batchWarn, format, args)
+}
+
+// Error queues an error.
+func (b *BatchSink) Error(format string, args ...interface{}) {
+	b.enqueue(batchError, format, args)
+}
+
+// Debug queues a debug message, written only when the wrapped sink is a DebugSink.
+func (b *BatchSink) Debug(format string, args ...interface{}) {
+	b.enqueue(batchDebug, format, args)
+}
+
+// Dropped returns the number of entries dropped because the queue was full.
+func (b *BatchSink) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close flushes the queued entries and stops the background goroutine, returning
+// ctx's error if the flush does not finish in time. It is safe to call more than once.
+func (b *BatchSink) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		b.mu.Unlock()
+		close(b.closing)
+	})
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("log flush did not complete: %w", ctx.Err())
+	}
+}
+
+// enqueue formats the entry up front, so arguments mutated after the call are not
+// observed by the background writer.
+func (b *BatchSink) enqueue(level batchLevel, format string, args []interface{}) {
+	e := batchEntry{level: level, msg: fmt.Sprintf(format, args...)}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		b.write(e)
+		return
+	}
+
+	select {
+	case b.queue <- e:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+func (b *BatchSink) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]batchEntry, 0, b.opts.BatchSize)
+	var reported int64
+	flush := func() {
+		for _, e := range batch {
+			b.write(e)
+		}
+		batch = batch[:0]
+
+		if dropped := b.dropped.Load(); dropped > reported {
+			b.next.Warn("log queue full, dropped %d entries", dropped-reported)
+			reported = dropped
+		}
+	}
+
+	for {
+		select {
+		case e := <-b.queue:
+			if batch = append(batch, e); len(batch) >= b.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-b.closing:
+			for {
+				select {
+				case e := <-b.queue:
+					if batch = append(batch, e); len(batch) >= b.opts.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *BatchSink) write(e batchEntry) {
+	switch e.level {
+	case batchWarn:
+		b.next.Warn("%s", e.msg)
+	case batchError:
+		b.next.Error("%s", e.msg)
+	case batchDebug:
+		if d, ok := b.next.(DebugSink); ok {
+			d.Debug("%s", e.msg)
+		}
+	default:
+		b.next.Info("%s", e.msg)
+	}
+}
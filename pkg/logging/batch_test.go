@@ -0,0 +1,152 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncSink records lines from the background writer. When gate is set, every write
+// signals entered and then waits on gate.
+type syncSink struct {
+	mu      sync.Mutex
+	lines   []string
+	gate    chan struct{}
+	entered chan struct{}
+}
+
+func (s *syncSink) record(line string) {
+	if s.gate != nil {
+		s.entered <- struct{}{}
+		<-s.gate
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, line)
+}
+
+func (s *syncSink) Info(format string, args ...interface{}) {
+	s.record("INFO " + fmt.Sprintf(format, args...))
+}
+
+func (s *syncSink) Warn(format string, args ...interface{}) {
+	s.record("WARN " + fmt.Sprintf(format, args...))
+}
+
+func (s *syncSink) Error(format string, args ...interface{}) {
+	s.record("ERROR " + fmt.Sprintf(format, args...))
+}
+
+func (s *syncSink) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+func TestBatchSink_FlushesFullBatches(t *testing.T) {
+	sink := &syncSink{}
+	b := NewBatchSink(sink, BatchOptions{BatchSize: 3, FlushInterval: time.Hour})
+	defer b.Close(context.Background())
+
+	b.Info("one")
+	b.Warn("two")
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, sink.Lines(), "entries are held until the batch is full")
+
+	b.Error("three %d", 3)
+	assert.Eventually(t, func() bool { return len(sink.Lines()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"INFO one", "WARN two", "ERROR three 3"}, sink.Lines())
+}
+
+// syncDebugSink is a syncSink accepting debug output.
+type syncDebugSink struct {
+	syncSink
+}
+
+func (s *syncDebugSink) Debug(format string, args ...interface{}) {
+	s.record("DEBUG " + fmt.Sprintf(format, args...))
+}
+
+func TestBatchSink_Debug(t *testing.T) {
+	sink := &syncDebugSink{}
+	b := NewBatchSink(sink, BatchOptions{})
+	b.Debug("detail %d", 1)
+	require.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, []string{"DEBUG detail 1"}, sink.Lines())
+
+	// Wrapped sinks without a debug level drop it
+	plain := &syncSink{}
+	b = NewBatchSink(plain, BatchOptions{})
+	b.Debug("detail")
+	b.Info("kept")
+	require.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, []string{"INFO kept"}, plain.Lines())
+}
+
+func TestBatchSink_FlushesOnInterval(t *testing.T) {
+	sink := &syncSink{}
+	b := NewBatchSink(sink, BatchOptions{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer b.Close(context.Background())
+
+	b.Info("lonely")
+	assert.Eventually(t, func() bool { return len(sink.Lines()) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestBatchSink_FlushesOnClose(t *testing.T) {
+	sink := &syncSink{}
+	b := NewBatchSink(sink, BatchOptions{BatchSize: 100, FlushInterval: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		b.Info("entry %d", i)
+	}
+	require.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, []string{"INFO entry 0", "INFO entry 1", "INFO entry 2", "INFO entry 3", "INFO entry 4"}, sink.Lines())
+
+	// Entries after Close are written directly, and Close can be repeated
+	b.Warn("late")
+	assert.Equal(t, "WARN late", sink.Lines()[5])
+	assert.NoError(t, b.Close(context.Background()))
+}
+
+func TestBatchSink_DropsWhenQueueFull(t *testing.T) {
+	sink := &syncSink{gate: make(chan struct{}), entered: make(chan struct{})}
+	b := NewBatchSink(sink, BatchOptions{QueueSize: 2, BatchSize: 1, FlushInterval: time.Hour})
+
+	// Block the writer on the first entry, then overflow the queue behind it
+	b.Info("first")
+	<-sink.entered
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		b.Info("queued %d", i)
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "logging must not block")
+	assert.Equal(t, int64(3), b.Dropped())
+
+	// Release the writer: the queued entries and a drop report follow
+	close(sink.gate)
+	go func() {
+		for range sink.entered {
+		}
+	}()
+	require.NoError(t, b.Close(context.Background()))
+	close(sink.entered)
+	assert.Equal(t, []string{"INFO first", "WARN log queue full, dropped 3 entries", "INFO queued 0", "INFO queued 1"}, sink.Lines())
+}
+
+func TestBatchSink_CloseTimesOut(t *testing.T) {
+	sink := &syncSink{gate: make(chan struct{}), entered: make(chan struct{}, 1)}
+	b := NewBatchSink(sink, BatchOptions{BatchSize: 1})
+	b.Info("stuck")
+	<-sink.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Close(ctx), context.DeadlineExceeded)
+	close(sink.gate)
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys used to propagate log fields across gRPC calls.
+const (
+	RequestIDMetadataKey = "x-request-id"
+	UserIDMetadataKey    = "x-user-id"
+)
+
+// UnaryClientInterceptor forwards the request ID and user ID of the logger in
+// the call context to the downstream service as outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor attaches a logger populated from incoming metadata and
+// the called method to the handler context.
+func UnaryServerInterceptor(sink Sink) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(incomingContext(ctx, sink, info.FullMethod), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor(sink Sink) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := incomingContext(ss.Context(), sink, info.FullMethod)
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	l := FromContext(ctx)
+	pairs := []string{}
+	if v := l.Field(FieldRequestID); v != "" {
+		pairs = append(pairs, RequestIDMetadataKey, v)
+	}
+	if v := l.Field(FieldUserID); v != "" {
+		pairs = append(pairs, UserIDMetadataKey, v)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func incomingContext(ctx context.Context, sink Sink, method string) context.Context {
+	l := New(sink)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		l = l.With(FieldRequestID, first(md.Get(RequestIDMetadataKey)))
+		l = l.With(FieldUserID, first(md.Get(UserIDMetadataKey)))
+	}
+	l = l.With(FieldRoute, method)
+	return NewContext(ctx, l)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
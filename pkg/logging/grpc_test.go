@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// callThrough simulates a unary call from the client interceptor to the server
+// interceptor, converting outgoing metadata into incoming metadata on the way.
+func callThrough(t *testing.T, ctx context.Context, sink Sink, handler grpc.UnaryHandler) {
+	client := UnaryClientInterceptor()
+	server := UnaryServerInterceptor(sink)
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		serverCtx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := server(serverCtx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	err := client(ctx, "/users.Users/Get", nil, nil, nil, invoker)
+	require.NoError(t, err)
+}
+
+func TestInterceptors_PropagateFields(t *testing.T) {
+	clientLogger := New(nil).With(FieldRequestID, "req-42").With(FieldUserID, "user-7")
+	ctx := NewContext(context.Background(), clientLogger)
+
+	sink := &recordingSink{}
+	callThrough(t, ctx, sink, func(ctx context.Context, req interface{}) (interface{}, error) {
+		FromContext(ctx).Info("handling")
+		return nil, nil
+	})
+
+	assert.Equal(t, []string{"INFO request_id=req-42 user_id=user-7 route=/users.Users/Get handling"}, sink.lines)
+}
+
+func TestInterceptors_NoFields(t *testing.T) {
+	sink := &recordingSink{}
+	callThrough(t, context.Background(), sink, func(ctx context.Context, req interface{}) (interface{}, error) {
+		FromContext(ctx).Info("handling")
+		return nil, nil
+	})
+
+	assert.Equal(t, []string{"INFO route=/users.Users/Get handling"}, sink.lines)
+}
@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"context"
+	"strings"
+)
+
+// Sink defines the subset of the service logger used for contextual logging.
+// *logger.Logger from http-common-go satisfies it.
+type Sink interface {
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+}
+
+// DebugSink is implemented by sinks that accept debug output, such as
+// *logger.Logger from http-common-go. Debug lines sent to other sinks are dropped.
+type DebugSink interface {
+	Debug(format string, args ...interface{})
+}
+
+// Keys under which request-scoped log fields are stored on a gin context.
+const (
+	RequestIDKey = "requestID"
+	UserIDKey    = "userID"
+)
+
+// Field names emitted on every contextual log line.
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldRoute     = "route"
+	FieldCaller    = "caller"
+	FieldPath      = "path"
+	FieldClientIP  = "client_ip"
+	FieldQuery     = "query"
+)
+
+// Field is a single key/value pair attached to a log line.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Logger wraps a Sink and prefixes every line with its fields.
+type Logger struct {
+	sink   Sink
+	fields []Field
+}
+
+type loggerKey struct{}
+
+// New creates a contextual logger writing to sink. A nil sink discards output.
+func New(sink Sink, fields ...Field) *Logger {
+	return &Logger{sink: sink, fields: fields}
+}
+
+// With returns a copy of the logger with the field set, replacing any existing
+// value for the same key. Empty values are ignored.
+func (l *Logger) With(key, value string) *Logger {
+	if value == "" {
+		return l
+	}
+
+	fields := make([]Field, 0, len(l.fields)+1)
+	for _, f := range l.fields {
+		if f.Key != key {
+			fields = append(fields, f)
+		}
+	}
+	fields = append(fields, Field{Key: key, Value: value})
+
+	return &Logger{sink: l.sink, fields: fields}
+}
+
+// Field returns the value for key, or an empty string if it is not set.
+func (l *Logger) Field(key string) string {
+	for _, f := range l.fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Fields returns the fields attached to the logger.
+func (l *Logger) Fields() []Field {
+	return append([]Field(nil), l.fields...)
+}
+
+// Debug logs a debug message with the logger's fields when the sink supports it.
+func (l *Logger) Debug(format string, args ...interface{}) {
+	if d, ok := l.sink.(DebugSink); ok {
+		d.Debug(l.prefix()+format, args...)
+	}
+}
+
+// Info logs an informational message with the logger's fields.
+func (l *Logger) Info(format string, args ...interface{}) {
+	if l.sink != nil {
+		l.sink.Info(l.prefix()+format, args...)
+	}
+}
+
+// Warn logs a warning with the logger's fields.
+func (l *Logger) Warn(format string, args ...interface{}) {
+	if l.sink != nil {
+		l.sink.Warn(l.prefix()+format, args...)
+	}
+}
+
+// Error logs an error with the logger's fields.
+func (l *Logger) Error(format string, args ...interface{}) {
+	if l.sink != nil {
+		l.sink.Error(l.prefix()+format, args...)
+	}
+}
+
+// prefix renders the fields as "key=value " pairs, escaping format verbs.
+func (l *Logger) prefix() string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, f := range l.fields {
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(strings.ReplaceAll(f.Value, "%", "%%"))
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// NewContext returns a copy of ctx carrying the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx, or a discarding logger if none is set.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return New(nil)
+}
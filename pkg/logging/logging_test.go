@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Fake sink ---
+
+type recordingSink struct {
+	lines []string
+}
+
+func (s *recordingSink) Info(format string, args ...interface{}) {
+	s.lines = append(s.lines, "INFO "+fmt.Sprintf(format, args...))
+}
+
+func (s *recordingSink) Warn(format string, args ...interface{}) {
+	s.lines = append(s.lines, "WARN "+fmt.Sprintf(format, args...))
+}
+
+func (s *recordingSink) Error(format string, args ...interface{}) {
+	s.lines = append(s.lines, "ERROR "+fmt.Sprintf(format, args...))
+}
+
+// debugSink also accepts debug output.
+type debugSink struct {
+	recordingSink
+}
+
+func (s *debugSink) Debug(format string, args ...interface{}) {
+	s.lines = append(s.lines, "DEBUG "+fmt.Sprintf(format, args...))
+}
+
+// --- Tests ---
+
+func TestLogger_IncludesFields(t *testing.T) {
+	sink := &recordingSink{}
+	l := New(sink).
+		With(FieldRequestID, "req-1").
+		With(FieldUserID, "user-1").
+		With(FieldRoute, "/api/v1/ping")
+
+	l.Info("hello %s", "world")
+	l.Warn("careful")
+	l.Error("failed: %v", "boom")
+
+	assert.Equal(t, []string{
+		"INFO request_id=req-1 user_id=user-1 route=/api/v1/ping hello world",
+		"WARN request_id=req-1 user_id=user-1 route=/api/v1/ping careful",
+		"ERROR request_id=req-1 user_id=user-1 route=/api/v1/ping failed: boom",
+	}, sink.lines)
+}
+
+func TestLogger_Debug(t *testing.T) {
+	sink := &debugSink{}
+	New(sink).With(FieldRequestID, "req-1").Debug("client gone: %v", "EOF")
+	assert.Equal(t, []string{"DEBUG request_id=req-1 client gone: EOF"}, sink.lines)
+
+	// Sinks without a debug level drop the line
+	plain := &recordingSink{}
+	New(plain).Debug("dropped")
+	New(nil).Debug("dropped")
+	assert.Empty(t, plain.lines)
+}
+
+func TestLogger_WithDoesNotMutateParent(t *testing.T) {
+	parent := New(nil).With(FieldRequestID, "req-1")
+	child := parent.With(FieldUserID, "user-1").With(FieldRequestID, "req-2")
+
+	assert.Equal(t, "req-1", parent.Field(FieldRequestID))
+	assert.Equal(t, "", parent.Field(FieldUserID))
+	assert.Equal(t, "req-2", child.Field(FieldRequestID))
+	assert.Equal(t, "user-1", child.Field(FieldUserID))
+	assert.Len(t, child.Fields(), 2)
+}
+
+func TestLogger_EscapesFormatVerbsInFields(t *testing.T) {
+	sink := &recordingSink{}
+	New(sink).With(FieldRoute, "/100%d").Info("ok")
+
+	assert.Equal(t, []string{"INFO route=/100%d ok"}, sink.lines)
+}
+
+func TestFromContext(t *testing.T) {
+	sink := &recordingSink{}
+	ctx := NewContext(context.Background(), New(sink).With(FieldRequestID, "req-1"))
+
+	FromContext(ctx).Info("inside")
+	assert.Equal(t, []string{"INFO request_id=req-1 inside"}, sink.lines)
+
+	// Missing logger discards output without panicking
+	assert.NotPanics(t, func() { FromContext(context.Background()).Info("dropped") })
+}
@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dbConfig struct {
+	Host     string `json:"host"`
+	Password string `json:"password"`
+}
+
+type startupConfig struct {
+	Service   string            `json:"service"`
+	APIKey    string            `json:"api_key" log:"redact"`
+	PIN       int               `log:"redact"`
+	DB        dbConfig          `json:"db"`
+	Replica   *dbConfig         `json:"replica"`
+	Headers   map[string]string `json:"headers"`
+	Tokens    []string          `json:"tokens"`
+	Extra     map[string]any
+	unexposed string
+}
+
+func TestRedact_TagsNamesAndPaths(t *testing.T) {
+	in := startupConfig{
+		Service:   "billing",
+		APIKey:    "sk_live",
+		PIN:       1234,
+		DB:        dbConfig{Host: "db", Password: "hunter2"},
+		Replica:   &dbConfig{Host: "replica", Password: "hunter3"},
+		Headers:   map[string]string{"Authorization": "Bearer abc", "Accept": "json"},
+		Tokens:    []string{"t1"},
+		Extra:     map[string]any{"nested": map[string]any{"secret": "s"}},
+		unexposed: "kept",
+	}
+
+	out := Redact(in, "authorization", "tokens", "secret", "db.password")
+
+	// Tagged fields
+	assert.Equal(t, Mask, out.APIKey)
+	assert.Equal(t, 0, out.PIN)
+	// Path-matched fields only match at that path
+	assert.Equal(t, Mask, out.DB.Password)
+	assert.Equal(t, "hunter3", out.Replica.Password)
+	// Name-matched fields and map keys match anywhere
+	assert.Equal(t, Mask, out.Headers["Authorization"])
+	assert.Nil(t, out.Tokens)
+	assert.Equal(t, Mask, out.Extra["nested"].(map[string]any)["secret"])
+
+	// Everything else is preserved
+	assert.Equal(t, "billing", out.Service)
+	assert.Equal(t, "db", out.DB.Host)
+	assert.Equal(t, "json", out.Headers["Accept"])
+	assert.Equal(t, "kept", out.unexposed)
+}
+
+func TestRedact_DeepCopy(t *testing.T) {
+	in := &startupConfig{
+		APIKey:  "sk_live",
+		Replica: &dbConfig{Password: "hunter3"},
+		Headers: map[string]string{"Authorization": "Bearer abc"},
+	}
+
+	out := Redact(in, "password", "authorization")
+
+	assert.Equal(t, "sk_live", in.APIKey, "the input must not be modified")
+	assert.Equal(t, "hunter3", in.Replica.Password)
+	assert.Equal(t, "Bearer abc", in.Headers["Authorization"])
+
+	assert.Equal(t, Mask, out.APIKey)
+	assert.Equal(t, Mask, out.Replica.Password)
+	assert.NotSame(t, in.Replica, out.Replica)
+}
+
+func TestRedact_Maps(t *testing.T) {
+	in := map[string]any{"user": "ada", "Password": "p", "count": 3}
+	out := Redact(in, "password")
+	assert.Equal(t, map[string]any{"user": "ada", "Password": Mask, "count": 3}, out)
+	assert.Equal(t, "p", in["Password"])
+
+	var nilMap map[string]string
+	assert.Nil(t, Redact(nilMap, "x"))
+}
+
+func TestSensitive(t *testing.T) {
+	for _, name := range []string{"DB_PASSWORD", "apiKey", "X-Auth-Token", "Cookie", "client_secret", "Authorization", "DATABASE_DSN", "SigningKeys"} {
+		assert.True(t, Sensitive(name), name)
+	}
+	for _, name := range []string{"Host", "Keyspace", "Monkey_Count", "user", ""} {
+		assert.False(t, Sensitive(name), name)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	type config struct {
+		Name       string
+		StripeKey  string
+		DB         *dbConfig
+		Signing    string `secret:"true"`
+		Env        map[string]string
+		WebhookPin string
+		Unset      string `secret:"true"`
+	}
+	in := config{
+		Name:      "billing",
+		StripeKey: "sk_live",
+		DB:        &dbConfig{Host: "db", Password: "hunter2"},
+		Signing:   "-----BEGIN KEY-----",
+		Env:       map[string]string{"REDIS_PASSWORD": "p", "REGION": "eu"},
+	}
+
+	out := RedactSecrets(in, "webhookpin")
+	assert.Equal(t, "billing", out.Name)
+	assert.Equal(t, Mask, out.StripeKey)
+	assert.Equal(t, "db", out.DB.Host)
+	assert.Equal(t, Mask, out.DB.Password)
+	assert.Equal(t, Mask, out.Signing)
+	assert.Equal(t, map[string]string{"REDIS_PASSWORD": Mask, "REGION": "eu"}, out.Env)
+	assert.Empty(t, out.Unset, "unset secrets stay empty")
+	assert.Equal(t, "hunter2", in.DB.Password)
+
+	// Without the heuristic only tags and names count
+	assert.Equal(t, "sk_live", Redact(in).StripeKey)
+	assert.Equal(t, Mask, Redact(in).Signing)
+}
+
+func TestRedactText(t *testing.T) {
+	cases := map[string]string{
+		"dial failed: password=hunter2 host=db":            "dial failed: password=" + Mask + " host=db",
+		`bad body {"user":"ada","token":"abc123"}`:         `bad body {"user":"ada","token":"` + Mask + `"}`,
+		"Authorization: Bearer eyJhbGci.x.y rejected":      "Authorization: " + Mask + " rejected",
+		"upstream said bearer abc.def":                     "upstream said bearer " + Mask,
+		"connect postgres://app:s3cret@db:5432/app failed": "connect postgres://app:" + Mask + "@db:5432/app failed",
+		"api_key=k1&page=2":                                "api_key=" + Mask + "&page=2",
+		"record not found":                                 "record not found",
+		"Key: 'Widget.Name' Error:Field validation failed": "Key: 'Widget.Name' Error:Field validation failed",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, RedactText(in), in)
+	}
+}
+
+func TestRedact_NilInterface(t *testing.T) {
+	var v any
+	assert.Nil(t, RedactSecrets(v))
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	bits atomic.Uint64
+}
+
+// Inc adds one.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add adds delta, which must not be negative.
+func (c *Counter) Add(delta float64) {
+	for {
+		old := c.bits.Load()
+		if c.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Value returns the current count.
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+// CounterVec is a family of counters partitioned by labels.
+type CounterVec struct {
+	v *vec[Counter]
+}
+
+// NewCounterVec registers a counter family on r.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{v: &vec[Counter]{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		newSeries:  func() *Counter { return &Counter{} },
+		series:     map[string]*Counter{},
+		values:     map[string][]string{},
+	}}
+	r.register(c)
+	return c
+}
+
+// WithLabelValues returns the counter for the given label values, creating it if needed.
+func (c *CounterVec) WithLabelValues(values ...string) *Counter {
+	return c.v.with(values)
+}
+
+func (c *CounterVec) name() string { return c.v.name() }
+
+func (c *CounterVec) write(w io.Writer) {
+	c.v.header(w, "counter")
+	c.v.each(func(labels string, s *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.v.metricName, labels, formatFloat(s.Value()))
+	})
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+// Observe records v.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += v
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Sum returns the sum of observations.
+func (h *Histogram) Sum() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sum
+}
+
+// HistogramVec is a family of histograms partitioned by labels.
+type HistogramVec struct {
+	v       *vec[Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec registers a histogram family on r. Nil buckets use DefBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{buckets: buckets}
+	h.v = &vec[Histogram]{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		newSeries: func() *Histogram {
+			return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+		},
+		series: map[string]*Histogram{},
+		values: map[string][]string{},
+	}
+	r.register(h)
+	return h
+}
+
+// WithLabelValues returns the histogram for the given label values, creating it if needed.
+func (h *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return h.v.with(values)
+}
+
+func (h *HistogramVec) name() string { return h.v.name() }
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.v.header(w, "histogram")
+	h.v.each(func(labels string, s *Histogram) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var cumulative uint64
+		for i, bound := range s.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.v.metricName, addLabel(labels, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.v.metricName, addLabel(labels, "le", formatFloat(math.Inf(1))), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.v.metricName, labels, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.v.metricName, labels, s.count)
+	})
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_TextFormat(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests served.", "method", "code")
+	latency := r.NewHistogramVec("request_duration_seconds", "Request latency.", []float64{0.1, 1}, "method")
+
+	requests.WithLabelValues("GET", "200").Inc()
+	requests.WithLabelValues("GET", "200").Add(2)
+	requests.WithLabelValues("POST", `a"b`).Inc()
+	latency.WithLabelValues("GET").Observe(0.05)
+	latency.WithLabelValues("GET").Observe(0.5)
+	latency.WithLabelValues("GET").Observe(3)
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		"# HELP request_duration_seconds Request latency.",
+		"# TYPE request_duration_seconds histogram",
+		`request_duration_seconds_bucket{method="GET",le="0.1"} 1`,
+		`request_duration_seconds_bucket{method="GET",le="1"} 2`,
+		`request_duration_seconds_bucket{method="GET",le="+Inf"} 3`,
+		`request_duration_seconds_sum{method="GET"} 3.55`,
+		`request_duration_seconds_count{method="GET"} 3`,
+		"# HELP requests_total Requests served.",
+		"# TYPE requests_total counter",
+		`requests_total{method="GET",code="200"} 3`,
+		`requests_total{method="POST",code="a\"b"} 1`,
+		"",
+	}, "\n"), w.Body.String())
+}
+
+func TestCounter_Concurrent(t *testing.T) {
+	c := NewRegistry().NewCounterVec("hits_total", "Hits.")
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.WithLabelValues().Inc()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, float64(100), c.WithLabelValues().Value())
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("dup_total", "Dup.", "a")
+	assert.Panics(t, func() { r.NewCounterVec("dup_total", "Dup.") })
+	assert.Panics(t, func() { c.WithLabelValues("x", "y") })
+}
@@ -0,0 +1,110 @@
+package route
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Group declares routes sharing a path prefix and middleware. Groups nest: a route's
+// path is the concatenation of every enclosing prefix and its own path, and it runs
+// the middleware of every enclosing group, outermost first, before its handlers.
+type Group struct {
+	Prefix     string
+	Middleware []gin.HandlerFunc
+	Routes     []*Handler
+	Groups     []*Group
+}
+
+// JoinPath appends path to prefix with exactly one slash between them.
+func JoinPath(prefix, path string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if path == "" {
+		return prefix
+	}
+	return prefix + "/" + strings.TrimLeft(path, "/")
+}
+
+// Validate reports route declarations the router would reject at registration: the
+// same method and path declared twice, including through different group prefixes,
+// and path parameters named differently at the same position, such as GET
+// /users/:id and GET /users/:name/posts. Paths are compared relative to the common
+// root, so handlers and groups are checked together.
+func Validate(handlers []*Handler, groups []*Group) error {
+	v := validator{routes: map[string]string{}, params: map[string]string{}}
+	if err := v.routesIn("", handlers); err != nil {
+		return err
+	}
+	return v.groupsIn("", groups)
+}
+
+// validator remembers the declarations seen so far, keyed by method and path shape.
+type validator struct {
+	routes map[string]string // method + path with parameters unnamed -> path
+	params map[string]string // method + path prefix up to a parameter -> parameter
+}
+
+func (v *validator) groupsIn(prefix string, groups []*Group) error {
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+		p := JoinPath(prefix, g.Prefix)
+		if strings.ContainsAny(g.Prefix, ":*") {
+			return fmt.Errorf("group prefix %s: path parameters belong in route paths", p)
+		}
+		if err := v.routesIn(p, g.Routes); err != nil {
+			return err
+		}
+		if err := v.groupsIn(p, g.Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) routesIn(prefix string, handlers []*Handler) error {
+	for _, h := range handlers {
+		if h == nil {
+			continue
+		}
+		methods := []string{strings.ToUpper(strings.TrimSpace(h.Method))}
+		if methods[0] == MethodAny {
+			methods = AnyMethods
+		}
+		for _, method := range methods {
+			if err := v.route(method, JoinPath(prefix, h.Path)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// route records one method and path, reporting a conflict with those seen before.
+func (v *validator) route(method, path string) error {
+	segments := strings.Split(path, "/")
+	shape := make([]string, len(segments))
+	for i, seg := range segments {
+		shape[i] = seg
+		if seg == "" || (seg[0] != ':' && seg[0] != '*') {
+			continue
+		}
+
+		// gin requires every route through a position to name the parameter alike
+		key := method + " " + strings.Join(shape[:i], "/") + "/" + seg[:1]
+		if other, ok := v.params[key]; ok && other != seg {
+			return fmt.Errorf("conflicting routes: %s %s names parameter %s where another route names it %s", method, path, seg, other)
+		}
+		v.params[key] = seg
+		shape[i] = seg[:1]
+	}
+
+	key := method + " " + strings.Join(shape, "/")
+	if other, ok := v.routes[key]; ok {
+		return fmt.Errorf("conflicting routes: %s %s duplicates %s %s", method, path, method, other)
+	}
+	v.routes[key] = path
+	return nil
+}
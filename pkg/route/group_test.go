@@ -0,0 +1,76 @@
+package route
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinPath(t *testing.T) {
+	assert.Equal(t, "/users", JoinPath("", "/users"))
+	assert.Equal(t, "/admin/users", JoinPath("/admin/", "users"))
+	assert.Equal(t, "/admin/users/:id", JoinPath("/admin", "/users/:id"))
+	assert.Equal(t, "/admin", JoinPath("/admin/", ""))
+}
+
+func TestValidate(t *testing.T) {
+	get := func(path string) *Handler { return &Handler{Method: http.MethodGet, Path: path} }
+
+	tests := []struct {
+		name     string
+		handlers []*Handler
+		groups   []*Group
+		want     string
+	}{
+		{
+			name:     "distinct routes",
+			handlers: []*Handler{get("/users"), get("/users/:id"), {Method: http.MethodDelete, Path: "/users/:id"}},
+			groups: []*Group{{Prefix: "/admin", Routes: []*Handler{get("/users/:id")}, Groups: []*Group{
+				{Prefix: "/reports", Routes: []*Handler{get("/:id"), get("/daily")}},
+			}}},
+		},
+		{
+			name:     "duplicate across a group prefix",
+			handlers: []*Handler{get("/admin/users")},
+			groups:   []*Group{{Prefix: "/admin", Routes: []*Handler{{Method: "get", Path: "/users"}}}},
+			want:     "conflicting routes: GET /admin/users duplicates GET /admin/users",
+		},
+		{
+			name:     "duplicate with renamed parameter",
+			handlers: []*Handler{get("/users/:id")},
+			groups:   []*Group{{Prefix: "/users", Routes: []*Handler{get("/:name")}}},
+			want:     "conflicting routes: GET /users/:name names parameter :name where another route names it :id",
+		},
+		{
+			name:   "parameter renamed deeper in the tree",
+			groups: []*Group{{Prefix: "/users", Routes: []*Handler{get("/:id")}, Groups: []*Group{{Prefix: "/", Routes: []*Handler{get("/:userID/posts")}}}}},
+			want:   "conflicting routes: GET /users/:userID/posts names parameter :userID where another route names it :id",
+		},
+		{
+			name:     "catch-all route duplicating a method",
+			handlers: []*Handler{{Method: MethodAny, Path: "/webhooks"}, {Method: http.MethodPost, Path: "/webhooks"}},
+			want:     "conflicting routes: POST /webhooks duplicates POST /webhooks",
+		},
+		{
+			name:     "catch-all route alongside other paths",
+			handlers: []*Handler{{Method: "any", Path: "/webhooks"}, {Method: http.MethodPost, Path: "/webhooks/:id"}},
+		},
+		{
+			name:   "parameter in a group prefix",
+			groups: []*Group{{Prefix: "/users/:id", Routes: []*Handler{get("/posts")}}},
+			want:   "group prefix /users/:id: path parameters belong in route paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.handlers, tt.groups)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package route
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Phase determines where a middleware runs in the HTTP chain. Lower phases run first.
+type Phase int
+
+const (
+	PhaseRecovery  Phase = 100
+	PhaseContext   Phase = 200
+	PhaseLogging   Phase = 300
+	PhaseRateLimit Phase = 400
+	PhaseAuth      Phase = 500
+	PhaseHandler   Phase = 600
+)
+
+// Middleware declares a middleware to be installed on every HTTP route.
+// Within a phase, lower priorities run first; ties keep registration order.
+type Middleware struct {
+	Name     string
+	Phase    Phase
+	Priority int
+	Handler  gin.HandlerFunc
+}
+
+// SortMiddleware returns the middleware ordered by phase and priority.
+// The input slice is left untouched and nil entries are dropped.
+func SortMiddleware(mws []*Middleware) []*Middleware {
+	sorted := make([]*Middleware, 0, len(mws))
+	for _, mw := range mws {
+		if mw != nil && mw.Handler != nil {
+			sorted = append(sorted, mw)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Phase != sorted[j].Phase {
+			return sorted[i].Phase < sorted[j].Phase
+		}
+		return sorted[i].Priority < sorted[j].Priority
+	})
+	return sorted
+}
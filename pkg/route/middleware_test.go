@@ -0,0 +1,45 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func names(mws []*Middleware) []string {
+	out := []string{}
+	for _, mw := range mws {
+		out = append(out, mw.Name)
+	}
+	return out
+}
+
+func TestSortMiddleware_OrdersByPhaseAndPriority(t *testing.T) {
+	noop := func(c *gin.Context) {}
+	mws := []*Middleware{
+		{Name: "auth", Phase: PhaseAuth, Handler: noop},
+		{Name: "rate-limit", Phase: PhaseRateLimit, Handler: noop},
+		{Name: "audit", Phase: PhaseLogging, Priority: 10, Handler: noop},
+		{Name: "access-log", Phase: PhaseLogging, Handler: noop},
+		{Name: "recovery", Phase: PhaseRecovery, Handler: noop},
+	}
+
+	sorted := SortMiddleware(mws)
+	assert.Equal(t, []string{"recovery", "access-log", "audit", "rate-limit", "auth"}, names(sorted))
+
+	// Input is untouched
+	assert.Equal(t, "auth", mws[0].Name)
+}
+
+func TestSortMiddleware_StableAndSkipsNil(t *testing.T) {
+	noop := func(c *gin.Context) {}
+	mws := []*Middleware{
+		{Name: "first", Phase: PhaseAuth, Handler: noop},
+		nil,
+		{Name: "no-handler", Phase: PhaseAuth},
+		{Name: "second", Phase: PhaseAuth, Handler: noop},
+	}
+
+	assert.Equal(t, []string{"first", "second"}, names(SortMiddleware(mws)))
+}
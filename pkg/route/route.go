@@ -1,11 +1,41 @@
 package route
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/jsonschema"
+)
+
+// MethodAny is the Handler.Method registering a route for every method in AnyMethods.
+const MethodAny = "ANY"
+
+// AnyMethods lists the methods a MethodAny route serves, matching gin's Any.
+var AnyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodConnect,
+	http.MethodTrace,
+}
 
 // HTTPHandler defines a route that can be registered in an HTTP service.
 // It is designed for declarative, data-driven route registration across services.
 type Handler struct {
+	// Method is the HTTP verb, case-insensitive, or MethodAny for every verb.
 	Method  string
 	Path    string
 	Handler []gin.HandlerFunc
+	// MaxConcurrent caps concurrent executions of this route. Zero means unlimited.
+	MaxConcurrent int
+	// ConcurrencyWait is how long a request may queue for a free slot before it is
+	// shed with 503. Zero sheds immediately.
+	ConcurrencyWait time.Duration
+	// Schema, when set, validates the JSON request body before the handler runs.
+	Schema *jsonschema.Schema
+	// Streaming marks routes reading the body incrementally through http.StreamBody.
+	// Their request bodies are not captured by the request log.
+	Streaming bool
+	// BodyOptional lets http.ShouldBindJSON accept an empty body on this route
+	// instead of rejecting it as missing.
+	BodyOptional bool
 }
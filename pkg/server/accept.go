@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// Accept backoff bounds, matching net/http's handling of temporary accept errors.
+const (
+	minAcceptBackoff     = 5 * time.Millisecond
+	defaultAcceptBackoff = time.Second
+)
+
+// acceptBackoff waits out an accept retry delay; swapped in tests.
+var acceptBackoff = time.After
+
+// backoffListener retries temporary accept errors, such as running out of file
+// descriptors, with exponential backoff instead of letting cmux spin on them.
+// Permanent errors are returned wrapped so Run stops and shuts the server down.
+type backoffListener struct {
+	net.Listener
+	svc        *service.Service
+	maxBackoff time.Duration
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newBackoffListener(l net.Listener, svc *service.Service, maxBackoff time.Duration) *backoffListener {
+	return &backoffListener{Listener: l, svc: svc, maxBackoff: maxBackoff, closed: make(chan struct{})}
+}
+
+// Accept waits for the next connection, backing off while accept errors are temporary.
+func (l *backoffListener) Accept() (net.Conn, error) {
+	var delay time.Duration
+	for {
+		conn, err := l.Listener.Accept()
+		if err == nil {
+			return conn, nil
+		}
+
+		select {
+		case <-l.closed:
+			return nil, err
+		default:
+		}
+		if !temporaryAcceptError(err) {
+			return nil, fmt.Errorf("listener accept failed: %w", err)
+		}
+
+		if delay == 0 {
+			delay = minAcceptBackoff
+		} else if delay *= 2; delay > l.maxBackoff {
+			delay = l.maxBackoff
+		}
+		l.svc.Logger.Warn("temporary accept error, retrying in %s: %v", delay, err)
+
+		select {
+		case <-acceptBackoff(delay):
+		case <-l.closed:
+			return nil, net.ErrClosed
+		}
+	}
+}
+
+// Close stops any backoff in progress and closes the underlying listener.
+func (l *backoffListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return l.Listener.Close()
+}
+
+// temporaryAcceptError reports whether an accept error is worth retrying:
+// resource exhaustion, aborted handshakes, or errors flagged temporary.
+func temporaryAcceptError(err error) bool {
+	for _, errno := range []syscall.Errno{syscall.EMFILE, syscall.ENFILE, syscall.ENOBUFS, syscall.ENOMEM, syscall.ECONNABORTED, syscall.ECONNRESET} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+
+	var ne interface{ Temporary() bool }
+	return errors.As(err, &ne) && ne.Temporary()
+}
+
+// acceptBackoffFromEnv reads the longest accept retry delay from ACCEPT_BACKOFF_MAX.
+func acceptBackoffFromEnv(svc *service.Service) time.Duration {
+	raw := os.Getenv("ACCEPT_BACKOFF_MAX")
+	if raw == "" {
+		return defaultAcceptBackoff
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < minAcceptBackoff {
+		svc.Logger.Warn("invalid ACCEPT_BACKOFF_MAX %q, using default of %s", raw, defaultAcceptBackoff)
+		return defaultAcceptBackoff
+	}
+	return d
+}
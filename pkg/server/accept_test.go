@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tempError struct{}
+
+func (tempError) Error() string   { return "temporary failure" }
+func (tempError) Timeout() bool   { return false }
+func (tempError) Temporary() bool { return true }
+
+// stubListener returns the queued accept errors in order, then blocks until closed.
+type stubListener struct {
+	mu     sync.Mutex
+	errs   []error
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newStubListener(errs ...error) *stubListener {
+	return &stubListener{errs: errs, closed: make(chan struct{})}
+}
+
+func (l *stubListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if len(l.errs) > 0 {
+		err := l.errs[0]
+		l.errs = l.errs[1:]
+		l.mu.Unlock()
+		return nil, err
+	}
+	l.mu.Unlock()
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *stubListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *stubListener) Addr() net.Addr { return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)} }
+
+// recordBackoff replaces acceptBackoff with an immediate wait that records each delay.
+func recordBackoff(t *testing.T) *[]time.Duration {
+	var mu sync.Mutex
+	delays := []time.Duration{}
+	orig := acceptBackoff
+	acceptBackoff = func(d time.Duration) <-chan time.Time {
+		mu.Lock()
+		delays = append(delays, d)
+		mu.Unlock()
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+	t.Cleanup(func() { acceptBackoff = orig })
+	return &delays
+}
+
+func TestBackoffListener_RetriesTemporaryErrors(t *testing.T) {
+	delays := recordBackoff(t)
+	permanent := errors.New("listener broken")
+	stub := newStubListener(
+		tempError{},
+		&net.OpError{Op: "accept", Err: os.NewSyscallError("accept", syscall.EMFILE)},
+		tempError{}, tempError{}, tempError{}, tempError{}, tempError{}, tempError{}, tempError{}, tempError{},
+		permanent,
+	)
+	l := newBackoffListener(stub, newMockService(t), 500*time.Millisecond)
+
+	_, err := l.Accept()
+	assert.ErrorIs(t, err, permanent)
+	assert.Equal(t, []time.Duration{
+		5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond,
+		80 * time.Millisecond, 160 * time.Millisecond, 320 * time.Millisecond,
+		500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond,
+	}, *delays)
+}
+
+func TestBackoffListener_ResetsAfterSuccess(t *testing.T) {
+	delays := recordBackoff(t)
+	server, client := net.Pipe()
+	defer client.Close()
+
+	stub := &connListener{stubListener: newStubListener(tempError{}, tempError{}), conn: server}
+	l := newBackoffListener(stub, newMockService(t), time.Second)
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	assert.Equal(t, server, conn)
+
+	stub.errs = []error{tempError{}, errors.New("done")}
+	_, err = l.Accept()
+	assert.Error(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 5 * time.Millisecond}, *delays)
+}
+
+// connListener hands out conn once its queued errors are exhausted.
+type connListener struct {
+	*stubListener
+	conn net.Conn
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if len(l.errs) == 0 && l.conn != nil {
+		conn := l.conn
+		l.conn = nil
+		l.mu.Unlock()
+		return conn, nil
+	}
+	l.mu.Unlock()
+	return l.stubListener.Accept()
+}
+
+func TestBackoffListener_CloseStopsBackoff(t *testing.T) {
+	orig := acceptBackoff
+	acceptBackoff = func(time.Duration) <-chan time.Time { return nil }
+	t.Cleanup(func() { acceptBackoff = orig })
+
+	l := newBackoffListener(newStubListener(tempError{}), newMockService(t), time.Second)
+	errc := make(chan error, 1)
+	go func() {
+		_, err := l.Accept()
+		errc <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, l.Close())
+	select {
+	case err := <-errc:
+		assert.ErrorIs(t, err, net.ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Accept did not return after Close")
+	}
+}
+
+func TestRun_StopsOnPermanentAcceptError(t *testing.T) {
+	t.Setenv("SERVICE_PROTOCOL", "http")
+	delays := recordBackoff(t)
+	s, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	s.Listener.Close()
+
+	permanent := errors.New("listener broken")
+	s.Listener = newBackoffListener(newStubListener(tempError{}, tempError{}, permanent), s.Service, time.Second)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, permanent)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not stop after a permanent accept error")
+	}
+	assert.Len(t, *delays, 2)
+}
+
+func TestTemporaryAcceptError(t *testing.T) {
+	assert.True(t, temporaryAcceptError(tempError{}))
+	assert.True(t, temporaryAcceptError(fmt.Errorf("wrapped: %w", syscall.ENFILE)))
+	assert.True(t, temporaryAcceptError(&net.OpError{Op: "accept", Err: os.NewSyscallError("accept", syscall.ECONNABORTED)}))
+	assert.False(t, temporaryAcceptError(net.ErrClosed))
+	assert.False(t, temporaryAcceptError(errors.New("boom")))
+}
+
+func TestAcceptBackoffFromEnv(t *testing.T) {
+	svc := newMockService(t)
+
+	t.Setenv("ACCEPT_BACKOFF_MAX", "")
+	assert.Equal(t, defaultAcceptBackoff, acceptBackoffFromEnv(svc))
+
+	t.Setenv("ACCEPT_BACKOFF_MAX", "250ms")
+	assert.Equal(t, 250*time.Millisecond, acceptBackoffFromEnv(svc))
+
+	t.Setenv("ACCEPT_BACKOFF_MAX", "1ms")
+	assert.Equal(t, defaultAcceptBackoff, acceptBackoffFromEnv(svc))
+
+	t.Setenv("ACCEPT_BACKOFF_MAX", "soon")
+	assert.Equal(t, defaultAcceptBackoff, acceptBackoffFromEnv(svc))
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// Registration pairs a gRPC service implementation with the HTTP gateway exposing it,
+// so both are registered together and stay in sync.
+type Registration struct {
+	// GRPC registers the implementation on the gRPC server, e.g. pb.RegisterUsersServer.
+	GRPC func(*grpc.Server)
+	// Gateway serves the HTTP mapping of the same implementation, typically a
+	// grpc-gateway runtime.ServeMux populated with RegisterXHandlerServer.
+	Gateway http.Handler
+	// GatewayPrefix is the path the gateway is mounted under, e.g. "/v1".
+	GatewayPrefix string
+}
+
+type gatewayMount struct {
+	prefix  string
+	handler http.Handler
+}
+
+// RegisterService registers the gRPC implementation immediately and mounts its
+// gateway on the HTTP engine when Run starts serving HTTP.
+func (s *Server) RegisterService(reg Registration) error {
+	if reg.GRPC == nil {
+		return fmt.Errorf("gRPC registration is required")
+	}
+
+	if reg.Gateway != nil {
+		prefix := strings.TrimRight(reg.GatewayPrefix, "/")
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("gateway prefix %q must be a non-root absolute path", reg.GatewayPrefix)
+		}
+		if prefix == "/api" || strings.HasPrefix(prefix, "/api/") {
+			return fmt.Errorf("gateway prefix %q conflicts with the /api route group", reg.GatewayPrefix)
+		}
+		for _, g := range s.gateways {
+			if g.prefix == prefix {
+				return fmt.Errorf("gateway prefix %q is already registered", prefix)
+			}
+		}
+		s.gateways = append(s.gateways, gatewayMount{prefix: prefix, handler: reg.Gateway})
+	}
+
+	s.GRPCServer.Register(reg.GRPC)
+	return nil
+}
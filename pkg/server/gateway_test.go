@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// echoHealth is a tiny service implementation shared by gRPC and the gateway.
+type echoHealth struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls int
+}
+
+func (e *echoHealth) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	e.calls++
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+// healthGateway maps GET /gw/health onto the same implementation, standing in for grpc-gateway.
+func healthGateway(impl *echoHealth) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gw/health", func(w http.ResponseWriter, r *http.Request) {
+		resp, err := impl.Check(r.Context(), &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": resp.Status.String()})
+	})
+	return mux
+}
+
+func TestRegisterService_ReachableOverGRPCAndGateway(t *testing.T) {
+	os.Unsetenv("SERVICE_PROTOCOL")
+	s, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	// Use a separate service name to avoid clashing with the built-in health server
+	impl := &echoHealth{}
+	desc := grpc_health_v1.Health_ServiceDesc
+	desc.ServiceName = "test.EchoHealth"
+	err = s.RegisterService(Registration{
+		GRPC:          func(g *grpc.Server) { g.RegisterService(&desc, impl) },
+		Gateway:       healthGateway(impl),
+		GatewayPrefix: "/gw",
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx) }()
+
+	addr := s.Listener.Addr().String()
+
+	// gRPC
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	callCtx, callCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer callCancel()
+	out := &grpc_health_v1.HealthCheckResponse{}
+	err = conn.Invoke(callCtx, "/test.EchoHealth/Check", &grpc_health_v1.HealthCheckRequest{}, out)
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, out.Status)
+
+	// Gateway
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = http.Get("http://" + addr + "/gw/health")
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	defer resp.Body.Close()
+
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "SERVING", body["status"])
+
+	assert.Equal(t, 2, impl.calls)
+}
+
+func TestRegisterService_Validation(t *testing.T) {
+	s, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	defer s.Listener.Close()
+
+	noop := func(*grpc.Server) {}
+	assert.Error(t, s.RegisterService(Registration{}))
+	assert.Error(t, s.RegisterService(Registration{GRPC: noop, Gateway: http.NotFoundHandler(), GatewayPrefix: "/"}))
+	assert.Error(t, s.RegisterService(Registration{GRPC: noop, Gateway: http.NotFoundHandler(), GatewayPrefix: "/api/v1"}))
+	assert.NoError(t, s.RegisterService(Registration{GRPC: noop, Gateway: http.NotFoundHandler(), GatewayPrefix: "/v1"}))
+	assert.Error(t, s.RegisterService(Registration{GRPC: noop, Gateway: http.NotFoundHandler(), GatewayPrefix: "/v1/"}))
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// defaultGroupShutdownTimeout bounds the graceful shutdown Run performs on exit.
+const defaultGroupShutdownTimeout = 30 * time.Second
+
+// ServerGroup runs several Servers in one process, e.g. an internal and an
+// external one, and shuts them all down together.
+type ServerGroup struct {
+	Servers []*Server
+	// ShutdownTimeout bounds the shutdown Run performs once it stops. Defaults to 30s.
+	ShutdownTimeout time.Duration
+
+	stopping     atomic.Bool
+	cancelMu     sync.Mutex
+	cancel       context.CancelFunc
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// NewServerGroup groups the given servers.
+func NewServerGroup(servers ...*Server) *ServerGroup {
+	return &ServerGroup{Servers: servers}
+}
+
+// Run starts every server and blocks until ctx is done, SIGINT or SIGTERM is
+// received, Shutdown is called, or any server fails. It then gracefully shuts
+// down all servers and returns the failures that caused it to stop, joined with
+// any shutdown errors. Errors servers return because they were stopped are not reported.
+func (g *ServerGroup) Run(ctx context.Context) error {
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g.cancelMu.Lock()
+	g.cancel = cancel
+	g.cancelMu.Unlock()
+	if g.stopping.Load() {
+		cancel()
+	}
+
+	// Servers stop through Shutdown rather than their Run context, so they can drain
+	runCtx := context.WithoutCancel(ctx)
+	var (
+		wg      sync.WaitGroup
+		errMu   sync.Mutex
+		runErrs []error
+	)
+	for _, s := range g.Servers {
+		wg.Add(1)
+		go func(s *Server) {
+			defer wg.Done()
+			err := s.Run(runCtx)
+			if err != nil && !g.stopping.Load() {
+				errMu.Lock()
+				runErrs = append(runErrs, err)
+				errMu.Unlock()
+				cancel()
+			}
+		}(s)
+	}
+
+	<-ctx.Done()
+	timeout := g.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultGroupShutdownTimeout
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), timeout)
+	defer cancelShutdown()
+	shutdownErr := g.Shutdown(shutdownCtx)
+	wg.Wait()
+
+	errMu.Lock()
+	defer errMu.Unlock()
+	return errors.Join(append(runErrs, shutdownErr)...)
+}
+
+// Shutdown gracefully stops every server concurrently and returns their joined
+// errors. It makes a running Run return, and like Server.Shutdown it is safe to
+// call more than once; later calls return the first call's result.
+func (g *ServerGroup) Shutdown(ctx context.Context) error {
+	g.shutdownOnce.Do(func() {
+		g.stopping.Store(true)
+		g.cancelMu.Lock()
+		if g.cancel != nil {
+			g.cancel()
+		}
+		g.cancelMu.Unlock()
+
+		errs := make([]error, len(g.Servers))
+		var wg sync.WaitGroup
+		for i, s := range g.Servers {
+			wg.Add(1)
+			go func(i int, s *Server) {
+				defer wg.Done()
+				errs[i] = s.Shutdown(ctx)
+			}(i, s)
+		}
+		wg.Wait()
+		g.shutdownErr = errors.Join(errs...)
+	})
+	return g.shutdownErr
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noKeepAlive opens a fresh connection per request, so probes see whether the listener still accepts.
+var noKeepAlive = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+func newGroupServer(t *testing.T) *Server {
+	s, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	return s
+}
+
+// waitServing polls the server over HTTP until it answers.
+func waitServing(t *testing.T, s *Server) {
+	t.Helper()
+	url := fmt.Sprintf("http://%s/api/v1/missing", s.Listener.Addr())
+	require.Eventually(t, func() bool {
+		resp, err := noKeepAlive.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusNotFound
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func runGroup(g *ServerGroup, ctx context.Context) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- g.Run(ctx) }()
+	return done
+}
+
+func TestServerGroup_StartsAndShutsDownAll(t *testing.T) {
+	internal, external := newGroupServer(t), newGroupServer(t)
+	g := NewServerGroup(internal, external)
+	done := runGroup(g, context.Background())
+
+	waitServing(t, internal)
+	waitServing(t, external)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, g.Shutdown(ctx))
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after Shutdown")
+	}
+
+	for _, s := range g.Servers {
+		_, err := noKeepAlive.Get(fmt.Sprintf("http://%s/", s.Listener.Addr()))
+		assert.Error(t, err, "server should no longer accept connections")
+	}
+	assert.NoError(t, g.Shutdown(context.Background()), "later calls return the first result")
+}
+
+func TestServerGroup_StopsOnContextCancel(t *testing.T) {
+	g := NewServerGroup(newGroupServer(t), newGroupServer(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runGroup(g, ctx)
+
+	waitServing(t, g.Servers[0])
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestServerGroup_FailedServerStopsTheOthers(t *testing.T) {
+	healthy, broken := newGroupServer(t), newGroupServer(t)
+	require.NoError(t, broken.Listener.Close())
+
+	g := NewServerGroup(healthy, broken)
+	g.ShutdownTimeout = time.Second
+
+	select {
+	case err := <-runGroup(g, context.Background()):
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after a server failed")
+	}
+
+	_, err := noKeepAlive.Get(fmt.Sprintf("http://%s/", healthy.Listener.Addr()))
+	assert.Error(t, err, "the healthy server is shut down too")
+}
+
+func TestServerGroup_ShutdownBeforeRun(t *testing.T) {
+	g := NewServerGroup(newGroupServer(t))
+	require.NoError(t, g.Shutdown(context.Background()))
+
+	select {
+	case err := <-runGroup(g, context.Background()):
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return for an already shut down group")
+	}
+}
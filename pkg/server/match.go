@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/soheilhy/cmux"
+)
+
+// grpcContentType prefixes every gRPC content-type, e.g. application/grpc+proto.
+const grpcContentType = "application/grpc"
+
+// h2cEnabled reports whether H2C_ENABLED=true asks for HTTP/2 connections that are not
+// gRPC to be served by the HTTP handlers over cleartext HTTP/2 instead of being dropped.
+func h2cEnabled() bool {
+	return os.Getenv("H2C_ENABLED") == "true"
+}
+
+// logUnmatched returns a cmux error handler logging connections no protocol matched,
+// typically HTTP/2 clients that are not gRPC while H2C_ENABLED is off.
+func logUnmatched(svc *service.Service) cmux.ErrorHandler {
+	return func(err error) bool {
+		var unmatched cmux.ErrNotMatched
+		if errors.As(err, &unmatched) {
+			svc.Logger.Warn("dropping connection: %v", err)
+		}
+		return true
+	}
+}
+
+// fallthroughListener hands non-gRPC HTTP/2 connections to the HTTP server. When the
+// gRPC matcher ran first it already answered the client preface with a SETTINGS frame,
+// so the client's ACK for it is dropped: the HTTP/2 server never sent those settings
+// and would hang up on the unexpected ACK.
+type fallthroughListener struct {
+	net.Listener
+	svc       *service.Service
+	filterAck bool
+}
+
+func (l *fallthroughListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.svc.Logger.Info("serving non-gRPC HTTP/2 connection from %s over HTTP", conn.RemoteAddr())
+	if l.filterAck {
+		return &settingsAckFilterConn{Conn: conn, prefaceLeft: len(http2Preface)}, nil
+	}
+	return conn, nil
+}
+
+// http2Preface opens every HTTP/2 connection.
+const http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// HTTP/2 frame layout used by settingsAckFilterConn.
+const (
+	frameHeaderLen    = 9
+	frameTypeSettings = 0x4
+	frameFlagAck      = 0x1
+)
+
+// settingsAckFilterConn passes the client's bytes through, except for the first
+// SETTINGS ACK frame. Once it is dropped, reads go straight to the connection.
+type settingsAckFilterConn struct {
+	net.Conn
+	prefaceLeft int
+	payloadLeft int
+	header      []byte
+	pending     []byte
+	done        bool
+	err         error // returned once pending is drained
+}
+
+func (c *settingsAckFilterConn) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := c.err; err != nil && len(c.pending) == 0 {
+		c.err = nil
+		return 0, err
+	}
+
+	for len(c.pending) == 0 && !c.done {
+		buf := make([]byte, len(p))
+		n, err := c.Conn.Read(buf)
+		c.pending = c.filter(buf[:n])
+		if err != nil {
+			// Errors such as deadlines set while hijacking are not final, so they
+			// are reported once the bytes read before them have been returned
+			if len(c.pending) == 0 {
+				return 0, err
+			}
+			c.err = err
+		}
+	}
+
+	if len(c.pending) > 0 {
+		n := copy(p, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// filter returns in without the first SETTINGS ACK frame, tracking frame boundaries
+// across reads.
+func (c *settingsAckFilterConn) filter(in []byte) []byte {
+	out := make([]byte, 0, len(in))
+	for len(in) > 0 && !c.done {
+		switch {
+		case c.prefaceLeft > 0:
+			n := min(c.prefaceLeft, len(in))
+			out = append(out, in[:n]...)
+			in, c.prefaceLeft = in[n:], c.prefaceLeft-n
+		case c.payloadLeft > 0:
+			n := min(c.payloadLeft, len(in))
+			out = append(out, in[:n]...)
+			in, c.payloadLeft = in[n:], c.payloadLeft-n
+		default:
+			n := min(frameHeaderLen-len(c.header), len(in))
+			c.header = append(c.header, in[:n]...)
+			in = in[n:]
+			if len(c.header) < frameHeaderLen {
+				continue
+			}
+
+			length := int(c.header[0])<<16 | int(c.header[1])<<8 | int(c.header[2])
+			if c.header[3] == frameTypeSettings && c.header[4]&frameFlagAck != 0 && length == 0 {
+				c.done = true
+			} else {
+				out = append(out, c.header...)
+				c.payloadLeft = length
+			}
+			c.header = c.header[:0]
+		}
+	}
+	return append(out, in...)
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// h2cClient speaks cleartext HTTP/2 with prior knowledge, like a non-gRPC HTTP/2 client.
+func h2cClient() *http.Client {
+	return &http.Client{
+		Timeout: 2 * time.Second,
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+func runMixedServer(t *testing.T) string {
+	t.Setenv("SERVICE_PROTOCOL", "")
+	svc := newMockService(t)
+	svc.HTTPHandlers = []*route.Handler{{
+		Method:  http.MethodGet,
+		Path:    "/ping",
+		Handler: []gin.HandlerFunc{func(c *gin.Context) { c.String(http.StatusOK, c.Request.Proto) }},
+	}}
+	s, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = s.Run(ctx) }()
+	return s.Listener.Addr().String()
+}
+
+func TestRun_H2CReachesHTTPHandlers(t *testing.T) {
+	t.Setenv("H2C_ENABLED", "true")
+	addr := runMixedServer(t)
+	client := h2cClient()
+
+	var resp *http.Response
+	var err error
+	require.Eventually(t, func() bool {
+		resp, err = client.Get("http://" + addr + "/api/v1/ping")
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	// Further requests reuse the connection, so the filtered handshake left it healthy
+	for i := 0; i < 3; i++ {
+		resp, err = client.Get("http://" + addr + "/api/v1/ping")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// HTTP/1.1 and gRPC are unaffected
+	resp, err = http.Get("http://" + addr + "/api/v1/ping")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", string(body))
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
+
+func TestRun_H2CDisabledDropsConnection(t *testing.T) {
+	t.Setenv("H2C_ENABLED", "")
+	addr := runMixedServer(t)
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/api/v1/ping")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+
+	_, err := h2cClient().Get("http://" + addr + "/api/v1/ping")
+	assert.Error(t, err)
+}
+
+// frame encodes an HTTP/2 frame header followed by payload.
+func frame(typ, flags byte, payload []byte) []byte {
+	n := len(payload)
+	return append([]byte{byte(n >> 16), byte(n >> 8), byte(n), typ, flags, 0, 0, 0, 0}, payload...)
+}
+
+// trickleConn returns its data a few bytes per Read, splitting frames across reads.
+type trickleConn struct {
+	net.Conn
+	data []byte
+}
+
+func (c *trickleConn) Read(p []byte) (int, error) {
+	if len(c.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p[:min(len(p), 5)], c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
+
+func TestSettingsAckFilterConn_DropsFirstAck(t *testing.T) {
+	settings := frame(frameTypeSettings, 0, []byte{0, 3, 0, 0, 0, 100})
+	ack := frame(frameTypeSettings, frameFlagAck, nil)
+	headers := frame(0x1, 0x4, []byte("headers-block"))
+
+	var in bytes.Buffer
+	in.WriteString(http2Preface)
+	in.Write(settings)
+	in.Write(headers)
+	in.Write(ack)
+	in.Write(ack)
+
+	var want bytes.Buffer
+	want.WriteString(http2Preface)
+	want.Write(settings)
+	want.Write(headers)
+	want.Write(ack)
+
+	c := &settingsAckFilterConn{Conn: &trickleConn{data: in.Bytes()}, prefaceLeft: len(http2Preface)}
+	got, err := io.ReadAll(c)
+	require.NoError(t, err)
+	assert.Equal(t, want.Bytes(), got)
+}
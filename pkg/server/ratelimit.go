@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"golang.org/x/time/rate"
+)
+
+// idleClientTTL is how long a client's limiter is kept after its last connection.
+const idleClientTTL = time.Minute
+
+// rateLimitListener drops new connections from client IPs exceeding a per-IP
+// connection rate, before cmux or any protocol handling sees them.
+type rateLimitListener struct {
+	net.Listener
+	svc   *service.Service
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter   *rate.Limiter
+	lastSeen  time.Time
+	throttled bool
+}
+
+func newRateLimitListener(l net.Listener, svc *service.Service, perSecond float64, burst int) *rateLimitListener {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimitListener{
+		Listener:  l,
+		svc:       svc,
+		limit:     rate.Limit(perSecond),
+		burst:     burst,
+		clients:   map[string]*clientLimiter{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Accept returns the next connection within its client's rate, closing the rest.
+func (l *rateLimitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.allow(clientIP(conn.RemoteAddr())) {
+			return conn, nil
+		}
+		_ = conn.Close()
+	}
+}
+
+func (l *rateLimitListener) allow(ip string) bool {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleClientTTL {
+		for key, c := range l.clients {
+			if now.Sub(c.lastSeen) > idleClientTTL {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[ip]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[ip] = c
+	}
+	c.lastSeen = now
+
+	if c.limiter.AllowN(now, 1) {
+		c.throttled = false
+		return true
+	}
+
+	// Log once per burst of drops to avoid flooding logs during an attack
+	if !c.throttled {
+		c.throttled = true
+		l.svc.Logger.Warn("dropping connections from %s: exceeded %.2f connections/s", ip, float64(l.limit))
+	}
+	return false
+}
+
+func clientIP(addr net.Addr) string {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
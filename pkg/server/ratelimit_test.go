@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitListener_DropsExcessConnections(t *testing.T) {
+	t.Setenv("CONN_RATE_PER_IP", "0.01")
+	t.Setenv("CONN_BURST_PER_IP", "2")
+	svc := newMockService(t)
+	s, err := New(svc, "v1")
+	require.NoError(t, err)
+	defer s.Listener.Close()
+
+	var accepted atomic.Int32
+	go func() {
+		for {
+			c, err := s.Listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			defer c.Close()
+		}
+	}()
+
+	addr := s.Listener.Addr().String()
+	dropped := 0
+	for i := 0; i < 5; i++ {
+		c, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer c.Close()
+
+		// Dropped connections are closed by the server straight away
+		_ = c.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		if _, err := c.Read(make([]byte, 1)); err != nil && !isTimeout(err) {
+			dropped++
+		}
+	}
+
+	assert.Equal(t, int32(2), accepted.Load())
+	assert.Equal(t, 3, dropped)
+}
+
+func TestRateLimitListener_SeparateClients(t *testing.T) {
+	l := newRateLimitListener(nil, newMockService(t), 0.01, 1)
+
+	assert.True(t, l.allow("10.0.0.1"))
+	assert.False(t, l.allow("10.0.0.1"))
+	assert.True(t, l.allow("10.0.0.2"), "other clients are unaffected")
+}
+
+func TestConnRateFromEnv(t *testing.T) {
+	svc := newMockService(t)
+
+	t.Setenv("CONN_RATE_PER_IP", "")
+	perSecond, _ := connRateFromEnv(svc)
+	assert.Zero(t, perSecond)
+
+	t.Setenv("CONN_RATE_PER_IP", "5")
+	t.Setenv("CONN_BURST_PER_IP", "")
+	perSecond, burst := connRateFromEnv(svc)
+	assert.Equal(t, 5.0, perSecond)
+	assert.Equal(t, 5, burst)
+
+	t.Setenv("CONN_BURST_PER_IP", "20")
+	_, burst = connRateFromEnv(svc)
+	assert.Equal(t, 20, burst)
+
+	t.Setenv("CONN_RATE_PER_IP", "fast")
+	perSecond, _ = connRateFromEnv(svc)
+	assert.Zero(t, perSecond)
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
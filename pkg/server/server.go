@@ -2,13 +2,21 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	nethttp "net/http"
 	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ranorsolutions/svc-common-go/pkg/http"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
 	"github.com/soheilhy/cmux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
@@ -21,7 +29,17 @@ type Server struct {
 	Listener   net.Listener
 	Service    *service.Service
 	Version    string
-	cancel     context.CancelFunc
+	gateways   []gatewayMount
+
+	cancelMu sync.Mutex
+	cancel   context.CancelFunc
+	// httpServers are the HTTP servers Run started, drained by Shutdown
+	httpServers []*nethttp.Server
+	// stopping is set once Shutdown starts, so Run reports the listeners it closes as a clean exit
+	stopping atomic.Bool
+
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 // New creates a new Server instance that can run gRPC, HTTP, or both.
@@ -35,6 +53,22 @@ func New(svc *service.Service, version string, creds ...grpc.ServerOption) (*Ser
 		return nil, fmt.Errorf("failed to create listener: %w", err)
 	}
 
+	// Back off on temporary accept errors instead of spinning, and stop on permanent ones
+	listener = newBackoffListener(listener, svc, acceptBackoffFromEnv(svc))
+
+	// Drop connection floods per client IP before they reach the connection limit
+	if perSecond, burst := connRateFromEnv(svc); perSecond > 0 {
+		svc.Logger.Info("limiting new connections to %.2f/s per client IP (burst %d)", perSecond, burst)
+		listener = newRateLimitListener(listener, svc, perSecond, burst)
+	}
+
+	// Bound concurrent connections before cmux sees them; excess connections
+	// wait in the accept backlog until a slot frees up.
+	if maxConns := maxConnsFromEnv(svc); maxConns > 0 {
+		svc.Logger.Info("limiting server to %d concurrent connections", maxConns)
+		listener = netutil.LimitListener(listener, maxConns)
+	}
+
 	s := &Server{
 		Listener:   listener,
 		GRPCServer: grpcsvc.New(svc, creds...),
@@ -45,14 +79,94 @@ func New(svc *service.Service, version string, creds ...grpc.ServerOption) (*Ser
 	return s, nil
 }
 
-// Run starts serving HTTP and/or gRPC depending on SERVICE_PROTOCOL env var.
+// maxConnsFromEnv reads the concurrent connection limit from MAX_CONNS.
+// Zero (the default) disables the limit.
+func maxConnsFromEnv(svc *service.Service) int {
+	raw := os.Getenv("MAX_CONNS")
+	if raw == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		svc.Logger.Warn("invalid MAX_CONNS %q, connection limit disabled", raw)
+		return 0
+	}
+	return n
+}
+
+// connRateFromEnv reads the per-IP connection rate from CONN_RATE_PER_IP (connections
+// per second) and its burst from CONN_BURST_PER_IP. A zero rate (the default) disables it.
+func connRateFromEnv(svc *service.Service) (float64, int) {
+	raw := os.Getenv("CONN_RATE_PER_IP")
+	if raw == "" {
+		return 0, 0
+	}
+
+	perSecond, err := strconv.ParseFloat(raw, 64)
+	if err != nil || perSecond < 0 {
+		svc.Logger.Warn("invalid CONN_RATE_PER_IP %q, connection rate limiting disabled", raw)
+		return 0, 0
+	}
+
+	burst := int(perSecond)
+	if rawBurst := os.Getenv("CONN_BURST_PER_IP"); rawBurst != "" {
+		if burst, err = strconv.Atoi(rawBurst); err != nil || burst < 1 {
+			svc.Logger.Warn("invalid CONN_BURST_PER_IP %q, using the rate as burst", rawBurst)
+			burst = int(perSecond)
+		}
+	}
+	return perSecond, max(burst, 1)
+}
+
+// Run starts serving HTTP and/or gRPC depending on SERVICE_PROTOCOL env var. It
+// returns nil once Shutdown stops the server, ctx's error when ctx is done first, and
+// the failure of the first server to stop otherwise.
 func (s *Server) Run(ctx context.Context) error {
-	ctx, s.cancel = context.WithCancel(ctx)
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancelMu.Lock()
+	s.cancel = cancel
+	s.cancelMu.Unlock()
 	m := cmux.New(s.Listener)
+	m.HandleError(logUnmatched(s.Service))
 	g, ctx := errgroup.WithContext(ctx)
 
 	protocol := os.Getenv("SERVICE_PROTOCOL")
 
+	// Resume or reject the requests the previous process handed off
+	if result, err := s.Service.RestoreHandoff(ctx); err != nil {
+		s.Service.Logger.Warn("%v", err)
+	} else if n := len(result.Restored) + len(result.Rejected); n > 0 {
+		s.Service.Logger.Info("restored %d of %d handed off requests", len(result.Restored), n)
+	}
+
+	// Set up HTTP before starting anything, so a failure leaves nothing running
+	var httpService *http.HTTPService
+	var adminListener net.Listener
+	if protocol != "grpc" {
+		var err error
+		if httpService, err = http.New(s.Service, s.Version); err != nil {
+			cancel()
+			return fmt.Errorf("failed to initialize HTTP service: %w", err)
+		}
+		for _, gw := range s.gateways {
+			httpService.Mount(gw.prefix, gw.handler)
+		}
+		if h2cEnabled() {
+			httpService.Server.Handler = h2c.NewHandler(httpService.Server.Handler, &http2.Server{})
+		}
+
+		// Serve the debug endpoints on their own port, kept off the public listener
+		if httpService.AdminServer != nil {
+			if adminListener, err = net.Listen("tcp", httpService.AdminServer.Addr); err != nil {
+				cancel()
+				return fmt.Errorf("failed to create admin listener: %w", err)
+			}
+		}
+		s.trackHTTP(httpService.Server, httpService.AdminServer)
+	}
+
 	// cancel listener on context done
 	go func() {
 		<-ctx.Done()
@@ -61,10 +175,8 @@ func (s *Server) Run(ctx context.Context) error {
 	}()
 
 	if protocol != "http" {
-		grpcListener := m.MatchWithWriters(
-			cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
-			cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc+proto"),
-		)
+		// Match every gRPC content-type; the SETTINGS reply unblocks clients waiting for it
+		grpcListener := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", grpcContentType))
 		g.Go(func() error {
 			s.Service.Logger.Info("gRPC service available on %s", s.Listener.Addr().String())
 			err := s.GRPCServer.Serve(grpcListener)
@@ -75,9 +187,16 @@ func (s *Server) Run(ctx context.Context) error {
 
 	if protocol != "grpc" {
 		httpListener := m.Match(cmux.HTTP1Fast())
-		httpService, err := http.New(s.Service, s.Version)
-		if err != nil {
-			return fmt.Errorf("failed to initialize HTTP service: %w", err)
+		if adminListener != nil {
+			go func() {
+				<-ctx.Done()
+				_ = adminListener.Close()
+			}()
+			g.Go(func() error {
+				err := httpService.ServeAdmin(adminListener)
+				s.Service.Logger.Warn("admin server stopped: %v", err)
+				return err
+			})
 		}
 		g.Go(func() error {
 			s.Service.Logger.Info("HTTP service available on %s", s.Listener.Addr().String())
@@ -85,6 +204,16 @@ func (s *Server) Run(ctx context.Context) error {
 			s.Service.Logger.Warn("HTTP server stopped: %v", err)
 			return err
 		})
+
+		// Serve other HTTP/2 traffic, e.g. h2c clients, with the HTTP handlers instead of dropping it
+		if h2cEnabled() {
+			h2Listener := &fallthroughListener{Listener: m.Match(cmux.HTTP2()), svc: s.Service, filterAck: protocol != "http"}
+			g.Go(func() error {
+				err := httpService.Server.Serve(h2Listener)
+				s.Service.Logger.Warn("HTTP/2 cleartext server stopped: %v", err)
+				return err
+			})
+		}
 	}
 
 	// cmux Serve
@@ -96,21 +225,79 @@ func (s *Server) Run(ctx context.Context) error {
 	})
 
 	err := g.Wait()
-	if err != nil && ctx.Err() == nil {
+	switch {
+	case err == nil:
+		return nil
+	case s.stopping.Load() && closedListener(err):
+		return nil
+	case parent.Err() != nil && closedListener(err):
+		return parent.Err()
+	}
+	if ctx.Err() == nil {
 		s.Service.Logger.Error("server run terminated: %v", err)
 	}
 	return err
 }
 
-// Shutdown gracefully stops all services.
+// trackHTTP records servers for Shutdown to drain, skipping nil ones.
+func (s *Server) trackHTTP(servers ...*nethttp.Server) {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+	for _, srv := range servers {
+		if srv != nil {
+			s.httpServers = append(s.httpServers, srv)
+		}
+	}
+}
+
+// closedListener reports whether err is how a server reports its listener being
+// closed underneath it, rather than a failure of its own.
+func closedListener(err error) bool {
+	return errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, cmux.ErrListenerClosed) ||
+		errors.Is(err, cmux.ErrServerClosed) ||
+		errors.Is(err, nethttp.ErrServerClosed) ||
+		errors.Is(err, grpc.ErrServerStopped)
+}
+
+// Shutdown gracefully stops all services. It is safe to call more than once and
+// from multiple goroutines; later calls wait for and return the first call's result.
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.cancel != nil {
-		s.cancel()
+	s.shutdownOnce.Do(func() {
+		s.shutdownErr = s.shutdown(ctx)
+	})
+	return s.shutdownErr
+}
+
+func (s *Server) shutdown(ctx context.Context) error {
+	// Save the state of requests that will not finish before they are cut off
+	if n, err := s.Service.SaveHandoff(ctx); err != nil {
+		s.Service.Logger.Error("%v", err)
+	} else if n > 0 {
+		s.Service.Logger.Info("handed off %d in-flight requests", n)
+	}
+
+	// Drain gRPC and HTTP before closing the listeners underneath them and the
+	// connections and database their handlers use, so in-flight requests finish
+	s.stopping.Store(true)
+	stopServing := func() {
+		s.cancelMu.Lock()
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.cancelMu.Unlock()
+		_ = s.Listener.Close()
 	}
 	done := make(chan struct{})
 	go func() {
-		s.GRPCServer.GracefulStop()
-		_ = s.Listener.Close()
+		s.drain(ctx)
+		stopServing()
+		if err := s.Service.Close(); err != nil {
+			s.Service.Logger.Error("failed to close service: %v", err)
+		}
+		if s.Service.LogBatch != nil {
+			_ = s.Service.LogBatch.Close(ctx)
+		}
 		close(done)
 	}()
 	select {
@@ -118,7 +305,33 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.Service.Logger.Info("server shutdown complete")
 		return nil
 	case <-ctx.Done():
+		stopServing()
 		s.Service.Logger.Warn("server shutdown timed out")
 		return ctx.Err()
 	}
 }
+
+// drain gracefully stops the gRPC server and every HTTP server Run started, in
+// parallel, waiting for their in-flight requests until ctx is done.
+func (s *Server) drain(ctx context.Context) {
+	s.cancelMu.Lock()
+	servers := append([]*nethttp.Server(nil), s.httpServers...)
+	s.cancelMu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.GRPCServer.GracefulStop()
+	}()
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(srv *nethttp.Server) {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				s.Service.Logger.Warn("HTTP server did not drain: %v", err)
+			}
+		}(srv)
+	}
+	wg.Wait()
+}
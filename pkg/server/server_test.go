@@ -2,14 +2,25 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net"
+	nethttp "net/http"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
 	"github.com/ranorsolutions/http-common-go/pkg/log/logger"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/route"
 	"github.com/ranorsolutions/svc-common-go/pkg/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newMockService(t *testing.T) *service.Service {
@@ -103,3 +114,285 @@ func TestShutdownCompletes(t *testing.T) {
 	err := s.Shutdown(ctx)
 	assert.NoError(t, err)
 }
+
+func TestNew_MaxConnsLimitsListener(t *testing.T) {
+	t.Setenv("MAX_CONNS", "2")
+	svc := newMockService(t)
+	s, err := New(svc, "v1")
+	assert.NoError(t, err)
+	defer s.Listener.Close()
+
+	accepted := make(chan net.Conn, 3)
+	go func() {
+		for {
+			c, err := s.Listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- c
+		}
+	}()
+
+	addr := s.Listener.Addr().String()
+	for i := 0; i < 3; i++ {
+		c, err := net.Dial("tcp", addr)
+		assert.NoError(t, err)
+		defer c.Close()
+	}
+
+	// Only two connections are handed out while both slots are held
+	first := <-accepted
+	<-accepted
+	select {
+	case <-accepted:
+		t.Fatal("accepted more connections than MAX_CONNS")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Releasing a slot admits the queued connection
+	first.Close()
+	select {
+	case <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("queued connection was not accepted after a slot freed")
+	}
+}
+
+func TestMaxConnsFromEnv(t *testing.T) {
+	svc := newMockService(t)
+
+	t.Setenv("MAX_CONNS", "")
+	assert.Equal(t, 0, maxConnsFromEnv(svc))
+
+	t.Setenv("MAX_CONNS", "25")
+	assert.Equal(t, 25, maxConnsFromEnv(svc))
+
+	t.Setenv("MAX_CONNS", "many")
+	assert.Equal(t, 0, maxConnsFromEnv(svc))
+}
+
+func TestShutdown_ConcurrentCallsAreIdempotent(t *testing.T) {
+	svc := newMockService(t)
+	s, err := New(svc, "v1")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.Shutdown(ctx)
+		}()
+	}
+
+	assert.NotPanics(t, wg.Wait)
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// A later call returns the first result without stopping again
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
+
+// countingSink counts lines written to it.
+type countingSink struct{ n atomic.Int32 }
+
+func (s *countingSink) Info(string, ...interface{})  { s.n.Add(1) }
+func (s *countingSink) Warn(string, ...interface{})  { s.n.Add(1) }
+func (s *countingSink) Error(string, ...interface{}) { s.n.Add(1) }
+
+func TestShutdown_FlushesLogBatch(t *testing.T) {
+	svc := newMockService(t)
+	sink := &countingSink{}
+	svc.LogBatch = logging.NewBatchSink(sink, logging.BatchOptions{FlushInterval: time.Hour})
+	s, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		svc.LogBatch.Info("request %d", i)
+	}
+	assert.Zero(t, sink.n.Load())
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.Equal(t, int32(3), sink.n.Load())
+}
+
+// memoryHandoff hands request state off in memory, standing in for the file between processes.
+type memoryHandoff struct{ records []service.HandoffRecord }
+
+func (m *memoryHandoff) Save(_ context.Context, records []service.HandoffRecord) error {
+	m.records = records
+	return nil
+}
+
+func (m *memoryHandoff) Load(context.Context) ([]service.HandoffRecord, error) {
+	records := m.records
+	m.records = nil
+	return records, nil
+}
+
+func TestShutdown_HandsOffInFlightRequests(t *testing.T) {
+	t.Setenv("SERVICE_PROTOCOL", "http")
+	store := &memoryHandoff{}
+
+	old := newMockService(t)
+	old.Handoff = store
+	old.TrackHandoff("longpoll", "poll-1", func() any { return map[string]int{"cursor": 3} })
+	s, err := New(old, "v1")
+	require.NoError(t, err)
+	require.NoError(t, s.Shutdown(context.Background()))
+	require.Len(t, store.records, 1)
+
+	// The replacement restores the request before it starts serving
+	replacement := newMockService(t)
+	replacement.Handoff = store
+	restored := make(chan string, 1)
+	replacement.RegisterHandoffRestorer("longpoll", func(_ context.Context, rec service.HandoffRecord) error {
+		restored <- rec.Key + " " + string(rec.State)
+		return nil
+	})
+	s, err = New(replacement, "v1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	assert.Equal(t, `poll-1 {"cursor":3}`, <-restored)
+	cancel()
+	<-done
+}
+
+func TestShutdown_RunReturnsNil(t *testing.T) {
+	for _, protocol := range []string{"", "http", "grpc"} {
+		t.Run("protocol="+protocol, func(t *testing.T) {
+			t.Setenv("SERVICE_PROTOCOL", protocol)
+			s, err := New(newMockService(t), "v1")
+			require.NoError(t, err)
+
+			done := make(chan error, 1)
+			go func() { done <- s.Run(context.Background()) }()
+
+			// Wait until the server accepts connections before stopping it
+			require.Eventually(t, func() bool {
+				conn, err := net.Dial("tcp", s.Listener.Addr().String())
+				if err == nil {
+					conn.Close()
+				}
+				return err == nil
+			}, time.Second, 10*time.Millisecond)
+
+			require.NoError(t, s.Shutdown(context.Background()))
+			select {
+			case err := <-done:
+				assert.NoError(t, err, "a shutdown is not a serve failure")
+			case <-time.After(2 * time.Second):
+				t.Fatal("Run did not return after Shutdown")
+			}
+		})
+	}
+}
+
+func TestRun_ContextDoneReturnsContextError(t *testing.T) {
+	t.Setenv("SERVICE_PROTOCOL", "http")
+	s, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	assert.ErrorIs(t, s.Run(ctx), context.Canceled)
+}
+
+func TestShutdown_DrainsHTTPBeforeClosingService(t *testing.T) {
+	t.Setenv("SERVICE_PROTOCOL", "http")
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectClose()
+
+	svc := newMockService(t)
+	svc.DB = db
+	started, release := make(chan struct{}), make(chan struct{})
+	svc.HTTPHandlers = []*route.Handler{{Method: nethttp.MethodGet, Path: "/slow", Handler: []gin.HandlerFunc{func(c *gin.Context) {
+		close(started)
+		<-release
+		if err := svc.DB.PingContext(c.Request.Context()); err != nil {
+			c.String(nethttp.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Status(nethttp.StatusNoContent)
+	}}}}
+	s, err := New(svc, "v1")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	type result struct {
+		code int
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		var resp *nethttp.Response
+		err := fmt.Errorf("server never accepted the request")
+		for i := 0; i < 100 && err != nil; i++ {
+			if resp, err = nethttp.Get("http://" + s.Listener.Addr().String() + "/api/v1/slow"); err != nil {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		resp.Body.Close()
+		responses <- result{code: resp.StatusCode}
+	}()
+	select {
+	case <-started:
+	case r := <-responses:
+		t.Fatalf("request finished before reaching the handler: %+v", r)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.Shutdown(context.Background()) }()
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned with a request in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	r := <-responses
+	require.NoError(t, r.err)
+	assert.Equal(t, nethttp.StatusNoContent, r.code, "the handler ran before the database closed")
+	require.NoError(t, <-shutdown)
+	assert.NoError(t, <-done)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRun_AdminListenFailureStartsNothing(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+	_, port, err := net.SplitHostPort(taken.Addr().String())
+	require.NoError(t, err)
+	t.Setenv("ADMIN_PORT", port)
+
+	s, err := New(newMockService(t), "v1")
+	require.NoError(t, err)
+	defer s.Listener.Close()
+
+	err = s.Run(context.Background())
+	assert.ErrorContains(t, err, "failed to create admin listener")
+	assert.Eventually(t, func() bool {
+		buf := make([]byte, 1<<20)
+		return !strings.Contains(string(buf[:runtime.Stack(buf, true)]), "server.(*Server).Run.func")
+	}, time.Second, 10*time.Millisecond, "no goroutine started by Run outlives it")
+}
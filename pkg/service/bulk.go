@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ConflictStrategy controls how BulkInsert handles rows violating a unique constraint.
+type ConflictStrategy int
+
+const (
+	// ConflictError fails the insert on conflict. It allows the COPY fast path.
+	ConflictError ConflictStrategy = iota
+	// ConflictIgnore skips conflicting rows (ON CONFLICT DO NOTHING).
+	ConflictIgnore
+	// ConflictUpsert updates conflicting rows with the inserted values (ON CONFLICT DO UPDATE).
+	ConflictUpsert
+)
+
+// defaultBulkBatchSize is the number of rows per multi-row INSERT when not configured.
+const defaultBulkBatchSize = 500
+
+// maxBulkParams is the Postgres limit on bind parameters per statement.
+const maxBulkParams = 65535
+
+// BulkInsertOptions configures BulkInsert.
+type BulkInsertOptions struct {
+	// OnConflict selects the conflict strategy. Anything but ConflictError uses batched INSERTs.
+	OnConflict ConflictStrategy
+	// ConflictColumns is the conflict target; required for ConflictUpsert.
+	ConflictColumns []string
+	// BatchSize is the number of rows per INSERT statement. Defaults to 500.
+	BatchSize int
+	// DisableCopy forces batched INSERTs even when COPY could be used.
+	DisableCopy bool
+}
+
+// BulkInsert inserts rows into table and returns the number of rows inserted.
+// Without a conflict strategy it streams rows with COPY; otherwise it issues batched
+// multi-row INSERTs. All rows are written in a single transaction, joining the
+// ambient one when ctx carries it.
+func (s *Service) BulkInsert(ctx context.Context, table string, columns []string, rows [][]any, opts ...BulkInsertOptions) (int64, error) {
+	opt := BulkInsertOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if table == "" || len(columns) == 0 {
+		return 0, fmt.Errorf("table and columns are required")
+	}
+	if opt.OnConflict == ConflictUpsert && len(opt.ConflictColumns) == 0 {
+		return 0, fmt.Errorf("conflict columns are required for upsert")
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return 0, fmt.Errorf("row %d has %d values, expected %d", i, len(row), len(columns))
+		}
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var inserted int64
+	err := s.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if opt.OnConflict == ConflictError && !opt.DisableCopy {
+			inserted, err = s.copyRows(ctx, table, columns, rows)
+		} else {
+			inserted, err = s.insertBatches(ctx, table, columns, rows, opt)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+func (s *Service) copyRows(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	var stmtSQL string
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		stmtSQL = pq.CopyInSchema(schema, name, columns...)
+	} else {
+		stmtSQL = pq.CopyIn(table, columns...)
+	}
+
+	stmt, err := s.DBFromContext(ctx).PrepareContext(ctx, stmtSQL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare COPY into %s: %w", table, err)
+	}
+	defer stmt.Close()
+
+	for i, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return 0, fmt.Errorf("failed to copy row %d into %s: %w", i, table, err)
+		}
+	}
+
+	// An argument-less Exec flushes the buffered rows
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to flush COPY into %s: %w", table, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return int64(len(rows)), nil
+	}
+	return n, nil
+}
+
+func (s *Service) insertBatches(ctx context.Context, table string, columns []string, rows [][]any, opt BulkInsertOptions) (int64, error) {
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBulkBatchSize
+	}
+	if batchSize*len(columns) > maxBulkParams {
+		batchSize = maxBulkParams / len(columns)
+	}
+
+	var inserted int64
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		query, args := buildInsert(table, columns, rows[start:end], opt)
+		res, err := s.DBFromContext(ctx).ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert rows %d-%d into %s: %w", start, end-1, table, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			inserted += n
+		}
+	}
+	return inserted, nil
+}
+
+// buildInsert renders a multi-row INSERT with the configured conflict clause.
+func buildInsert(table string, columns []string, rows [][]any, opt BulkInsertOptions) (string, []any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", quoteTable(table), quoteIdentifiers(columns))
+
+	args := make([]any, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j, v := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, v)
+			fmt.Fprintf(&b, "$%d", len(args))
+		}
+		b.WriteByte(')')
+	}
+
+	switch opt.OnConflict {
+	case ConflictIgnore:
+		if len(opt.ConflictColumns) > 0 {
+			fmt.Fprintf(&b, " ON CONFLICT (%s)", quoteIdentifiers(opt.ConflictColumns))
+		} else {
+			b.WriteString(" ON CONFLICT")
+		}
+		b.WriteString(" DO NOTHING")
+	case ConflictUpsert:
+		fmt.Fprintf(&b, " ON CONFLICT (%s) DO UPDATE SET ", quoteIdentifiers(opt.ConflictColumns))
+		updates := []string{}
+		for _, col := range columns {
+			if !contains(opt.ConflictColumns, col) {
+				q := pq.QuoteIdentifier(col)
+				updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", q, q))
+			}
+		}
+		if len(updates) == 0 {
+			// Every column is part of the conflict target; nothing to update
+			q := pq.QuoteIdentifier(columns[0])
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", q, q))
+		}
+		b.WriteString(strings.Join(updates, ", "))
+	}
+
+	return b.String(), args
+}
+
+func quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = pq.QuoteIdentifier(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = pq.QuoteIdentifier(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkInsert_Copy(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta(`COPY "users" ("id", "name") FROM STDIN`))
+	prep.ExpectExec().WithArgs(1, "ada").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs(2, "grace").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	n, err := svc.BulkInsert(context.Background(), "users", []string{"id", "name"}, [][]any{{1, "ada"}, {2, "grace"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsert_BatchedWithIgnore(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "app"."users" ("id", "name") VALUES ($1, $2), ($3, $4) ON CONFLICT ("id") DO NOTHING`)).
+		WithArgs(1, "ada", 2, "grace").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "app"."users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO NOTHING`)).
+		WithArgs(3, "linus").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := svc.BulkInsert(context.Background(), "app.users", []string{"id", "name"},
+		[][]any{{1, "ada"}, {2, "grace"}, {3, "linus"}},
+		BulkInsertOptions{OnConflict: ConflictIgnore, ConflictColumns: []string{"id"}, BatchSize: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n, "ignored conflicts are not counted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsert_Upsert(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "users" ("id", "name", "email") VALUES ($1, $2, $3) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "email" = EXCLUDED."email"`)).
+		WithArgs(1, "ada", "ada@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := svc.BulkInsert(context.Background(), "users", []string{"id", "name", "email"},
+		[][]any{{1, "ada", "ada@example.com"}},
+		BulkInsertOptions{OnConflict: ConflictUpsert, ConflictColumns: []string{"id"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsert_RollsBackOnFailure(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO").WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	n, err := svc.BulkInsert(context.Background(), "users", []string{"id"}, [][]any{{1}, {2}},
+		BulkInsertOptions{DisableCopy: true, BatchSize: 1})
+
+	assert.ErrorContains(t, err, "disk full")
+	assert.Equal(t, int64(0), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsert_Validation(t *testing.T) {
+	svc, _ := newSQLMockService(t)
+	ctx := context.Background()
+
+	_, err := svc.BulkInsert(ctx, "", []string{"id"}, [][]any{{1}})
+	assert.Error(t, err)
+
+	_, err = svc.BulkInsert(ctx, "users", []string{"id", "name"}, [][]any{{1}})
+	assert.Error(t, err)
+
+	_, err = svc.BulkInsert(ctx, "users", []string{"id"}, [][]any{{1}}, BulkInsertOptions{OnConflict: ConflictUpsert})
+	assert.Error(t, err)
+
+	n, err := svc.BulkInsert(ctx, "users", []string{"id"}, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestBulkInsert_CapsBatchToParamLimit(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+	columns := make([]string, 1000)
+	row := make([]any, 1000)
+	for i := range columns {
+		columns[i] = "c" + string(rune('a'+i%26))
+		row[i] = i
+	}
+
+	// 1000 columns allow at most 65 rows per statement, so 100 rows take two statements
+	rows := make([][]any, 100)
+	for i := range rows {
+		rows[i] = row
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(0, 65))
+	mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(0, 35))
+	mock.ExpectCommit()
+
+	n, err := svc.BulkInsert(context.Background(), "wide", columns, rows, BulkInsertOptions{DisableCopy: true, BatchSize: 100})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
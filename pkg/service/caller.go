@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// CallerMetadataKey is the outgoing metadata key identifying the calling service.
+const CallerMetadataKey = "x-caller-service"
+
+// CallerUnaryClientInterceptor tags every outgoing unary call with the local service name.
+func CallerUnaryClientInterceptor(name string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withCaller(ctx, name), method, req, reply, cc, opts...)
+	}
+}
+
+// CallerStreamClientInterceptor tags every outgoing stream with the local service name.
+func CallerStreamClientInterceptor(name string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withCaller(ctx, name), desc, cc, method, opts...)
+	}
+}
+
+func withCaller(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, CallerMetadataKey, name)
+}
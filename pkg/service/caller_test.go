@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestCallerUnaryClientInterceptor(t *testing.T) {
+	var got metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	err := CallerUnaryClientInterceptor("billing")(context.Background(), "/users.Users/Get", nil, nil, nil, invoker)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"billing"}, got.Get(CallerMetadataKey))
+}
+
+func TestCallerUnaryClientInterceptor_EmptyName(t *testing.T) {
+	var got metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	err := CallerUnaryClientInterceptor("")(context.Background(), "/users.Users/Get", nil, nil, nil, invoker)
+	assert.NoError(t, err)
+	assert.Empty(t, got.Get(CallerMetadataKey))
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	logs "github.com/ranorsolutions/http-common-go/pkg/log/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Config describes a service for NewWithConfig, so services can be built without
+// going through the process environment. New derives one from the environment.
+type Config struct {
+	// Name and Version identify the service in logs and calls to its dependencies.
+	Name    string
+	Version string
+	// Port is the port the servers listen on. Defaults to 4000.
+	Port string
+	// TerminalLogs writes human-readable logs instead of JSON.
+	TerminalLogs bool
+	// DB holds the database connection parameters. It is required unless NoDB is set.
+	DB *postgres.Connection
+	// NoDB runs the service without a database.
+	NoDB bool
+	// DBConnectRetries is how often a failed database connection is retried, with
+	// exponential backoff starting at DBConnectBackoff (default 1s).
+	DBConnectRetries int
+	DBConnectBackoff time.Duration
+	// Deps are the gRPC dependencies to create connections for.
+	Deps []ServiceDep
+	// GRPCCredential secures the dependency connections. Defaults to insecure.
+	GRPCCredential credentials.TransportCredentials
+	// BlockingDial waits for every dependency to connect, failing once DialTimeout
+	// (default 10s) passes for any of them.
+	BlockingDial bool
+	DialTimeout  time.Duration
+	// LogBatch batches request-scoped logs; see Service.LogBatch.
+	LogBatch bool
+	// PanicTable is the table recovered panics are persisted to; see Service.PanicSink.
+	PanicTable string
+	// HandoffFile is the file in-flight request state is handed off through; see Service.Handoff.
+	HandoffFile string
+}
+
+// NewWithConfig creates a service from cfg without reading the environment.
+func NewWithConfig(cfg Config) (*Service, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid service config: %w", err)
+	}
+
+	logger, err := logs.New(cfg.Name, cfg.Version, !cfg.TerminalLogs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create service logger: %w", err)
+	}
+
+	creds := cfg.GRPCCredential
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	return newService(cfg, logger, []grpc.DialOption{grpc.WithTransportCredentials(creds)})
+}
+
+// validate reports configuration New would otherwise fail on later or misuse.
+func (cfg Config) validate() error {
+	if cfg.Name == "" || cfg.Version == "" {
+		return fmt.Errorf("name and version are required")
+	}
+	if !cfg.NoDB && cfg.DB == nil {
+		return fmt.Errorf("database connection parameters are required unless NoDB is set")
+	}
+
+	seen := map[string]bool{}
+	for _, dep := range cfg.Deps {
+		if dep.Name == "" || len(dep.Addrs) == 0 {
+			return fmt.Errorf("dependencies need a name and at least one address")
+		}
+		if seen[dep.Name] {
+			return fmt.Errorf("duplicate dependency %q", dep.Name)
+		}
+		seen[dep.Name] = true
+	}
+	return nil
+}
+
+// configFromEnv builds the Config New uses from the environment, warning through
+// logger about invalid optional settings.
+func configFromEnv(logger *logs.Logger) (Config, error) {
+	deps, err := parseServiceDeps(os.Getenv("SERVICE_DEPS"), loadBalanceDeps())
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := Config{
+		Name:         os.Getenv("SERVICE"),
+		Version:      os.Getenv("VERSION"),
+		Port:         os.Getenv("PORT"),
+		TerminalLogs: os.Getenv("IS_TERMINAL") == "true",
+		NoDB:         noDatabase(),
+		Deps:         deps,
+		LogBatch:     logBatchEnabled(),
+		PanicTable:   panicTable(),
+		DialTimeout:  dialTimeoutFromEnv(logger),
+		HandoffFile:  handoffFile(),
+	}
+	if !cfg.NoDB {
+		cfg.DB = postgres.GetURIFromEnv()
+		cfg.DBConnectRetries, cfg.DBConnectBackoff = connectRetriesFromEnv(logger)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestNewWithConfig(t *testing.T) {
+	db := &sql.DB{}
+	origConnect := connectPostgres
+	var connected *postgres.Connection
+	connectPostgres = func(conn *postgres.Connection) (*sql.DB, error) {
+		connected = conn
+		return db, nil
+	}
+	defer func() { connectPostgres = origConnect }()
+
+	var targets []string
+	origDial := dialGRPC
+	dialGRPC = func(target string, _ ...grpc.DialOption) (*grpc.ClientConn, error) {
+		targets = append(targets, target)
+		return new(grpc.ClientConn), nil
+	}
+	defer func() { dialGRPC = origDial }()
+
+	conn := &postgres.Connection{}
+	svc, err := NewWithConfig(Config{
+		Name:       "orders",
+		Version:    "2.0.0",
+		Port:       "9090",
+		DB:         conn,
+		Deps:       []ServiceDep{{Name: "users", Addrs: []string{"users:5000"}}, {Name: "billing", Addrs: []string{"billing-a:5000", "billing-b:5000"}}},
+		LogBatch:   true,
+		PanicTable: "panics",
+	})
+	require.NoError(t, err)
+
+	assert.Same(t, conn, connected)
+	assert.Same(t, db, svc.DB)
+	assert.Equal(t, "9090", svc.Port)
+	assert.Equal(t, "orders", svc.Name)
+	assert.Equal(t, "2.0.0", svc.Version)
+	require.NotNil(t, svc.Logger)
+	assert.Equal(t, []string{"users:5000", depsResolverScheme + ":///billing"}, targets)
+	assert.Len(t, svc.ServiceConnections, 2)
+	assert.Contains(t, svc.HealthChecks(), ConnectionsHealthCheck)
+	assert.NotNil(t, svc.LogBatch)
+	assert.IsType(t, &DBPanicSink{}, svc.PanicSink)
+}
+
+func TestNewWithConfig_Defaults(t *testing.T) {
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) {
+		t.Fatal("connectPostgres must not be called with NoDB")
+		return nil, errors.New("unreachable")
+	}
+	defer func() { connectPostgres = origConnect }()
+
+	svc, err := NewWithConfig(Config{Name: "orders", Version: "2.0.0", NoDB: true})
+	require.NoError(t, err)
+	assert.Nil(t, svc.DB)
+	assert.Equal(t, "4000", svc.Port)
+	assert.Empty(t, svc.ServiceConnections)
+	assert.Nil(t, svc.LogBatch)
+	assert.Nil(t, svc.PanicSink)
+}
+
+func TestNewWithConfig_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"missing identity", Config{NoDB: true}, "invalid service config: name and version are required"},
+		{"missing database", Config{Name: "orders", Version: "1"}, "invalid service config: database connection parameters are required unless NoDB is set"},
+		{"dependency without address", Config{Name: "orders", Version: "1", NoDB: true, Deps: []ServiceDep{{Name: "users"}}},
+			"invalid service config: dependencies need a name and at least one address"},
+		{"duplicate dependency", Config{Name: "orders", Version: "1", NoDB: true, Deps: []ServiceDep{
+			{Name: "users", Addrs: []string{"a:1"}}, {Name: "users", Addrs: []string{"b:1"}},
+		}}, `invalid service config: duplicate dependency "users"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWithConfig(tt.cfg)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
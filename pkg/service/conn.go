@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	logs "github.com/ranorsolutions/http-common-go/pkg/log/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ConnectionsHealthCheck is the name of the readiness check covering ServiceConnections.
+const ConnectionsHealthCheck = "grpc-deps"
+
+// defaultDialTimeout bounds blocking dependency dials when not configured.
+const defaultDialTimeout = 10 * time.Second
+
+// dialTimeoutFromEnv reads how long New waits for each dependency when dialing
+// blocks from SERVICE_DEP_DIAL_TIMEOUT (default 10s).
+func dialTimeoutFromEnv(logger *logs.Logger) time.Duration {
+	raw := os.Getenv("SERVICE_DEP_DIAL_TIMEOUT")
+	if raw == "" {
+		return defaultDialTimeout
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		logger.Warn("invalid SERVICE_DEP_DIAL_TIMEOUT %q, using default of %s", raw, defaultDialTimeout)
+		return defaultDialTimeout
+	}
+	return d
+}
+
+// dialBlocking creates a client for target and waits up to timeout for it to
+// connect, closing it when it does not.
+func dialBlocking(target string, timeout time.Duration, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	conn, err := dialGRPC(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := waitReady(ctx, conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("not ready after %s: %w", timeout, err)
+	}
+	return conn, nil
+}
+
+// newClient creates a client without blocking on connection establishment, so only
+// invalid targets or options fail here; connection failures surface on RPCs or via
+// WaitForConnections. This is the contract of grpc.NewClient, which the pinned gRPC
+// release predates; grpc.Dial without WithBlock provides it there.
+func newClient(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.Dial(target, opts...)
+}
+
+// WaitForConnections triggers connection establishment on every service connection
+// and blocks until all of them are ready or ctx is done. Because client creation does
+// not wait for connectivity, this is where unreachable dependencies surface.
+func (s *Service) WaitForConnections(ctx context.Context) error {
+	s.connMu.Lock()
+	conns := make(map[string]*grpc.ClientConn, len(s.ServiceConnections))
+	names := make([]string, 0, len(s.ServiceConnections))
+	for name, conn := range s.ServiceConnections {
+		conns[name] = conn
+		names = append(names, name)
+	}
+	s.connMu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := waitReady(ctx, conns[name]); err != nil {
+			return fmt.Errorf("service %s not ready: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// waitReady connects conn and waits for it to reach the Ready state.
+func waitReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("connection is shut down")
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection %s: %w", state, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestNewClient_InvalidOptionsFailImmediately(t *testing.T) {
+	// Missing transport credentials is a configuration error surfaced at creation
+	_, err := newClient("localhost:5001")
+	require.Error(t, err)
+}
+
+func TestNewClient_DoesNotBlockOnUnreachableTarget(t *testing.T) {
+	start := time.Now()
+	conn, err := newClient("bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return nil, errors.New("unreachable")
+		}),
+	)
+	require.NoError(t, err, "unreachable targets must not fail client creation")
+	defer conn.Close()
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNew_DialErrorFromRealClient(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", "auth@localhost:5001")
+
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return newMockDB(t), nil }
+	defer func() { connectPostgres = origConnect }()
+
+	// Dropping every option leaves the real client without transport security
+	origDial := dialGRPC
+	dialGRPC = func(target string, _ ...grpc.DialOption) (*grpc.ClientConn, error) { return newClient(target) }
+	defer func() { dialGRPC = origDial }()
+
+	_, err := New()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to dial auth")
+}
+
+func TestWaitForConnections(t *testing.T) {
+	l := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	ready, err := newClient("bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+	)
+	require.NoError(t, err)
+	defer ready.Close()
+
+	svc := &Service{ServiceConnections: map[string]*grpc.ClientConn{"users": ready}}
+	svc.RegisterHealthCheck(ConnectionsHealthCheck, svc.WaitForConnections)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, svc.HealthChecks()[ConnectionsHealthCheck](ctx))
+
+	down, err := newClient("bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return nil, errors.New("unreachable") }),
+	)
+	require.NoError(t, err)
+	defer down.Close()
+	svc.ServiceConnections["billing"] = down
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = svc.WaitForConnections(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service billing not ready")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNew_RegistersConnectionsHealthCheck(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", "auth@localhost:5001")
+
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+	defer func() { connectPostgres = origConnect }()
+
+	origDial := dialGRPC
+	dialGRPC = func(_ string, _ ...grpc.DialOption) (*grpc.ClientConn, error) { return new(grpc.ClientConn), nil }
+	defer func() { dialGRPC = origDial }()
+
+	svc, err := New()
+	require.NoError(t, err)
+	assert.Contains(t, svc.HealthChecks(), ConnectionsHealthCheck)
+
+	t.Setenv("SERVICE_DEPS", "")
+	svc, err = New()
+	require.NoError(t, err)
+	assert.NotContains(t, svc.HealthChecks(), ConnectionsHealthCheck)
+}
+
+func TestNew_BlockingDial(t *testing.T) {
+	l := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", "users@bufnet,billing@billing:5000")
+	t.Setenv("SERVICE_DEP_DIAL_TIMEOUT", "50ms")
+
+	origConnect, origDial := connectPostgres, dialGRPC
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return newMockDB(t), nil }
+	defer func() { connectPostgres, dialGRPC = origConnect, origDial }()
+
+	// billing's dialer hangs, as an unreachable host blackholing packets would
+	hang := make(chan struct{})
+	defer close(hang)
+	dialGRPC = func(addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		return newClient(addr, append(opts, grpc.WithContextDialer(func(ctx context.Context, target string) (net.Conn, error) {
+			if target == "bufnet" {
+				return l.DialContext(ctx)
+			}
+			select {
+			case <-hang:
+			case <-ctx.Done():
+			}
+			return nil, errors.New("unreachable")
+		}))...)
+	}
+
+	opts := ServiceOption{GRPCCredential: insecure.NewCredentials(), BlockingDial: true}
+	start := time.Now()
+	_, err := New(opts)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Contains(t, err.Error(), "failed to dial billing at billing:5000: not ready after 50ms")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Reachable dependencies are connected before New returns
+	t.Setenv("SERVICE_DEPS", "users@bufnet")
+	svc, err := New(opts)
+	require.NoError(t, err)
+	conn, _ := svc.Connection("users")
+	assert.Equal(t, connectivity.Ready, conn.GetState())
+	assert.NoError(t, svc.CloseDependency("users"))
+
+	// Without BlockingDial the hanging dependency does not hold up New
+	t.Setenv("SERVICE_DEPS", "billing@billing:5000")
+	svc, err = New(ServiceOption{GRPCCredential: insecure.NewCredentials()})
+	require.NoError(t, err)
+	assert.NoError(t, svc.CloseDependency("billing"))
+}
+
+func TestNew_OptionsWithoutCredentialDialInsecurely(t *testing.T) {
+	l := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", "users@bufnet")
+	t.Setenv("SERVICE_DEP_DIAL_TIMEOUT", "1s")
+
+	origConnect, origDial := connectPostgres, dialGRPC
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return newMockDB(t), nil }
+	dialGRPC = func(addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		return newClient(addr, append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}))...)
+	}
+	defer func() { connectPostgres, dialGRPC = origConnect, origDial }()
+
+	svc, err := New(ServiceOption{BlockingDial: true})
+	require.NoError(t, err)
+	conn, _ := svc.Connection("users")
+	assert.Equal(t, connectivity.Ready, conn.GetState())
+	assert.NoError(t, svc.CloseDependency("users"))
+}
+
+func TestDialTimeoutFromEnv(t *testing.T) {
+	logger := NewMock().Logger
+	assert.Equal(t, defaultDialTimeout, dialTimeoutFromEnv(logger))
+
+	t.Setenv("SERVICE_DEP_DIAL_TIMEOUT", "250ms")
+	assert.Equal(t, 250*time.Millisecond, dialTimeoutFromEnv(logger))
+
+	t.Setenv("SERVICE_DEP_DIAL_TIMEOUT", "soon")
+	assert.Equal(t, defaultDialTimeout, dialTimeoutFromEnv(logger))
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	logs "github.com/ranorsolutions/http-common-go/pkg/log/logger"
+)
+
+// Database connection retry defaults.
+const (
+	defaultConnectBackoff = time.Second
+	maxConnectBackoff     = 30 * time.Second
+)
+
+// connectSleep waits between database connection attempts; swapped in tests.
+var connectSleep = time.Sleep
+
+// connectRetriesFromEnv reads how often a failed database connection is retried from
+// DB_CONNECT_RETRIES (default 0), and the first delay from DB_CONNECT_BACKOFF (default 1s).
+func connectRetriesFromEnv(logger *logs.Logger) (int, time.Duration) {
+	retries := 0
+	if raw := os.Getenv("DB_CONNECT_RETRIES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			logger.Warn("invalid DB_CONNECT_RETRIES %q, connecting without retries", raw)
+		} else {
+			retries = n
+		}
+	}
+
+	backoff := defaultConnectBackoff
+	if raw := os.Getenv("DB_CONNECT_BACKOFF"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			logger.Warn("invalid DB_CONNECT_BACKOFF %q, using default of %s", raw, defaultConnectBackoff)
+		} else {
+			backoff = d
+		}
+	}
+	return retries, backoff
+}
+
+// connectWithRetry connects to the database, retrying up to retries times with
+// exponential backoff from backoff, capped at 30s, so a database that is still
+// starting does not fail the service.
+func connectWithRetry(logger *logs.Logger, conn *postgres.Connection, retries int, backoff time.Duration) (*sql.DB, error) {
+	attempts := retries + 1
+	for attempt := 1; ; attempt++ {
+		db, err := connectPostgres(conn)
+		if err == nil {
+			return db, nil
+		}
+		if attempt == attempts {
+			if attempts > 1 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+			}
+			return nil, err
+		}
+
+		logger.Warn("database connection attempt %d/%d failed, retrying in %s: %v", attempt, attempts, backoff, err)
+		connectSleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/ranorsolutions/http-common-go/pkg/log/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingConnect fails the first failures attempts, then succeeds.
+func failingConnect(t *testing.T, failures int) *int {
+	attempts := 0
+	orig := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) {
+		attempts++
+		if attempts <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return &sql.DB{}, nil
+	}
+	t.Cleanup(func() { connectPostgres = orig })
+	return &attempts
+}
+
+func recordSleeps(t *testing.T) *[]time.Duration {
+	sleeps := []time.Duration{}
+	orig := connectSleep
+	connectSleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	t.Cleanup(func() { connectSleep = orig })
+	return &sleeps
+}
+
+func TestNew_RetriesDatabaseConnection(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("DB_CONNECT_RETRIES", "5")
+	t.Setenv("DB_CONNECT_BACKOFF", "100ms")
+	attempts := failingConnect(t, 3)
+	sleeps := recordSleeps(t)
+
+	svc, err := New()
+	require.NoError(t, err)
+	assert.NotNil(t, svc.DB)
+	assert.Equal(t, 4, *attempts)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}, *sleeps)
+}
+
+func TestNew_GivesUpAfterRetries(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("DB_CONNECT_RETRIES", "2")
+	attempts := failingConnect(t, 10)
+	sleeps := recordSleeps(t)
+
+	_, err := New()
+	assert.ErrorContains(t, err, "failed to create db connection: giving up after 3 attempts: connection refused")
+	assert.Equal(t, 3, *attempts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *sleeps)
+}
+
+func TestNew_NoRetriesByDefault(t *testing.T) {
+	setMinimalEnv(t)
+	attempts := failingConnect(t, 1)
+	sleeps := recordSleeps(t)
+
+	_, err := New()
+	assert.EqualError(t, err, "failed to create db connection: connection refused")
+	assert.Equal(t, 1, *attempts)
+	assert.Empty(t, *sleeps)
+}
+
+func TestConnectWithRetry_CapsBackoff(t *testing.T) {
+	log, err := logger.New("test", "1.0.0", true)
+	require.NoError(t, err)
+	failingConnect(t, 4)
+	sleeps := recordSleeps(t)
+
+	_, err = connectWithRetry(log, &postgres.Connection{}, 4, 20*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{20 * time.Second, 30 * time.Second, 30 * time.Second, 30 * time.Second}, *sleeps)
+}
+
+func TestConnectRetriesFromEnv(t *testing.T) {
+	log, err := logger.New("test", "1.0.0", true)
+	require.NoError(t, err)
+
+	t.Setenv("DB_CONNECT_RETRIES", "")
+	t.Setenv("DB_CONNECT_BACKOFF", "")
+	retries, backoff := connectRetriesFromEnv(log)
+	assert.Equal(t, 0, retries)
+	assert.Equal(t, defaultConnectBackoff, backoff)
+
+	t.Setenv("DB_CONNECT_RETRIES", "-1")
+	t.Setenv("DB_CONNECT_BACKOFF", "soon")
+	retries, backoff = connectRetriesFromEnv(log)
+	assert.Equal(t, 0, retries)
+	assert.Equal(t, defaultConnectBackoff, backoff)
+}
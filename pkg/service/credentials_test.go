@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testPKI holds a self-signed CA and server and client certificates issued by it.
+type testPKI struct {
+	caFile, certFile, keyFile string
+	ca                        *x509.CertPool
+	server                    tls.Certificate
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	dir := t.TempDir()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	pki := testPKI{
+		caFile:   filepath.Join(dir, "ca.crt"),
+		certFile: filepath.Join(dir, "client.crt"),
+		keyFile:  filepath.Join(dir, "client.key"),
+		ca:       x509.NewCertPool(),
+	}
+	pki.ca.AddCert(caCert)
+	require.NoError(t, os.WriteFile(pki.caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+
+	clientCert, clientKey := issue(2, "client", x509.ExtKeyUsageClientAuth)
+	require.NoError(t, os.WriteFile(pki.certFile, clientCert, 0o600))
+	require.NoError(t, os.WriteFile(pki.keyFile, clientKey, 0o600))
+
+	serverCert, serverKey := issue(3, "localhost", x509.ExtKeyUsageServerAuth)
+	pki.server, err = tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	return pki
+}
+
+func TestMTLSCredential_Handshake(t *testing.T) {
+	pki := newTestPKI(t)
+
+	// The server only accepts clients presenting a certificate issued by the test CA
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pki.ca,
+	})))
+	grpc_health_v1.RegisterHealthServer(srv, &countingHealth{})
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	creds, err := MTLSCredential(pki.certFile, pki.keyFile, pki.caFile)
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	conn, err := newClient("localhost:"+port, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
+
+func TestMTLSCredential_Errors(t *testing.T) {
+	pki := newTestPKI(t)
+	garbage := filepath.Join(t.TempDir(), "garbage.pem")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a certificate"), 0o600))
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name                      string
+		certFile, keyFile, caFile string
+		want                      string
+	}{
+		{"empty path", pki.certFile, "", pki.caFile, "mTLS requires a certificate, key and CA file"},
+		{"missing certificate", missing, pki.keyFile, pki.caFile, "failed to load client certificate " + missing},
+		{"invalid key", pki.certFile, garbage, pki.caFile, "failed to load client certificate " + pki.certFile + " with key " + garbage},
+		{"missing CA", pki.certFile, pki.keyFile, missing, "failed to read CA file: open " + missing},
+		{"invalid CA", pki.certFile, pki.keyFile, garbage, "CA file " + garbage + " contains no valid PEM certificates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := MTLSCredential(tt.certFile, tt.keyFile, tt.caFile)
+			assert.Nil(t, creds)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
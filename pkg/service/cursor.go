@@ -0,0 +1,213 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SortKey is one column of a (possibly composite) keyset pagination key. Column may
+// be qualified, e.g. "u.created_at", to match Query; the page is sorted by the
+// column of that name in its result.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// CursorQuery describes a keyset-paginated query. Query is a base SELECT using
+// $1..$n placeholders for Args; it is wrapped as a subquery so it may contain
+// its own WHERE clause. Every Keys column must be selected by Query, and the keys
+// together must be unique so pages neither skip nor repeat rows.
+type CursorQuery struct {
+	Query  string
+	Args   []any
+	Keys   []SortKey
+	Cursor []any
+	Limit  int
+}
+
+// Page is a single page of results and the cursor for the next one.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	HasMore    bool
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// EncodeCursor encodes key values into an opaque, URL-safe cursor string.
+func EncodeCursor(values []any) (string, error) {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor decodes a cursor produced by EncodeCursor. An empty cursor decodes
+// to nil, meaning the first page. Numbers are preserved as json.Number.
+func DecodeCursor(cursor string) ([]any, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var values []any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return values, nil
+}
+
+// BuildCursorQuery renders the SQL for one page, fetching Limit+1 rows so the
+// caller can tell whether another page follows.
+func BuildCursorQuery(q CursorQuery) (string, []any, error) {
+	if len(q.Keys) == 0 {
+		return "", nil, fmt.Errorf("at least one sort key is required")
+	}
+	if q.Limit <= 0 {
+		return "", nil, fmt.Errorf("limit must be positive")
+	}
+	if q.Cursor != nil && len(q.Cursor) != len(q.Keys) {
+		return "", nil, fmt.Errorf("cursor has %d values, expected %d", len(q.Cursor), len(q.Keys))
+	}
+	seen := make(map[string]string, len(q.Keys))
+	for _, k := range q.Keys {
+		if !identifierPattern.MatchString(k.Column) {
+			return "", nil, fmt.Errorf("invalid sort column %q", k.Column)
+		}
+		if prev, ok := seen[k.column()]; ok {
+			return "", nil, fmt.Errorf("sort columns %q and %q both refer to %s", prev, k.Column, k.column())
+		}
+		seen[k.column()] = k.Column
+	}
+
+	args := append([]any{}, q.Args...)
+	placeholder := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT * FROM (%s) AS page", q.Query)
+
+	if q.Cursor != nil {
+		b.WriteString(" WHERE ")
+		b.WriteString(keysetCondition(q.Keys, q.Cursor, placeholder))
+	}
+
+	order := make([]string, len(q.Keys))
+	for i, k := range q.Keys {
+		order[i] = k.column() + direction(k)
+	}
+	fmt.Fprintf(&b, " ORDER BY %s LIMIT %s", strings.Join(order, ", "), placeholder(q.Limit+1))
+
+	return b.String(), args, nil
+}
+
+// keysetCondition renders the "after cursor" predicate. Uniform directions use a
+// row comparison, which Postgres can satisfy from a composite index; mixed
+// directions are expanded into an OR of equality prefixes.
+func keysetCondition(keys []SortKey, cursor []any, placeholder func(any) string) string {
+	uniform := true
+	for _, k := range keys {
+		if k.Desc != keys[0].Desc {
+			uniform = false
+		}
+	}
+
+	if uniform {
+		cols := make([]string, len(keys))
+		vals := make([]string, len(keys))
+		for i, k := range keys {
+			cols[i] = k.column()
+			vals[i] = placeholder(cursor[i])
+		}
+		if len(keys) == 1 {
+			return fmt.Sprintf("%s %s %s", cols[0], comparator(keys[0]), vals[0])
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), comparator(keys[0]), strings.Join(vals, ", "))
+	}
+
+	clauses := make([]string, len(keys))
+	for i, k := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", keys[j].column(), placeholder(cursor[j])))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", k.column(), comparator(k), placeholder(cursor[i])))
+		clauses[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")"
+}
+
+// column returns the name of the key's column in the page subquery, where table
+// qualifiers from Query no longer apply.
+func (k SortKey) column() string {
+	if _, name, ok := strings.Cut(k.Column, "."); ok {
+		return name
+	}
+	return k.Column
+}
+
+func comparator(k SortKey) string {
+	if k.Desc {
+		return "<"
+	}
+	return ">"
+}
+
+func direction(k SortKey) string {
+	if k.Desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// QueryPage runs a keyset-paginated query against db. scan decodes the current row
+// and key returns the row's sort key values, in Keys order, for the next cursor.
+func QueryPage[T any](ctx context.Context, db DBTX, q CursorQuery, scan func(*sql.Rows) (T, error), key func(T) []any) (*Page[T], error) {
+	query, args, err := BuildCursorQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]T, 0, q.Limit+1)
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+
+	page := &Page[T]{Items: items}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		page.HasMore = true
+		if page.NextCursor, err = EncodeCursor(key(page.Items[q.Limit-1])); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
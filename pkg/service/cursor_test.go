@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCursorQuery_FirstPage(t *testing.T) {
+	query, args, err := BuildCursorQuery(CursorQuery{
+		Query: "SELECT id, name FROM users WHERE org = $1",
+		Args:  []any{"acme"},
+		Keys:  []SortKey{{Column: "id"}},
+		Limit: 10,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id, name FROM users WHERE org = $1) AS page ORDER BY id ASC LIMIT $2", query)
+	assert.Equal(t, []any{"acme", 11}, args)
+}
+
+func TestBuildCursorQuery_Descending(t *testing.T) {
+	query, args, err := BuildCursorQuery(CursorQuery{
+		Query:  "SELECT id FROM users",
+		Keys:   []SortKey{{Column: "id", Desc: true}},
+		Cursor: []any{int64(50)},
+		Limit:  5,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id FROM users) AS page WHERE id < $1 ORDER BY id DESC LIMIT $2", query)
+	assert.Equal(t, []any{int64(50), 6}, args)
+}
+
+func TestBuildCursorQuery_CompositeUniform(t *testing.T) {
+	query, args, err := BuildCursorQuery(CursorQuery{
+		Query:  "SELECT id, created_at FROM events",
+		Keys:   []SortKey{{Column: "created_at"}, {Column: "id"}},
+		Cursor: []any{"2024-01-01T00:00:00Z", int64(7)},
+		Limit:  20,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id, created_at FROM events) AS page WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT $3", query)
+	assert.Equal(t, []any{"2024-01-01T00:00:00Z", int64(7), 21}, args)
+}
+
+func TestBuildCursorQuery_CompositeMixed(t *testing.T) {
+	query, args, err := BuildCursorQuery(CursorQuery{
+		Query:  "SELECT id, score FROM players",
+		Keys:   []SortKey{{Column: "score", Desc: true}, {Column: "id"}},
+		Cursor: []any{int64(90), int64(3)},
+		Limit:  2,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id, score FROM players) AS page WHERE ((score < $1) OR (score = $2 AND id > $3)) ORDER BY score DESC, id ASC LIMIT $4", query)
+	assert.Equal(t, []any{int64(90), int64(90), int64(3), 3}, args)
+}
+
+func TestBuildCursorQuery_QualifiedColumns(t *testing.T) {
+	query, args, err := BuildCursorQuery(CursorQuery{
+		Query:  "SELECT t.id, t.created_at FROM tasks t JOIN projects p ON p.id = t.project_id",
+		Keys:   []SortKey{{Column: "t.created_at", Desc: true}, {Column: "t.id"}},
+		Cursor: []any{"2024-01-01T00:00:00Z", int64(7)},
+		Limit:  20,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT t.id, t.created_at FROM tasks t JOIN projects p ON p.id = t.project_id) AS page WHERE ((created_at < $1) OR (created_at = $2 AND id > $3)) ORDER BY created_at DESC, id ASC LIMIT $4", query)
+	assert.Equal(t, []any{"2024-01-01T00:00:00Z", "2024-01-01T00:00:00Z", int64(7), 21}, args)
+
+	_, _, err = BuildCursorQuery(CursorQuery{Query: "SELECT 1", Keys: []SortKey{{Column: "t.id"}, {Column: "p.id"}}, Limit: 1})
+	assert.ErrorContains(t, err, "both refer to id")
+}
+
+func TestBuildCursorQuery_Invalid(t *testing.T) {
+	_, _, err := BuildCursorQuery(CursorQuery{Query: "SELECT 1", Limit: 1})
+	assert.Error(t, err)
+
+	_, _, err = BuildCursorQuery(CursorQuery{Query: "SELECT 1", Keys: []SortKey{{Column: "id"}}})
+	assert.Error(t, err)
+
+	_, _, err = BuildCursorQuery(CursorQuery{Query: "SELECT 1", Keys: []SortKey{{Column: "id; DROP TABLE users"}}, Limit: 1})
+	assert.Error(t, err)
+
+	_, _, err = BuildCursorQuery(CursorQuery{Query: "SELECT 1", Keys: []SortKey{{Column: "id"}}, Cursor: []any{1, 2}, Limit: 1})
+	assert.Error(t, err)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor, err := EncodeCursor([]any{"2024-01-01T00:00:00Z", int64(9007199254740993)})
+	require.NoError(t, err)
+
+	values, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"2024-01-01T00:00:00Z", json.Number("9007199254740993")}, values)
+
+	values, err = DecodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+
+	_, err = DecodeCursor("not a cursor!")
+	assert.Error(t, err)
+}
+
+type user struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+func scanUser(rows *sql.Rows) (user, error) {
+	var u user
+	err := rows.Scan(&u.ID, &u.CreatedAt)
+	return u, err
+}
+
+func userKey(u user) []any { return []any{u.ID} }
+
+func TestQueryPage_DetectsNextPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM (SELECT id, created_at FROM users) AS page WHERE id > $1 ORDER BY id ASC LIMIT $2")).
+		WithArgs(int64(10), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+			AddRow(11, ts).AddRow(12, ts).AddRow(13, ts))
+
+	page, err := QueryPage(context.Background(), db, CursorQuery{
+		Query:  "SELECT id, created_at FROM users",
+		Keys:   []SortKey{{Column: "id"}},
+		Cursor: []any{int64(10)},
+		Limit:  2,
+	}, scanUser, userKey)
+
+	require.NoError(t, err)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, int64(12), page.Items[1].ID)
+
+	next, err := DecodeCursor(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []any{json.Number("12")}, next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryPage_LastPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT \\* FROM").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	page, err := QueryPage(context.Background(), db, CursorQuery{
+		Query: "SELECT id, created_at FROM users",
+		Keys:  []SortKey{{Column: "id"}},
+		Limit: 2,
+	}, scanUser, userKey)
+
+	require.NoError(t, err)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.Len(t, page.Items, 1)
+}
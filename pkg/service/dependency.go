@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ErrUnknownDependency is returned for dependency names that are not configured.
+var ErrUnknownDependency = errors.New("unknown service dependency")
+
+// dependencyDrainTimeout bounds how long CloseDependency waits for in-flight calls.
+var dependencyDrainTimeout = 10 * time.Second
+
+// CloseDependency removes the named connection from ServiceConnections, waits for
+// its in-flight calls to finish (up to a drain timeout) and then closes it.
+func (s *Service) CloseDependency(name string) error {
+	s.connMu.Lock()
+	conn, ok := s.ServiceConnections[name]
+	if !ok {
+		s.connMu.Unlock()
+		return fmt.Errorf("%w %q", ErrUnknownDependency, name)
+	}
+	delete(s.ServiceConnections, name)
+	tracker := s.inflight[name]
+	delete(s.inflight, name)
+	s.connMu.Unlock()
+
+	if tracker != nil && !tracker.wait(dependencyDrainTimeout) && s.Logger != nil {
+		s.Logger.Warn("closing %s service with %d calls still in flight", name, tracker.n.Load())
+	}
+
+	if err := conn.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", name, err)
+	}
+	if s.Logger != nil {
+		s.Logger.Info("Closed connection to %s service", name)
+	}
+	return nil
+}
+
+// Close closes every service connection, draining their in-flight calls like
+// CloseDependency but in parallel, and then the database. It returns every failure
+// joined into one error. Close is safe to call more than once; later calls return
+// the first result.
+func (s *Service) Close() error {
+	s.closeOnce.Do(func() {
+		names := s.DependencyNames()
+		errs := make([]error, len(names), len(names)+1)
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				errs[i] = s.CloseDependency(name)
+			}(i, name)
+		}
+		wg.Wait()
+		if s.DB != nil {
+			if err := s.DB.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+			}
+		}
+		s.closeErr = errors.Join(errs...)
+	})
+	return s.closeErr
+}
+
+// Connection returns the named dependency connection. Unlike reading
+// ServiceConnections directly, it is safe to call concurrently with CloseDependency.
+func (s *Service) Connection(name string) (*grpc.ClientConn, bool) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	conn, ok := s.ServiceConnections[name]
+	return conn, ok
+}
+
+// DependencyNames returns the names of the open dependency connections, sorted.
+// Like Connection, it is safe to call concurrently with CloseDependency.
+func (s *Service) DependencyNames() []string {
+	s.connMu.Lock()
+	names := make([]string, 0, len(s.ServiceConnections))
+	for name := range s.ServiceConnections {
+		names = append(names, name)
+	}
+	s.connMu.Unlock()
+	sort.Strings(names)
+	return names
+}
+
+// Dependency returns the named dependency connection, or an error wrapping
+// ErrUnknownDependency when it is not configured, e.g. missing from SERVICE_DEPS.
+func (s *Service) Dependency(name string) (*grpc.ClientConn, error) {
+	conn, ok := s.Connection(name)
+	if !ok || conn == nil {
+		return nil, fmt.Errorf("%w %q: add it to SERVICE_DEPS as %s@host:port", ErrUnknownDependency, name, name)
+	}
+	return conn, nil
+}
+
+// MustDependency is like Dependency but panics when the dependency is not configured.
+// Use it when wiring clients at startup, where a missing dependency is fatal.
+func (s *Service) MustDependency(name string) *grpc.ClientConn {
+	conn, err := s.Dependency(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return conn
+}
+
+// inflight counts calls in progress on a single client connection.
+type inflight struct {
+	n atomic.Int64
+}
+
+// dialOptions returns interceptors tracking calls made through the connection.
+func (f *inflight) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(f.unary),
+		grpc.WithChainStreamInterceptor(f.stream),
+	}
+}
+
+func (f *inflight) unary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	f.n.Add(1)
+	defer f.n.Add(-1)
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (f *inflight) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	f.n.Add(1)
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		f.n.Add(-1)
+		return nil, err
+	}
+
+	ts := &trackedStream{ClientStream: cs}
+	ts.finish = func() { ts.once.Do(func() { f.n.Add(-1) }) }
+
+	// A stream ends when it returns an error (including io.EOF) or its context is done
+	go func() {
+		<-cs.Context().Done()
+		ts.finish()
+	}()
+	return ts, nil
+}
+
+// wait blocks until no calls are in flight or timeout elapses, reporting whether it drained.
+func (f *inflight) wait(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for f.n.Load() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// trackedStream marks its call finished when the stream ends.
+type trackedStream struct {
+	grpc.ClientStream
+	once   sync.Once
+	finish func()
+}
+
+func (s *trackedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.finish()
+	}
+	return err
+}
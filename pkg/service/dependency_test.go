@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// blockingHealth holds Check calls until release is closed.
+type blockingHealth struct {
+	grpc_health_v1.UnimplementedHealthServer
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHealth) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	h.started <- struct{}{}
+	<-h.release
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func newServiceWithBufconnDep(t *testing.T, name string, h grpc_health_v1.HealthServer) *Service {
+	t.Helper()
+	l := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, h)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", name+"@bufnet")
+
+	origConnect, origDial := connectPostgres, dialGRPC
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+	dialGRPC = func(addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		return newClient(addr, append(opts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}))...)
+	}
+	t.Cleanup(func() { connectPostgres, dialGRPC = origConnect, origDial })
+
+	svc, err := New()
+	require.NoError(t, err)
+	return svc
+}
+
+func TestCloseDependency_DrainsInFlightCalls(t *testing.T) {
+	h := &blockingHealth{started: make(chan struct{}, 1), release: make(chan struct{})}
+	svc := newServiceWithBufconnDep(t, "users", h)
+	conn, ok := svc.Connection("users")
+	require.True(t, ok)
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		callErr <- err
+	}()
+	<-h.started
+
+	closed := make(chan error, 1)
+	go func() { closed <- svc.CloseDependency("users") }()
+
+	select {
+	case <-closed:
+		t.Fatal("CloseDependency returned while a call was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	_, ok = svc.Connection("users")
+	assert.False(t, ok, "new callers must not find a closing dependency")
+
+	close(h.release)
+	require.NoError(t, <-callErr, "the in-flight call must complete")
+	require.NoError(t, <-closed)
+}
+
+func TestCloseDependency_DrainTimeout(t *testing.T) {
+	h := &blockingHealth{started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(h.release)
+	svc := newServiceWithBufconnDep(t, "users", h)
+
+	orig := dependencyDrainTimeout
+	dependencyDrainTimeout = 20 * time.Millisecond
+	defer func() { dependencyDrainTimeout = orig }()
+
+	go func() {
+		_, _ = grpc_health_v1.NewHealthClient(svc.ServiceConnections["users"]).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	}()
+	<-h.started
+
+	require.NoError(t, svc.CloseDependency("users"))
+}
+
+func TestCloseDependency_Missing(t *testing.T) {
+	svc := NewMock()
+	err := svc.CloseDependency("ghost")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown service dependency "ghost"`)
+}
+
+func TestClose_AggregatesErrorsAndIsIdempotent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectClose().WillReturnError(errors.New("connection busy"))
+
+	// Connections closed underneath the service fail to close again
+	dial := func() *grpc.ClientConn {
+		conn, err := newClient("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		return conn
+	}
+	users, billing, open := dial(), dial(), dial()
+	require.NoError(t, users.Close())
+	require.NoError(t, billing.Close())
+
+	svc := NewMock()
+	svc.DB = db
+	svc.ServiceConnections = map[string]*grpc.ClientConn{"users": users, "billing": billing, "search": open}
+
+	err = svc.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to close billing")
+	assert.Contains(t, err.Error(), "failed to close users")
+	assert.Contains(t, err.Error(), "failed to close database: connection busy")
+	assert.NotContains(t, err.Error(), "search")
+	assert.Empty(t, svc.ServiceConnections)
+	assert.Equal(t, connectivity.Shutdown, open.GetState())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Later calls do not close anything again and report the same result
+	assert.Equal(t, err, svc.Close())
+}
+
+func TestClose_DrainsDependenciesInParallel(t *testing.T) {
+	h := &blockingHealth{started: make(chan struct{}, 2), release: make(chan struct{})}
+	defer close(h.release)
+	svc := newServiceWithBufconnDep(t, "users", h)
+	svc.DB = nil
+
+	// A second dependency on the same server, tracked like the ones New dials
+	tracker := &inflight{}
+	billing, err := dialGRPC("bufnet", append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, tracker.dialOptions()...)...)
+	require.NoError(t, err)
+	svc.connMu.Lock()
+	svc.ServiceConnections["billing"] = billing
+	svc.inflight["billing"] = tracker
+	svc.connMu.Unlock()
+
+	orig := dependencyDrainTimeout
+	dependencyDrainTimeout = 200 * time.Millisecond
+	defer func() { dependencyDrainTimeout = orig }()
+
+	for _, name := range []string{"users", "billing"} {
+		conn, _ := svc.Connection(name)
+		go func() {
+			_, _ = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		}()
+		<-h.started
+	}
+
+	start := time.Now()
+	require.NoError(t, svc.Close())
+	assert.Less(t, time.Since(start), 2*dependencyDrainTimeout, "drains share one timeout instead of adding up")
+	assert.Empty(t, svc.DependencyNames())
+}
+
+func TestClose_NothingToClose(t *testing.T) {
+	assert.NoError(t, NewMock().Close())
+}
+
+func TestDependency(t *testing.T) {
+	conn, err := newClient("users:5000", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	svc := NewMock()
+	svc.ServiceConnections["users"] = conn
+	svc.ServiceConnections["broken"] = nil
+
+	got, err := svc.Dependency("users")
+	require.NoError(t, err)
+	assert.Same(t, conn, got)
+	assert.Same(t, conn, svc.MustDependency("users"))
+
+	for _, name := range []string{"billing", "broken"} {
+		_, err = svc.Dependency(name)
+		assert.ErrorIs(t, err, ErrUnknownDependency)
+		assert.EqualError(t, err, `unknown service dependency "`+name+`": add it to SERVICE_DEPS as `+name+`@host:port`)
+	}
+	assert.PanicsWithValue(t, `unknown service dependency "billing": add it to SERVICE_DEPS as billing@host:port`, func() {
+		svc.MustDependency("billing")
+	})
+
+	// A service built without connections has none to look up
+	_, err = (&Service{}).Dependency("users")
+	assert.ErrorIs(t, err, ErrUnknownDependency)
+}
+
+func TestDependencyNames(t *testing.T) {
+	svc := NewMock()
+	assert.Empty(t, svc.DependencyNames())
+
+	svc.ServiceConnections["users"] = nil
+	svc.ServiceConnections["billing"] = nil
+	assert.Equal(t, []string{"billing", "users"}, svc.DependencyNames())
+}
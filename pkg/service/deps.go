@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// depsResolverScheme names the in-process resolver serving load-balanced dependencies.
+const depsResolverScheme = "svcdeps"
+
+// roundRobinConfig spreads calls across every address of a load-balanced dependency.
+const roundRobinConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+
+// ServiceDep is a gRPC dependency the service holds a connection to, keyed by Name in
+// ServiceConnections. Several addresses are load-balanced round-robin.
+type ServiceDep struct {
+	Name  string
+	Addrs []string
+}
+
+// loadBalanceDeps reports whether SERVICE_DEPS_LOAD_BALANCE=true asks for repeated
+// names to be treated as endpoints of one load-balanced dependency.
+func loadBalanceDeps() bool {
+	return os.Getenv("SERVICE_DEPS_LOAD_BALANCE") == "true"
+}
+
+// parseServiceDeps parses comma-separated name@addr entries, skipping malformed ones.
+// An entry may list several semicolon-separated addresses, as in auth@host1:5001;host2:5001,
+// to balance across them. A name listed twice is a configuration error unless
+// loadBalance is set, in which case its addresses are collected in order.
+// Dependencies keep their first-seen order.
+func parseServiceDeps(raw string, loadBalance bool) ([]ServiceDep, error) {
+	var deps []ServiceDep
+	index := map[string]int{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "@", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		var addrs []string
+		for _, addr := range strings.Split(parts[1], ";") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+		if name == "" || len(addrs) == 0 {
+			continue
+		}
+
+		i, seen := index[name]
+		if !seen {
+			index[name] = len(deps)
+			deps = append(deps, ServiceDep{Name: name, Addrs: addrs})
+			continue
+		}
+		if !loadBalance {
+			return nil, fmt.Errorf("duplicate SERVICE_DEPS name %q (%s and %s); list both as %s@%s;%s or set SERVICE_DEPS_LOAD_BALANCE=true to balance across them",
+				name, deps[i].Addrs[0], addrs[0], name, deps[i].Addrs[0], addrs[0])
+		}
+		deps[i].Addrs = append(deps[i].Addrs, addrs...)
+	}
+	return deps, nil
+}
+
+// target returns the dial target for the dependency. A single address is dialed
+// directly; several are served by a per-connection resolver with round-robin balancing.
+func (d ServiceDep) target() (string, []grpc.DialOption) {
+	if len(d.Addrs) == 1 {
+		return d.Addrs[0], nil
+	}
+
+	r := manual.NewBuilderWithScheme(depsResolverScheme)
+	addrs := make([]resolver.Address, 0, len(d.Addrs))
+	for _, addr := range d.Addrs {
+		addrs = append(addrs, resolver.Address{Addr: addr})
+	}
+	r.InitialState(resolver.State{Addresses: addrs})
+
+	return depsResolverScheme + ":///" + d.Name, []grpc.DialOption{
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(roundRobinConfig),
+	}
+}
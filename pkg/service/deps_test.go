@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestParseServiceDeps(t *testing.T) {
+	deps, err := parseServiceDeps(" auth@localhost:5001, malformed ,@nohost,users@localhost:5002", false)
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDep{
+		{Name: "auth", Addrs: []string{"localhost:5001"}},
+		{Name: "users", Addrs: []string{"localhost:5002"}},
+	}, deps)
+}
+
+func TestParseServiceDeps_DuplicateName(t *testing.T) {
+	_, err := parseServiceDeps("auth@localhost:5001,users@localhost:5002,auth@localhost:5003", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate SERVICE_DEPS name "auth"`)
+	assert.Contains(t, err.Error(), "localhost:5001 and localhost:5003")
+}
+
+func TestParseServiceDeps_LoadBalanced(t *testing.T) {
+	deps, err := parseServiceDeps("auth@10.0.0.1:5001,users@localhost:5002,auth@10.0.0.2:5001", true)
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDep{
+		{Name: "auth", Addrs: []string{"10.0.0.1:5001", "10.0.0.2:5001"}},
+		{Name: "users", Addrs: []string{"localhost:5002"}},
+	}, deps)
+}
+
+func TestParseServiceDeps_MultiAddress(t *testing.T) {
+	deps, err := parseServiceDeps("auth@host1:5001; host2:5001 ;,users@localhost:5002,billing@;", false)
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDep{
+		{Name: "auth", Addrs: []string{"host1:5001", "host2:5001"}},
+		{Name: "users", Addrs: []string{"localhost:5002"}},
+	}, deps)
+
+	// Multi-address entries combine with repeated names when load balancing
+	deps, err = parseServiceDeps("auth@host1:5001;host2:5001,auth@host3:5001", true)
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceDep{{Name: "auth", Addrs: []string{"host1:5001", "host2:5001", "host3:5001"}}}, deps)
+}
+
+func TestNew_DuplicateServiceDepsFails(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", "auth@localhost:5001,auth@localhost:5002")
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+	defer func() { connectPostgres = origConnect }()
+
+	_, err := New()
+	assert.ErrorContains(t, err, "duplicate SERVICE_DEPS name")
+}
+
+// countingHealth counts Check calls served by one backend.
+type countingHealth struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls atomic.Int32
+}
+
+func (h *countingHealth) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	h.calls.Add(1)
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func startHealthBackend(t *testing.T) (string, *countingHealth) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	h := &countingHealth{}
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, h)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+	return l.Addr().String(), h
+}
+
+func TestNew_LoadBalancedServiceDeps(t *testing.T) {
+	tests := []struct {
+		name        string
+		deps        func(addrA, addrB string) string
+		loadBalance string
+	}{
+		{"repeated names", func(a, b string) string { return "users@" + a + ",users@" + b }, "true"},
+		{"address list", func(a, b string) string { return "users@" + a + ";" + b }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addrA, backendA := startHealthBackend(t)
+			addrB, backendB := startHealthBackend(t)
+
+			setMinimalEnv(t)
+			t.Setenv("SERVICE_DEPS", tt.deps(addrA, addrB))
+			t.Setenv("SERVICE_DEPS_LOAD_BALANCE", tt.loadBalance)
+			origConnect := connectPostgres
+			connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+			defer func() { connectPostgres = origConnect }()
+
+			svc, err := New()
+			require.NoError(t, err)
+			conn, ok := svc.Connection("users")
+			require.True(t, ok)
+			defer conn.Close()
+
+			require.NoError(t, svc.WaitForConnections(context.Background()))
+			// round_robin only picks ready backends, so keep calling until both have connected
+			client := grpc_health_v1.NewHealthClient(conn)
+			for i := 0; i < 500 && (backendA.calls.Load() == 0 || backendB.calls.Load() == 0); i++ {
+				_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+				require.NoError(t, err)
+			}
+
+			assert.Positive(t, backendA.calls.Load())
+			assert.Positive(t, backendB.calls.Load())
+		})
+	}
+}
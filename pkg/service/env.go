@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultRequiredEnv lists the variables New requires unless ServiceOption.RequiredEnv
+// overrides them. The DB_* variables are only required when connecting to a database.
+var defaultRequiredEnv = []string{"SERVICE", "VERSION"}
+
+// dbRequiredEnv lists the connection variables required unless NO_DB=true.
+var dbRequiredEnv = []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME"}
+
+// requiredEnv returns the variables New must find set: the last RequiredEnv given in
+// opts, or the defaults.
+func requiredEnv(opts []ServiceOption) []string {
+	for i := len(opts) - 1; i >= 0; i-- {
+		if opts[i].RequiredEnv != nil {
+			return opts[i].RequiredEnv
+		}
+	}
+
+	if noDatabase() {
+		return defaultRequiredEnv
+	}
+	return append(append([]string{}, defaultRequiredEnv...), dbRequiredEnv...)
+}
+
+// requireEnv reports every variable in names that is unset or empty in a single error.
+func requireEnv(names []string) error {
+	var missing []string
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_ReportsEveryMissingEnvVar(t *testing.T) {
+	for _, name := range append(append([]string{}, defaultRequiredEnv...), dbRequiredEnv...) {
+		t.Setenv(name, "")
+	}
+	t.Setenv("DB_HOST", "localhost")
+
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) {
+		t.Fatal("New must not connect with missing configuration")
+		return nil, nil
+	}
+	defer func() { connectPostgres = origConnect }()
+
+	_, err := New()
+	require.Error(t, err)
+	assert.EqualError(t, err, "missing required environment variables: SERVICE, VERSION, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME")
+
+	// Without a database only the service identity is required
+	t.Setenv("NO_DB", "true")
+	_, err = New()
+	assert.EqualError(t, err, "missing required environment variables: SERVICE, VERSION")
+}
+
+func TestNew_RequiredEnvOverride(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("VERSION", "")
+	t.Setenv("FEATURE_FLAGS_URL", "")
+
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+	defer func() { connectPostgres = origConnect }()
+
+	_, err := New(ServiceOption{RequiredEnv: []string{"SERVICE", "FEATURE_FLAGS_URL"}})
+	assert.EqualError(t, err, "missing required environment variables: FEATURE_FLAGS_URL")
+
+	// An empty list disables the check, and the option keeps the insecure dial default
+	t.Setenv("SERVICE_DEPS", "auth@localhost:5001")
+	origDial := dialGRPC
+	dialGRPC = newClient
+	defer func() { dialGRPC = origDial }()
+
+	svc, err := New(ServiceOption{RequiredEnv: []string{}})
+	require.NoError(t, err)
+	assert.NoError(t, svc.CloseDependency("auth"))
+}
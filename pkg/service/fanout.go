@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// FanOutOptions configures FanOut.
+type FanOutOptions struct {
+	// Limit bounds how many calls run at once. Zero runs them all concurrently.
+	Limit int
+	// Timeout is a deadline shared by every call. Zero leaves ctx's deadline alone.
+	Timeout time.Duration
+	// ContinueOnError lets the remaining calls finish after one fails, instead of
+	// cancelling them, and reports every failure.
+	ContinueOnError bool
+}
+
+// FanOut runs calls in parallel and returns their results in call order. By default the
+// first failure cancels the context passed to the others and is returned alone; with
+// ContinueOnError every failure is joined into the error, and results from the calls
+// that succeeded are still returned.
+func FanOut[T any](ctx context.Context, calls []func(ctx context.Context) (T, error), opts ...FanOutOptions) ([]T, error) {
+	opt := FanOutOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+
+	var g *errgroup.Group
+	if opt.ContinueOnError {
+		g = &errgroup.Group{}
+	} else {
+		g, ctx = errgroup.WithContext(ctx)
+	}
+	if opt.Limit > 0 {
+		g.SetLimit(opt.Limit)
+	}
+
+	results := make([]T, len(calls))
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		g.Go(func() error {
+			res, err := call(ctx)
+			if err != nil {
+				errs[i] = fmt.Errorf("fan-out call %d failed: %w", i, err)
+				return errs[i]
+			}
+			results[i] = res
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if opt.ContinueOnError {
+		err = errors.Join(errs...)
+	}
+	return results, err
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOut_RunsCallsInParallel(t *testing.T) {
+	// Every call waits for all of them to start, so sequential execution would deadlock
+	const n = 4
+	started := make(chan struct{}, n)
+	call := func(v int) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			started <- struct{}{}
+			for len(started) < n {
+				select {
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+			return v, nil
+		}
+	}
+
+	results, err := FanOut(context.Background(), []func(context.Context) (int, error){call(1), call(2), call(3), call(4)},
+		FanOutOptions{Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, results)
+}
+
+func TestFanOut_Limit(t *testing.T) {
+	var running, peak atomic.Int32
+	calls := make([]func(context.Context) (struct{}, error), 6)
+	for i := range calls {
+		calls[i] = func(context.Context) (struct{}, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			return struct{}{}, nil
+		}
+	}
+
+	_, err := FanOut(context.Background(), calls, FanOutOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestFanOut_FirstErrorCancelsOthers(t *testing.T) {
+	boom := errors.New("users unavailable")
+	cancelled := make(chan error, 1)
+	calls := []func(context.Context) (string, error){
+		func(context.Context) (string, error) { return "", boom },
+		func(ctx context.Context) (string, error) {
+			select {
+			case <-ctx.Done():
+				cancelled <- ctx.Err()
+				return "", ctx.Err()
+			case <-time.After(time.Second):
+				return "slow", nil
+			}
+		},
+	}
+
+	_, err := FanOut(context.Background(), calls)
+	assert.ErrorIs(t, err, boom)
+	assert.EqualError(t, err, "fan-out call 0 failed: users unavailable")
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+}
+
+func TestFanOut_ContinueOnError(t *testing.T) {
+	calls := []func(context.Context) (string, error){
+		func(context.Context) (string, error) { return "", errors.New("users unavailable") },
+		func(ctx context.Context) (string, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "orders", ctx.Err()
+		},
+		func(context.Context) (string, error) { return "", errors.New("billing unavailable") },
+	}
+
+	results, err := FanOut(context.Background(), calls, FanOutOptions{ContinueOnError: true})
+	assert.Equal(t, []string{"", "orders", ""}, results)
+	assert.EqualError(t, err, "fan-out call 0 failed: users unavailable\nfan-out call 2 failed: billing unavailable")
+}
+
+func TestFanOut_SharedDeadline(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	deadlines := make(chan time.Time, 2)
+	call := func(ctx context.Context) (int, error) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	start := time.Now()
+	_, err := FanOut(parent, []func(context.Context) (int, error){call, call}, FanOutOptions{Timeout: 20 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	first, second := <-deadlines, <-deadlines
+	assert.Equal(t, first, second)
+	assert.WithinDuration(t, start.Add(20*time.Millisecond), first, 15*time.Millisecond)
+}
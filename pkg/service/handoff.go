@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// handoffMaxAge is how old saved request state may be before a restart rejects it
+// instead of restoring it, since its clients have likely given up by then.
+var handoffMaxAge = time.Minute
+
+// HandoffRecord is the serialized state of one in-flight request, saved on shutdown
+// so a replacement process can resume it.
+type HandoffRecord struct {
+	Kind    string          `json:"kind"`
+	Key     string          `json:"key"`
+	State   json.RawMessage `json:"state"`
+	SavedAt time.Time       `json:"saved_at"`
+}
+
+// HandoffStore persists request state between the process shutting down and its
+// replacement. Load consumes the saved records, so they are restored only once.
+type HandoffStore interface {
+	Save(ctx context.Context, records []HandoffRecord) error
+	Load(ctx context.Context) ([]HandoffRecord, error)
+}
+
+// HandoffRestorer resumes a request from its saved state. Returning an error
+// rejects the record.
+type HandoffRestorer func(ctx context.Context, rec HandoffRecord) error
+
+// HandoffResult lists the keys of the records RestoreHandoff restored and rejected.
+type HandoffResult struct {
+	Restored []string
+	Rejected []string
+}
+
+// handoffEntry is a request tracked by TrackHandoff.
+type handoffEntry struct {
+	kind, key string
+	snapshot  func() any
+}
+
+// handoffFile reads the file request state is handed off through from HANDOFF_FILE.
+func handoffFile() string {
+	return os.Getenv("HANDOFF_FILE")
+}
+
+// TrackHandoff registers an in-flight request whose state should survive a restart,
+// such as a long poll's cursor. snapshot is called on shutdown and its result is
+// saved as JSON under kind and key. Call the returned function once the request
+// completes; requests still tracked at shutdown are handed off. A request may
+// finish after its state was saved, so restorers should tolerate resuming it.
+func (s *Service) TrackHandoff(kind, key string, snapshot func() any) (done func()) {
+	entry := &handoffEntry{kind: kind, key: key, snapshot: snapshot}
+
+	s.handoffMu.Lock()
+	if s.handoffTracked == nil {
+		s.handoffTracked = map[*handoffEntry]struct{}{}
+	}
+	s.handoffTracked[entry] = struct{}{}
+	s.handoffMu.Unlock()
+
+	return func() {
+		s.handoffMu.Lock()
+		delete(s.handoffTracked, entry)
+		s.handoffMu.Unlock()
+	}
+}
+
+// RegisterHandoffRestorer sets how records of kind are resumed by RestoreHandoff.
+// Records of kinds without a restorer are rejected.
+func (s *Service) RegisterHandoffRestorer(kind string, restore HandoffRestorer) {
+	s.handoffMu.Lock()
+	defer s.handoffMu.Unlock()
+
+	if s.handoffRestorers == nil {
+		s.handoffRestorers = map[string]HandoffRestorer{}
+	}
+	s.handoffRestorers[kind] = restore
+}
+
+// SaveHandoff snapshots every tracked request into Handoff and returns how many
+// were saved. It does nothing without a Handoff store or tracked requests.
+func (s *Service) SaveHandoff(ctx context.Context) (int, error) {
+	if s.Handoff == nil {
+		return 0, nil
+	}
+
+	s.handoffMu.Lock()
+	entries := make([]*handoffEntry, 0, len(s.handoffTracked))
+	for entry := range s.handoffTracked {
+		entries = append(entries, entry)
+	}
+	s.handoffMu.Unlock()
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	records := make([]HandoffRecord, 0, len(entries))
+	for _, entry := range entries {
+		state, err := json.Marshal(entry.snapshot())
+		if err != nil {
+			s.Logger.Warn("not handing off %s request %s: %v", entry.kind, entry.key, err)
+			continue
+		}
+		records = append(records, HandoffRecord{Kind: entry.kind, Key: entry.key, State: state, SavedAt: now})
+	}
+
+	if err := s.Handoff.Save(ctx, records); err != nil {
+		return 0, fmt.Errorf("failed to save request state: %w", err)
+	}
+	return len(records), nil
+}
+
+// RestoreHandoff loads the records saved by the previous process and passes each
+// to the restorer registered for its kind. Records without a restorer, older than
+// a minute, or failing to restore are rejected and logged. Register restorers
+// before calling it; server.Run calls it before serving.
+func (s *Service) RestoreHandoff(ctx context.Context) (HandoffResult, error) {
+	var result HandoffResult
+	if s.Handoff == nil {
+		return result, nil
+	}
+
+	records, err := s.Handoff.Load(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to load request state: %w", err)
+	}
+
+	for _, rec := range records {
+		s.handoffMu.Lock()
+		restore := s.handoffRestorers[rec.Kind]
+		s.handoffMu.Unlock()
+
+		switch {
+		case restore == nil:
+			err = fmt.Errorf("no restorer for kind %q", rec.Kind)
+		case time.Since(rec.SavedAt) > handoffMaxAge:
+			err = fmt.Errorf("state saved %s ago is stale", time.Since(rec.SavedAt).Round(time.Second))
+		default:
+			err = restore(ctx, rec)
+		}
+
+		if err != nil {
+			s.Logger.Warn("rejecting handed off %s request %s: %v", rec.Kind, rec.Key, err)
+			result.Rejected = append(result.Rejected, rec.Key)
+			continue
+		}
+		result.Restored = append(result.Restored, rec.Key)
+	}
+	return result, nil
+}
+
+// FileHandoffStore hands request state off through a JSON file, for restarts on
+// the same host or volume.
+type FileHandoffStore struct {
+	path string
+}
+
+// NewFileHandoffStore creates a store saving to path.
+func NewFileHandoffStore(path string) *FileHandoffStore {
+	return &FileHandoffStore{path: path}
+}
+
+// Save writes records to the file, replacing it atomically.
+func (f *FileHandoffStore) Save(_ context.Context, records []HandoffRecord) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// Load reads and removes the file. A missing file means nothing was handed off.
+func (f *FileHandoffStore) Load(_ context.Context) ([]HandoffRecord, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(f.path); err != nil {
+		return nil, err
+	}
+
+	var records []HandoffRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid handoff file %s: %w", f.path, err)
+	}
+	return records, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pollState struct {
+	Cursor int `json:"cursor"`
+}
+
+func TestHandoff_SavedOnShutdownAndRestored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handoff.json")
+
+	// The old process has three long polls in flight when it shuts down
+	old := NewMock()
+	old.Handoff = NewFileHandoffStore(path)
+	cursor := 7
+	old.TrackHandoff("longpoll", "poll-1", func() any { return pollState{Cursor: cursor} })
+	old.TrackHandoff("export", "export-1", func() any { return map[string]string{"job": "42"} })
+	old.TrackHandoff("longpoll", "poll-bad", func() any { return pollState{Cursor: -1} })
+	done := old.TrackHandoff("longpoll", "poll-done", func() any { return pollState{} })
+	done()
+	cursor = 9
+
+	n, err := old.SaveHandoff(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// The replacement resumes long polls, rejecting invalid state and unknown kinds
+	replacement := NewMock()
+	replacement.Handoff = NewFileHandoffStore(path)
+	resumed := map[string]pollState{}
+	replacement.RegisterHandoffRestorer("longpoll", func(_ context.Context, rec HandoffRecord) error {
+		var state pollState
+		if err := json.Unmarshal(rec.State, &state); err != nil {
+			return err
+		}
+		if state.Cursor < 0 {
+			return errors.New("invalid cursor")
+		}
+		resumed[rec.Key] = state
+		return nil
+	})
+
+	result, err := replacement.RestoreHandoff(context.Background())
+	require.NoError(t, err)
+	sort.Strings(result.Rejected)
+	assert.Equal(t, []string{"poll-1"}, result.Restored)
+	assert.Equal(t, []string{"export-1", "poll-bad"}, result.Rejected)
+	assert.Equal(t, map[string]pollState{"poll-1": {Cursor: 9}}, resumed)
+
+	// State is restored only once
+	result, err = replacement.RestoreHandoff(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, result.Restored)
+	assert.Empty(t, result.Rejected)
+}
+
+func TestRestoreHandoff_RejectsStaleState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handoff.json")
+	store := NewFileHandoffStore(path)
+	require.NoError(t, store.Save(context.Background(), []HandoffRecord{
+		{Kind: "longpoll", Key: "poll-1", State: json.RawMessage(`{}`), SavedAt: time.Now().Add(-2 * handoffMaxAge)},
+	}))
+
+	svc := NewMock()
+	svc.Handoff = store
+	svc.RegisterHandoffRestorer("longpoll", func(context.Context, HandoffRecord) error {
+		t.Fatal("stale state must not be restored")
+		return nil
+	})
+
+	result, err := svc.RestoreHandoff(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"poll-1"}, result.Rejected)
+}
+
+func TestHandoff_WithoutStore(t *testing.T) {
+	svc := NewMock()
+	svc.TrackHandoff("longpoll", "poll-1", func() any { return nil })
+
+	n, err := svc.SaveHandoff(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	result, err := svc.RestoreHandoff(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, result.Restored)
+}
+
+func TestFileHandoffStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handoff.json")
+	store := NewFileHandoffStore(path)
+
+	records, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, records)
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err = store.Load(context.Background())
+	assert.ErrorContains(t, err, "invalid handoff file")
+	assert.NoFileExists(t, path)
+}
+
+func TestNew_HandoffFile(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("NO_DB", "true")
+	t.Setenv("HANDOFF_FILE", filepath.Join(t.TempDir(), "handoff.json"))
+
+	svc, err := New()
+	require.NoError(t, err)
+	assert.IsType(t, &FileHandoffStore{}, svc.Handoff)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck reports whether a dependency is healthy. A nil error means healthy.
+type HealthCheck func(ctx context.Context) error
+
+// RegisterHealthCheck registers a named readiness check, replacing any check
+// previously registered under the same name.
+func (s *Service) RegisterHealthCheck(name string, check HealthCheck) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if s.healthChecks == nil {
+		s.healthChecks = map[string]HealthCheck{}
+	}
+	s.healthChecks[name] = check
+}
+
+// HealthChecks returns a copy of the registered readiness checks.
+func (s *Service) HealthChecks() map[string]HealthCheck {
+	s.healthMu.RLock()
+	defer s.healthMu.RUnlock()
+
+	checks := make(map[string]HealthCheck, len(s.healthChecks))
+	for name, check := range s.healthChecks {
+		checks[name] = check
+	}
+	return checks
+}
+
+// DBHealthCheck is the key of the database result in Health.
+const DBHealthCheck = "db"
+
+// HealthCheckDB pings the database, reporting ErrNoDatabase when the service has none.
+func (s *Service) HealthCheckDB(ctx context.Context) error {
+	if s.DB == nil {
+		return ErrNoDatabase
+	}
+	if err := s.DB.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	return nil
+}
+
+// Health pings the database, when there is one, and checks the state of every
+// service connection without waiting on them, keyed by DBHealthCheck and the
+// connection names. A nil error means healthy; idle and connecting connections count
+// as healthy since clients connect lazily, while failing and shut down ones do not.
+func (s *Service) Health(ctx context.Context) map[string]error {
+	results := map[string]error{}
+	if s.DB != nil {
+		results[DBHealthCheck] = s.HealthCheckDB(ctx)
+	}
+
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	for name, conn := range s.ServiceConnections {
+		results[name] = nil
+		switch state := conn.GetState(); state {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			results[name] = fmt.Errorf("service %s connection is %s", name, state)
+		}
+	}
+	return results
+}
+
+// HealthServer returns the gRPC health server reporting this service's status,
+// creating it on first use. The gRPC server registers it, and MonitorHealth updates it.
+func (s *Service) HealthServer() *health.Server {
+	s.healthServerOnce.Do(func() {
+		s.healthServer = health.NewServer()
+	})
+	return s.healthServer
+}
+
+// DefaultHealthInterval is the interval MonitorHealth uses when given none.
+const DefaultHealthInterval = 10 * time.Second
+
+// MonitorHealth runs the registered health checks immediately and then every
+// interval in the background until ctx is done. Each check's result is reported
+// as the status of the gRPC service named after it, and the overall ("") status
+// is SERVING only while every check passes and the service is marked ready (see
+// SetReady). Each run is bounded by interval. Intervals of zero or less use
+// DefaultHealthInterval.
+func (s *Service) MonitorHealth(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		if s.Logger != nil {
+			s.Logger.Warn("invalid health check interval %s, using %s", interval, DefaultHealthInterval)
+		}
+		interval = DefaultHealthInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.runHealthChecks(ctx, interval)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runHealthChecks runs every check once and publishes the results.
+func (s *Service) runHealthChecks(ctx context.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	hs := s.HealthServer()
+	failing := false
+	for name, check := range s.HealthChecks() {
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		if err := check(ctx); err != nil {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			failing = true
+			if s.Logger != nil {
+				s.Logger.Warn("health check %s failed: %v", name, err)
+			}
+		}
+		hs.SetServingStatus(name, status)
+	}
+	s.checksFailing.Store(failing)
+	s.publishOverallHealth()
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestRegisterHealthCheck(t *testing.T) {
+	svc := &Service{}
+	assert.Empty(t, svc.HealthChecks())
+
+	svc.RegisterHealthCheck("cache", func(context.Context) error { return nil })
+	svc.RegisterHealthCheck("queue", func(context.Context) error { return errors.New("down") })
+
+	checks := svc.HealthChecks()
+	assert.Len(t, checks, 2)
+	assert.NoError(t, checks["cache"](context.Background()))
+	assert.EqualError(t, checks["queue"](context.Background()), "down")
+
+	// Re-registering replaces the check
+	svc.RegisterHealthCheck("queue", func(context.Context) error { return nil })
+	assert.NoError(t, svc.HealthChecks()["queue"](context.Background()))
+
+	// The returned map is a copy
+	delete(checks, "cache")
+	assert.Len(t, svc.HealthChecks(), 2)
+}
+
+func TestMonitorHealth_FlipsServingStatus(t *testing.T) {
+	svc := &Service{}
+	var healthy atomic.Bool
+	healthy.Store(true)
+	svc.RegisterHealthCheck("db", func(context.Context) error {
+		if healthy.Load() {
+			return nil
+		}
+		return errors.New("connection refused")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.MonitorHealth(ctx, 10*time.Millisecond)
+
+	status := func(name string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := svc.HealthServer().Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: name})
+		if err != nil {
+			return grpc_health_v1.HealthCheckResponse_UNKNOWN
+		}
+		return resp.Status
+	}
+
+	assert.Eventually(t, func() bool {
+		return status("") == grpc_health_v1.HealthCheckResponse_SERVING && status("db") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	healthy.Store(false)
+	assert.Eventually(t, func() bool {
+		return status("") == grpc_health_v1.HealthCheckResponse_NOT_SERVING && status("db") == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	healthy.Store(true)
+	assert.Eventually(t, func() bool {
+		return status("") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHealthCheckDB(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+	svc := &Service{DB: db}
+
+	mock.ExpectPing()
+	assert.NoError(t, svc.HealthCheckDB(context.Background()))
+
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	err = svc.HealthCheckDB(context.Background())
+	assert.EqualError(t, err, "database ping failed: connection refused")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.ErrorIs(t, (&Service{}).HealthCheckDB(context.Background()), ErrNoDatabase)
+}
+
+func TestHealth_ReportsDBAndConnections(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	l := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	dial := func(dialer func(context.Context, string) (net.Conn, error)) *grpc.ClientConn {
+		conn, err := newClient("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialer))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	ready := dial(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) })
+	idle := dial(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) })
+	down := dial(func(context.Context, string) (net.Conn, error) { return nil, errors.New("unreachable") })
+	closed := dial(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) })
+	require.NoError(t, closed.Close())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, waitReady(ctx, ready))
+	down.Connect()
+	for state := down.GetState(); state != connectivity.TransientFailure; state = down.GetState() {
+		require.True(t, down.WaitForStateChange(ctx, state))
+	}
+
+	svc := &Service{DB: db, ServiceConnections: map[string]*grpc.ClientConn{
+		"users": ready, "search": idle, "billing": down, "audit": closed,
+	}}
+	results := svc.Health(context.Background())
+
+	assert.Len(t, results, 5)
+	assert.EqualError(t, results[DBHealthCheck], "database ping failed: connection refused")
+	assert.NoError(t, results["users"])
+	assert.NoError(t, results["search"])
+	assert.EqualError(t, results["billing"], "service billing connection is TRANSIENT_FAILURE")
+	assert.EqualError(t, results["audit"], "service audit connection is SHUTDOWN")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Without a database only the connections are reported
+	assert.Empty(t, (&Service{}).Health(context.Background()))
+}
+
+func TestMonitorHealth_NonPositiveIntervalUsesDefault(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		svc := &Service{}
+		ran := make(chan struct{}, 1)
+		svc.RegisterHealthCheck("db", func(ctx context.Context) error {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.Greater(t, time.Until(deadline), DefaultHealthInterval/2, "runs are bounded by the default interval")
+			ran <- struct{}{}
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		svc.MonitorHealth(ctx, interval)
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatalf("checks did not run with interval %s", interval)
+		}
+		cancel()
+	}
+}
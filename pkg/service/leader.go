@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// ErrLeadershipLost is the cause of the context passed to a RunAsLeader callback
+// when the session holding the advisory lock stops responding.
+var ErrLeadershipLost = errors.New("leader lock session lost")
+
+var (
+	// leaderRetryInterval is how often a waiting replica retries the lock and
+	// how often the leader checks that its session is still alive.
+	leaderRetryInterval = 5 * time.Second
+	// leaderReleaseTimeout bounds unlocking once the callback returns.
+	leaderReleaseTimeout = 5 * time.Second
+)
+
+// LockKey derives an advisory lock key from a readable name, e.g. LockKey("cleanup").
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// RunAsLeader runs fn on at most one replica at a time, using a session-level
+// Postgres advisory lock on lockKey. Replicas that lose the race retry every
+// leaderRetryInterval until the lock frees up or ctx is cancelled. The lock is
+// held on a dedicated connection for as long as fn runs and released when it
+// returns, so fn should return promptly once its context is done. That context
+// is also cancelled, with cause ErrLeadershipLost, if the lock's session dies.
+func (s *Service) RunAsLeader(ctx context.Context, lockKey int64, fn func(ctx context.Context) error) error {
+	if s.DB == nil {
+		return ErrNoDatabase
+	}
+
+	conn, err := acquireLeaderLock(ctx, s.DB, lockKey)
+	if err != nil {
+		return err
+	}
+	s.Logger.Info("Acquired leader lock %d", lockKey)
+
+	leaderCtx, cancel := context.WithCancelCause(ctx)
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		watchLeaderLock(leaderCtx, cancel, conn)
+	}()
+
+	err = fn(leaderCtx)
+	lost := errors.Is(context.Cause(leaderCtx), ErrLeadershipLost)
+	cancel(nil)
+	<-watched
+
+	if relErr := releaseLeaderLock(conn, lockKey); relErr != nil {
+		s.Logger.Warn("failed to release leader lock %d, discarding its connection: %v", lockKey, relErr)
+	} else {
+		s.Logger.Info("Released leader lock %d", lockKey)
+	}
+
+	if lost && err == nil {
+		return fmt.Errorf("leader lock %d: %w", lockKey, ErrLeadershipLost)
+	}
+	return err
+}
+
+// acquireLeaderLock pins a connection and polls pg_try_advisory_lock on it until it succeeds.
+func acquireLeaderLock(ctx context.Context, db *sql.DB, lockKey int64) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve leader lock connection: %w", err)
+	}
+
+	for {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&acquired); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to acquire leader lock %d: %w", lockKey, err)
+		}
+		if acquired {
+			return conn, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(leaderRetryInterval):
+		}
+	}
+}
+
+// watchLeaderLock pings the lock's session until ctx is done, cancelling it if the session dies.
+func watchLeaderLock(ctx context.Context, cancel context.CancelCauseFunc, conn *sql.Conn) {
+	ticker := time.NewTicker(leaderRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+				cancel(ErrLeadershipLost)
+				return
+			}
+		}
+	}
+}
+
+// releaseLeaderLock unlocks and returns the connection to the pool. If unlocking
+// fails the connection is discarded instead, since the lock lives as long as its session.
+func releaseLeaderLock(conn *sql.Conn, lockKey int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), leaderReleaseTimeout)
+	defer cancel()
+
+	var released bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey).Scan(&released)
+	if err == nil && !released {
+		err = fmt.Errorf("lock was not held by this session")
+	}
+	if err != nil {
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+	return err
+}
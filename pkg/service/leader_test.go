@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lockServer emulates Postgres session-level advisory locks shared by every
+// connection opened from it.
+type lockServer struct {
+	mu      sync.Mutex
+	holders map[int64]*lockConn
+	broken  atomic.Bool
+}
+
+func (s *lockServer) Connect(context.Context) (driver.Conn, error) { return &lockConn{srv: s}, nil }
+
+func (s *lockServer) Driver() driver.Driver { return nil }
+
+type lockConn struct{ srv *lockServer }
+
+func (c *lockConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (c *lockConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+// Close ends the session, releasing its locks like Postgres would.
+func (c *lockConn) Close() error {
+	c.srv.mu.Lock()
+	defer c.srv.mu.Unlock()
+	for key, holder := range c.srv.holders {
+		if holder == c {
+			delete(c.srv.holders, key)
+		}
+	}
+	return nil
+}
+
+func (c *lockConn) Ping(context.Context) error {
+	if c.srv.broken.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *lockConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	key := args[0].Value.(int64)
+	c.srv.mu.Lock()
+	defer c.srv.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "pg_try_advisory_lock"):
+		holder, held := c.srv.holders[key]
+		if !held {
+			c.srv.holders[key] = c
+		}
+		return &boolRows{v: !held || holder == c}, nil
+	case strings.Contains(query, "pg_advisory_unlock"):
+		ok := c.srv.holders[key] == c
+		if ok {
+			delete(c.srv.holders, key)
+		}
+		return &boolRows{v: ok}, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type boolRows struct {
+	v    bool
+	done bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"result"} }
+
+func (r *boolRows) Close() error { return nil }
+
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.v
+	return nil
+}
+
+func newLeaderService(t *testing.T) (*Service, *lockServer) {
+	srv := &lockServer{holders: map[int64]*lockConn{}}
+	db := sql.OpenDB(srv)
+	t.Cleanup(func() { db.Close() })
+
+	svc := NewMock()
+	svc.DB = db
+	return svc, srv
+}
+
+func fastLeaderRetry(t *testing.T) {
+	orig := leaderRetryInterval
+	leaderRetryInterval = 5 * time.Millisecond
+	t.Cleanup(func() { leaderRetryInterval = orig })
+}
+
+func TestRunAsLeader_OnlyOneContenderRunsAtATime(t *testing.T) {
+	fastLeaderRetry(t)
+	svc, _ := newLeaderService(t)
+	key := LockKey("cleanup")
+
+	var running, maxRunning, runs atomic.Int32
+	fn := func(ctx context.Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		runs.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, svc.RunAsLeader(context.Background(), key, fn))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), runs.Load(), "the second contender runs once the lock is released")
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestRunAsLeader_WaitingContenderStopsOnCancel(t *testing.T) {
+	fastLeaderRetry(t)
+	svc, _ := newLeaderService(t)
+	key := LockKey("cleanup")
+
+	leading := make(chan struct{})
+	stop := make(chan struct{})
+	go func() {
+		_ = svc.RunAsLeader(context.Background(), key, func(ctx context.Context) error {
+			close(leading)
+			<-stop
+			return nil
+		})
+	}()
+	<-leading
+	defer close(stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	err := svc.RunAsLeader(ctx, key, func(context.Context) error {
+		t.Error("fn must not run while another replica leads")
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRunAsLeader_ReleasesOnCancellation(t *testing.T) {
+	fastLeaderRetry(t)
+	svc, srv := newLeaderService(t)
+	key := LockKey("cleanup")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.RunAsLeader(ctx, key, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+	cancel()
+
+	assert.ErrorIs(t, <-done, context.Canceled)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	assert.Empty(t, srv.holders)
+}
+
+func TestRunAsLeader_ReturnsFnError(t *testing.T) {
+	svc, _ := newLeaderService(t)
+	want := errors.New("cleanup failed")
+	assert.Equal(t, want, svc.RunAsLeader(context.Background(), 1, func(context.Context) error { return want }))
+}
+
+func TestRunAsLeader_LostSessionCancelsFn(t *testing.T) {
+	fastLeaderRetry(t)
+	svc, srv := newLeaderService(t)
+
+	err := svc.RunAsLeader(context.Background(), 1, func(ctx context.Context) error {
+		srv.broken.Store(true)
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), ErrLeadershipLost)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrLeadershipLost)
+}
+
+func TestRunAsLeader_NoDatabase(t *testing.T) {
+	svc := NewMock()
+	svc.DB = nil
+	assert.ErrorIs(t, svc.RunAsLeader(context.Background(), 1, func(context.Context) error { return nil }), ErrNoDatabase)
+}
+
+func TestLockKey_Stable(t *testing.T) {
+	assert.Equal(t, LockKey("cleanup"), LockKey("cleanup"))
+	assert.NotEqual(t, LockKey("cleanup"), LockKey("reindex"))
+}
+
+var _ driver.QueryerContext = (*lockConn)(nil)
+var _ driver.Pinger = (*lockConn)(nil)
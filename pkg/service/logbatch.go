@@ -0,0 +1,25 @@
+package service
+
+import (
+	"os"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+)
+
+// logBatchEnabled reports whether LOG_BATCH=true asks for request-scoped logs to be
+// queued and written in batches instead of synchronously on the request path.
+func logBatchEnabled() bool {
+	return os.Getenv("LOG_BATCH") == "true"
+}
+
+// LogSink returns the sink request-scoped loggers write to: LogBatch when set,
+// otherwise Logger. It returns nil when neither is set.
+func (s *Service) LogSink() logging.Sink {
+	if s.LogBatch != nil {
+		return s.LogBatch
+	}
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return nil
+}
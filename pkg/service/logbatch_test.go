@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/ranorsolutions/http-common-go/pkg/log/logger"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_LogBatch(t *testing.T) {
+	setMinimalEnv(t)
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+	t.Cleanup(func() { connectPostgres = postgres.Connect })
+
+	svc, err := New()
+	require.NoError(t, err)
+	assert.Nil(t, svc.LogBatch)
+
+	t.Setenv("LOG_BATCH", "true")
+	svc, err = New()
+	require.NoError(t, err)
+	require.NotNil(t, svc.LogBatch)
+	assert.NoError(t, svc.LogBatch.Close(context.Background()))
+}
+
+func TestLogSink(t *testing.T) {
+	assert.Nil(t, (&Service{}).LogSink())
+
+	log, err := logger.New("test", "1.0.0", true)
+	require.NoError(t, err)
+	svc := &Service{Logger: log}
+	assert.Same(t, log, svc.LogSink())
+
+	svc.LogBatch = logging.NewBatchSink(log, logging.BatchOptions{})
+	defer svc.LogBatch.Close(context.Background())
+	assert.Same(t, svc.LogBatch, svc.LogSink())
+}
@@ -13,7 +13,7 @@ func NewMock() *Service {
 	log, _ := logger.New("mock-service", "test", true)
 
 	return &Service{
-		DB:                 &sql.DB{}, // not actually connected
+		DB:                 sql.OpenDB(noDBConnector{}), // not actually connected; queries fail with ErrNoDatabase
 		ServiceConnections: map[string]*grpc.ClientConn{},
 		Services:           map[string]any{},
 		Logger:             log,
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"os"
+)
+
+// ErrNoDatabase is returned by DB helpers when the service runs without a database.
+var ErrNoDatabase = errors.New("service has no database configured")
+
+// noDatabase reports whether NO_DB=true asks New to skip connecting to a database.
+func noDatabase() bool {
+	return os.Getenv("NO_DB") == "true"
+}
+
+// noDB answers every query with ErrNoDatabase. It backs DBFromContext when DB is nil,
+// since *sql.Row cannot carry an error unless it comes from a real *sql.DB.
+var noDB = sql.OpenDB(noDBConnector{})
+
+type noDBConnector struct{}
+
+func (noDBConnector) Connect(context.Context) (driver.Conn, error) { return nil, ErrNoDatabase }
+
+func (noDBConnector) Driver() driver.Driver { return noDBDriver{} }
+
+type noDBDriver struct{}
+
+func (noDBDriver) Open(string) (driver.Conn, error) { return nil, ErrNoDatabase }
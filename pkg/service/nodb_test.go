@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_NoDB(t *testing.T) {
+	clearEnv()
+	t.Setenv("SERVICE", "test-service")
+	t.Setenv("VERSION", "1.0.0")
+	t.Setenv("NO_DB", "true")
+
+	origConnect := connectPostgres
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) {
+		t.Fatal("connectPostgres must not be called when NO_DB=true")
+		return nil, errors.New("unreachable")
+	}
+	defer func() { connectPostgres = origConnect }()
+
+	svc, err := New()
+	require.NoError(t, err)
+	require.NotNil(t, svc)
+	assert.Nil(t, svc.DB)
+}
+
+func TestDBHelpers_NoDB(t *testing.T) {
+	svc := &Service{}
+	ctx := context.Background()
+
+	_, err := svc.DBFromContext(ctx).ExecContext(ctx, "DELETE FROM sessions")
+	assert.ErrorIs(t, err, ErrNoDatabase)
+
+	var n int
+	err = svc.DBFromContext(ctx).QueryRowContext(ctx, "SELECT 1").Scan(&n)
+	assert.ErrorIs(t, err, ErrNoDatabase)
+
+	called := false
+	err = svc.WithTx(ctx, func(context.Context) error { called = true; return nil })
+	assert.ErrorIs(t, err, ErrNoDatabase)
+	assert.False(t, called)
+
+	_, err = svc.BulkInsert(ctx, "events", []string{"id"}, [][]any{{1}})
+	assert.ErrorIs(t, err, ErrNoDatabase)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+)
+
+// panicReportTimeout bounds how long a PanicSink may take to record a panic.
+const panicReportTimeout = 5 * time.Second
+
+// PanicRecord describes a recovered panic for persistent reporting.
+type PanicRecord struct {
+	Time time.Time
+	// Protocol is "http" or "grpc".
+	Protocol string
+	// Method is the HTTP method or the full gRPC method name.
+	Method string
+	// Path is the HTTP request path; it is empty for gRPC.
+	Path      string
+	RequestID string
+	UserID    string
+	Message   string
+	Stack     string
+}
+
+// PanicSink persists recovered panics, e.g. to an audit table for compliance.
+type PanicSink interface {
+	RecordPanic(ctx context.Context, rec PanicRecord) error
+}
+
+// ReportPanic hands rec to sink in the background, so neither the recovering request
+// nor shutdown waits on it. The sink gets panicReportTimeout to finish; its errors and
+// panics are logged through ctx's logger rather than propagated. A nil sink is a no-op.
+func ReportPanic(ctx context.Context, sink PanicSink, rec PanicRecord) {
+	if sink == nil {
+		return
+	}
+
+	log := logging.FromContext(ctx)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("panic sink panicked: %v", r)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), panicReportTimeout)
+		defer cancel()
+		if err := sink.RecordPanic(ctx, rec); err != nil {
+			log.Error("failed to record panic: %v", err)
+		}
+	}()
+}
+
+// DBPanicSink inserts recovered panics into a table with the columns occurred_at,
+// protocol, method, path, request_id, user_id, message and stack.
+type DBPanicSink struct {
+	db    *sql.DB
+	query string
+}
+
+// NewDBPanicSink creates a sink writing to table, which may be schema-qualified.
+func NewDBPanicSink(db *sql.DB, table string) (*DBPanicSink, error) {
+	if db == nil {
+		return nil, ErrNoDatabase
+	}
+	if table == "" {
+		return nil, fmt.Errorf("panic table is required")
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (occurred_at, protocol, method, path, request_id, user_id, message, stack) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		quoteTable(table),
+	)
+	return &DBPanicSink{db: db, query: query}, nil
+}
+
+// RecordPanic inserts rec.
+func (s *DBPanicSink) RecordPanic(ctx context.Context, rec PanicRecord) error {
+	_, err := s.db.ExecContext(ctx, s.query, rec.Time, rec.Protocol, rec.Method, rec.Path, rec.RequestID, rec.UserID, rec.Message, rec.Stack)
+	if err != nil {
+		return fmt.Errorf("failed to insert panic record: %w", err)
+	}
+	return nil
+}
+
+// panicTable reads the audit table recovered panics are written to from PANIC_TABLE.
+func panicTable() string {
+	return os.Getenv("PANIC_TABLE")
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type funcPanicSink func(ctx context.Context, rec PanicRecord) error
+
+func (f funcPanicSink) RecordPanic(ctx context.Context, rec PanicRecord) error { return f(ctx, rec) }
+
+func TestReportPanic_RecordsInBackground(t *testing.T) {
+	release := make(chan struct{})
+	got := make(chan PanicRecord, 1)
+	sink := funcPanicSink(func(ctx context.Context, rec PanicRecord) error {
+		<-release
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		got <- rec
+		return nil
+	})
+
+	// The caller's context being cancelled does not cancel the report
+	ctx, cancel := context.WithCancel(context.Background())
+	ReportPanic(ctx, sink, PanicRecord{Message: "boom"})
+	cancel()
+	close(release)
+
+	select {
+	case rec := <-got:
+		assert.Equal(t, "boom", rec.Message)
+	case <-time.After(time.Second):
+		t.Fatal("panic was not recorded")
+	}
+}
+
+func TestReportPanic_SurvivesFailingSinks(t *testing.T) {
+	done := make(chan struct{}, 2)
+	ReportPanic(context.Background(), funcPanicSink(func(context.Context, PanicRecord) error {
+		defer func() { done <- struct{}{} }()
+		panic("sink exploded")
+	}), PanicRecord{})
+	ReportPanic(context.Background(), funcPanicSink(func(context.Context, PanicRecord) error {
+		defer func() { done <- struct{}{} }()
+		return errors.New("table missing")
+	}), PanicRecord{})
+	ReportPanic(context.Background(), nil, PanicRecord{})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("sink was not called")
+		}
+	}
+}
+
+func TestDBPanicSink_InsertsRecord(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+	sink, err := NewDBPanicSink(svc.DB, "audit.panics")
+	require.NoError(t, err)
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectExec(`INSERT INTO "audit"."panics" \(occurred_at, protocol, method, path, request_id, user_id, message, stack\)`).
+		WithArgs(at, "http", "GET", "/api/v1/users", "req-1", "user-1", "boom", "goroutine 1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = sink.RecordPanic(context.Background(), PanicRecord{
+		Time: at, Protocol: "http", Method: "GET", Path: "/api/v1/users",
+		RequestID: "req-1", UserID: "user-1", Message: "boom", Stack: "goroutine 1",
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewDBPanicSink_Validation(t *testing.T) {
+	_, err := NewDBPanicSink(nil, "panics")
+	assert.ErrorIs(t, err, ErrNoDatabase)
+
+	svc, _ := newSQLMockService(t)
+	_, err = NewDBPanicSink(svc.DB, "")
+	assert.EqualError(t, err, "panic table is required")
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ErrNotReady is reported by CheckReady while the service has marked itself not ready.
+var ErrNotReady = errors.New("service is not ready")
+
+// SetReady marks the service ready or not ready to receive traffic without shutting
+// it down, e.g. around a cache rebuild. Services start ready. The overall gRPC health
+// status follows immediately, combined with the last MonitorHealth results, and
+// CheckReady reports ErrNotReady until the service is marked ready again.
+func (s *Service) SetReady(ready bool) {
+	s.notReady.Store(!ready)
+	s.publishOverallHealth()
+}
+
+// IsReady reports whether the service last marked itself ready.
+func (s *Service) IsReady() bool {
+	return !s.notReady.Load()
+}
+
+// CheckReady reports whether the service should receive traffic: it must be marked
+// ready and every registered health check must pass. Checks run in name order and
+// the first failure is returned.
+func (s *Service) CheckReady(ctx context.Context) error {
+	if !s.IsReady() {
+		return ErrNotReady
+	}
+
+	checks := s.HealthChecks()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := checks[name](ctx); err != nil {
+			return fmt.Errorf("health check %s failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// publishOverallHealth sets the overall ("") gRPC health status from the readiness
+// flag and the last health check results.
+func (s *Service) publishOverallHealth() {
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	if !s.IsReady() || s.checksFailing.Load() {
+		status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	s.HealthServer().SetServingStatus("", status)
+}
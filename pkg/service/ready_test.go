@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func overallStatus(svc *Service) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	resp, err := svc.HealthServer().Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return grpc_health_v1.HealthCheckResponse_UNKNOWN
+	}
+	return resp.Status
+}
+
+func TestSetReady(t *testing.T) {
+	svc := &Service{}
+	assert.True(t, svc.IsReady())
+	assert.NoError(t, svc.CheckReady(context.Background()))
+
+	svc.SetReady(false)
+	assert.False(t, svc.IsReady())
+	assert.ErrorIs(t, svc.CheckReady(context.Background()), ErrNotReady)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, overallStatus(svc))
+
+	svc.SetReady(true)
+	assert.NoError(t, svc.CheckReady(context.Background()))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, overallStatus(svc))
+}
+
+func TestSetReady_CombinedWithHealthChecks(t *testing.T) {
+	svc := &Service{}
+	svc.RegisterHealthCheck("cache", func(context.Context) error { return nil })
+	svc.RegisterHealthCheck("db", func(context.Context) error { return errors.New("connection refused") })
+
+	assert.EqualError(t, svc.CheckReady(context.Background()), "health check db failed: connection refused")
+
+	// Marking the service ready does not hide failing checks from the gRPC status
+	svc.runHealthChecks(context.Background(), time.Second)
+	svc.SetReady(true)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, overallStatus(svc))
+
+	// And passing checks do not override a service marked not ready
+	svc.RegisterHealthCheck("db", func(context.Context) error { return nil })
+	svc.SetReady(false)
+	svc.runHealthChecks(context.Background(), time.Second)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, overallStatus(svc))
+
+	svc.SetReady(true)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, overallStatus(svc))
+	assert.NoError(t, svc.CheckReady(context.Background()))
+}
@@ -2,43 +2,91 @@ package service
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
 	logs "github.com/ranorsolutions/http-common-go/pkg/log/logger"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
 	"github.com/ranorsolutions/svc-common-go/pkg/route"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
 )
 
 var (
 	connectPostgres = postgres.Connect
-	dialGRPC        = grpc.Dial
+	dialGRPC        = newClient
 )
 
 type Service struct {
-	DB                 *sql.DB
+	DB *sql.DB
+	// ServiceConnections holds the dependency connections by name. Read them through
+	// Connection, Dependency and DependencyNames, which are safe to call while
+	// CloseDependency removes connections.
 	ServiceConnections map[string]*grpc.ClientConn
 	Services           map[string]interface{}
 	Logger             *logs.Logger
 	Port               string
 	HTTPHandlers       []*route.Handler
+	HTTPGroups         []*route.Group
+	HTTPMiddleware     []*route.Middleware
+	Config             interface{}
+	// Name and Version identify the service, from SERVICE and VERSION or its Config.
+	Name    string
+	Version string
+	// LogBatch, when set, batches request-scoped logs written to Logger. It is
+	// created when LOG_BATCH=true and flushed by server shutdown.
+	LogBatch *logging.BatchSink
+	// PanicSink, when set, persists panics recovered by the HTTP and gRPC servers.
+	// It writes to PANIC_TABLE when that is set and a database is configured.
+	PanicSink PanicSink
+	// Handoff, when set, carries the state of requests tracked with TrackHandoff
+	// across a restart. It uses HANDOFF_FILE when that is set.
+	Handoff HandoffStore
+
+	healthMu         sync.RWMutex
+	healthChecks     map[string]HealthCheck
+	healthServerOnce sync.Once
+	healthServer     *health.Server
+	notReady         atomic.Bool
+	checksFailing    atomic.Bool
+
+	connMu   sync.Mutex
+	inflight map[string]*inflight
+
+	handoffMu        sync.Mutex
+	handoffTracked   map[*handoffEntry]struct{}
+	handoffRestorers map[string]HandoffRestorer
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
 type ServiceOption struct {
+	// GRPCCredential secures dependency dials; MTLSCredential builds mutual TLS ones.
+	// Dials are insecure when no option sets it.
 	GRPCCredential credentials.TransportCredentials
+	// RequiredEnv, when non-nil, replaces the variables New requires to be set.
+	// An empty slice disables the check.
+	RequiredEnv []string
+	// BlockingDial makes New wait for every dependency to connect, failing once
+	// SERVICE_DEP_DIAL_TIMEOUT (default 10s) passes for any of them.
+	BlockingDial bool
 }
 
 // New -- Create a firebase app
 func New(serviceOpts ...ServiceOption) (*Service, error) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "4000"
+	// Report every missing variable at once instead of failing on the first use
+	if err := requireEnv(requiredEnv(serviceOpts)); err != nil {
+		return nil, err
 	}
 
 	// Create the service logger
@@ -47,70 +95,114 @@ func New(serviceOpts ...ServiceOption) (*Service, error) {
 		log.Fatalf("unable to create service logger")
 	}
 
-	// Connect to the Database
-	connString := postgres.GetURIFromEnv()
-	db, err := connectPostgres(connString)
+	cfg, err := configFromEnv(logger)
 	if err != nil {
-		// Avoid trying to log or access db if nil
-		if logger != nil {
-			logger.Error("failed to connect to database: %v", err)
-		} else {
-			log.Printf("failed to connect to database: %v", err)
+		return nil, err
+	}
+
+	// Dependencies are dialed insecurely unless an option secures them
+	var creds credentials.TransportCredentials = insecure.NewCredentials()
+	for _, option := range serviceOpts {
+		if option.GRPCCredential != nil {
+			creds = option.GRPCCredential
 		}
-		return nil, fmt.Errorf("failed to create db connection: %v", err)
+		cfg.BlockingDial = cfg.BlockingDial || option.BlockingDial
 	}
 
-	logger.Info("Connected to database %s", connString.HostString())
+	return newService(cfg, logger, []grpc.DialOption{grpc.WithTransportCredentials(creds)})
+}
+
+// newService connects to the database and dependencies described by cfg and builds
+// the service around them.
+func newService(cfg Config, logger *logs.Logger, grpcOptions []grpc.DialOption) (*Service, error) {
+	port := cfg.Port
+	if port == "" {
+		port = "4000"
+	}
+	logStartup(logger, cfg)
 
-	grpcOptions := []grpc.DialOption{}
-	if len(serviceOpts) > 0 {
-		for _, option := range serviceOpts {
-			if option.GRPCCredential != nil {
-				grpcOptions = append(grpcOptions, grpc.WithTransportCredentials(option.GRPCCredential))
+	// Connect to the Database, unless the service runs without one
+	var db *sql.DB
+	if cfg.NoDB {
+		logger.Info("running without a database")
+	} else {
+		backoff := cfg.DBConnectBackoff
+		if backoff <= 0 {
+			backoff = defaultConnectBackoff
+		}
+		var err error
+		db, err = connectWithRetry(logger, cfg.DB, cfg.DBConnectRetries, backoff)
+		if err != nil {
+			// Avoid trying to log or access db if nil
+			if logger != nil {
+				logger.Error("failed to connect to database: %v", err)
+			} else {
+				log.Printf("failed to connect to database: %v", err)
 			}
+			return nil, fmt.Errorf("failed to create db connection: %v", err)
 		}
-	} else {
-		grpcOptions = append(grpcOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+		logger.Info("Connected to database %s", cfg.DB.HostString())
 	}
 
-	// Parse the service dependencies
+	// Propagate request log fields and the caller identity to downstream services
+	grpcOptions = append(grpcOptions,
+		grpc.WithChainUnaryInterceptor(
+			logging.UnaryClientInterceptor(),
+			CallerUnaryClientInterceptor(cfg.Name),
+		),
+		grpc.WithChainStreamInterceptor(
+			logging.StreamClientInterceptor(),
+			CallerStreamClientInterceptor(cfg.Name),
+		),
+	)
+
 	services := map[string]*grpc.ClientConn{}
-	raw := os.Getenv("SERVICE_DEPS")
-	if raw != "" {
-		for _, dep := range strings.Split(raw, ",") {
-			dep = strings.TrimSpace(dep)
-			if dep == "" {
-				continue
-			}
-			parts := strings.SplitN(dep, "@", 2)
-			if len(parts) != 2 {
-				continue
+	tracked := map[string]*inflight{}
+	// release closes what was opened so far when a later dependency fails
+	release := func() {
+		for name, conn := range services {
+			if err := conn.Close(); err != nil {
+				logger.Warn("failed to close %s connection: %v", name, err)
 			}
-			name, addr := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
-			if name == "" || addr == "" {
-				continue
+		}
+		if db != nil {
+			if err := db.Close(); err != nil {
+				logger.Warn("failed to close database: %v", err)
 			}
+		}
+	}
+	for _, dep := range cfg.Deps {
+		// Track in-flight calls so CloseDependency can drain the connection
+		tracker := &inflight{}
+		target, targetOpts := dep.target()
+		opts := append(append(append([]grpc.DialOption{}, grpcOptions...), targetOpts...), tracker.dialOptions()...)
 
-			conn, err := dialGRPC(addr, grpcOptions...)
-			if err != nil {
-				return nil, fmt.Errorf("failed to dial %s: %w", name, err)
-			}
-			if conn == nil { // <- ensure non-nil
-				return nil, fmt.Errorf("failed to dial %s: got nil connection", name)
+		// Creation does not wait for connectivity, so only invalid targets or options fail
+		// here unless blocking was asked for
+		var conn *grpc.ClientConn
+		var err error
+		if cfg.BlockingDial {
+			timeout := cfg.DialTimeout
+			if timeout <= 0 {
+				timeout = defaultDialTimeout
 			}
-
-			services[name] = conn
-
-			// Don't touch connectivity internals (mocks can panic).
-			// If you still want a log, keep it best-effort and guarded.
-			// defer func() {
-			//     if r := recover(); r != nil {
-			//         logger.Warn("skipped connectivity inspection for %s", name)
-			//     }
-			// }()
-			// _ = conn.GetState()
-			logger.Info("Connected to %s service", name)
+			conn, err = dialBlocking(target, timeout, opts...)
+		} else {
+			conn, err = dialGRPC(target, opts...)
+		}
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("failed to dial %s at %s: %w", dep.Name, strings.Join(dep.Addrs, ", "), err)
+		}
+		if conn == nil { // <- ensure non-nil
+			release()
+			return nil, fmt.Errorf("failed to dial %s: got nil connection", dep.Name)
 		}
+
+		services[dep.Name] = conn
+		tracked[dep.Name] = tracker
+		logger.Info("Created client for %s service at %s", dep.Name, strings.Join(dep.Addrs, ", "))
 	}
 
 	// Create a new FirebaseApp instance
@@ -118,14 +210,53 @@ func New(serviceOpts ...ServiceOption) (*Service, error) {
 		DB:                 db,
 		ServiceConnections: services,
 		Logger:             logger,
+		Name:               cfg.Name,
+		Version:            cfg.Version,
 		Port:               port,
+		inflight:           tracked,
+	}
+	if cfg.LogBatch {
+		service.LogBatch = logging.NewBatchSink(logger, logging.BatchOptions{})
+	}
+	if cfg.HandoffFile != "" {
+		service.Handoff = NewFileHandoffStore(cfg.HandoffFile)
+	}
+	if table := cfg.PanicTable; table != "" {
+		if sink, err := NewDBPanicSink(db, table); err != nil {
+			logger.Warn("not persisting panics to %s: %v", table, err)
+		} else {
+			service.PanicSink = sink
+		}
+	}
+
+	// Connectivity is only confirmed once a readiness probe asks for it
+	if len(services) > 0 {
+		service.RegisterHealthCheck(ConnectionsHealthCheck, service.WaitForConnections)
 	}
 
 	return service, nil
 }
 
+// logStartup logs the configuration the service starts with, secrets masked.
+func logStartup(logger *logs.Logger, cfg Config) {
+	if logger == nil {
+		return
+	}
+	cfg.GRPCCredential = nil
+	banner, err := json.Marshal(logging.RedactSecrets(cfg))
+	if err != nil {
+		logger.Warn("unable to log the startup configuration: %v", err)
+		return
+	}
+	logger.Info("starting %s %s with config %s", cfg.Name, cfg.Version, banner)
+}
+
 func (s *Service) HandleErr(c *gin.Context, err error, message string, code int) {
-	s.Logger.Error(message)
+	if message == "" {
+		s.Logger.Error("%s", logging.RedactText(err.Error()))
+	} else {
+		s.Logger.Error("%s: %s", message, logging.RedactText(err.Error()))
+	}
 	if message == "" {
 		c.JSON(code, gin.H{"error": err.Error()})
 	} else {
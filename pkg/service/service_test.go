@@ -8,12 +8,15 @@ import (
 	"os"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/ranorsolutions/http-common-go/pkg/db/postgres"
 	"github.com/ranorsolutions/http-common-go/pkg/log/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // --- Test Setup Helpers ---
@@ -50,6 +53,13 @@ func mockConnect(_ *postgres.Connection) (*sql.DB, error) {
 	return &sql.DB{}, nil
 }
 
+// newMockDB returns a database that can be closed, for tests where New fails after connecting.
+func newMockDB(t *testing.T) *sql.DB {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	return db
+}
+
 func mockConnectFail(_ *postgres.Connection) (*sql.DB, error) {
 	return nil, errors.New("db connection failed")
 }
@@ -136,7 +146,7 @@ func TestNew_WithServiceDeps_Failure(t *testing.T) {
 	t.Setenv("SERVICE_DEPS", "auth@localhost:5001")
 
 	origConnect := connectPostgres
-	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return &sql.DB{}, nil }
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return newMockDB(t), nil }
 	defer func() { connectPostgres = origConnect }()
 
 	origDial := dialGRPC
@@ -151,6 +161,37 @@ func TestNew_WithServiceDeps_Failure(t *testing.T) {
 	assert.Contains(t, err.Error(), "mock grpc dial failed")
 }
 
+func TestNew_DialFailureReleasesEarlierConnections(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("SERVICE_DEPS", "auth@localhost:5001,users@localhost:5002")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectClose()
+	origConnect, origDial := connectPostgres, dialGRPC
+	defer func() { connectPostgres, dialGRPC = origConnect, origDial }()
+	connectPostgres = func(_ *postgres.Connection) (*sql.DB, error) { return db, nil }
+
+	// Only the second dependency fails
+	var auth *grpc.ClientConn
+	dialGRPC = func(addr string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+		if addr == "localhost:5002" {
+			return nil, errors.New("users unavailable")
+		}
+		conn, err := newClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		auth = conn
+		return conn, err
+	}
+
+	svc, err := New()
+	require.Error(t, err)
+	assert.Nil(t, svc)
+	assert.Contains(t, err.Error(), "failed to dial users")
+	require.NotNil(t, auth)
+	assert.Equal(t, connectivity.Shutdown, auth.GetState(), "the auth connection is closed")
+	assert.NoError(t, mock.ExpectationsWereMet(), "the database is closed")
+}
+
 func TestHandleErr_WithMessage(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	log, _ := logger.New("test", "1.0", true)
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrNoTransaction is returned by WithSavepoint when ctx carries no transaction.
+var ErrNoTransaction = errors.New("savepoints require an ambient transaction")
+
+// savepointName matches the unquoted identifiers accepted as savepoint names.
+var savepointName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DBTX is the query interface shared by *sql.DB and *sql.Tx.
+// Repository code should depend on it so it transparently joins an ambient transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type txKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx.
+func ContextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok && tx != nil
+}
+
+// DBFromContext returns the ambient transaction when ctx carries one, or the pooled DB otherwise.
+// Without a database every query made through the result fails with ErrNoDatabase.
+func (s *Service) DBFromContext(ctx context.Context) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if s.DB == nil {
+		return noDB
+	}
+	return s.DB
+}
+
+// WithTx runs fn inside a transaction made available to it through its context.
+// The transaction is committed when fn returns nil and rolled back when it returns
+// an error or panics. If ctx already carries a transaction fn joins it, leaving
+// commit and rollback to the outermost WithTx.
+func (s *Service) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	if s.DB == nil {
+		return ErrNoDatabase
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(ContextWithTx(ctx, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// WithSavepoint runs fn inside a savepoint of the transaction carried by ctx, so a
+// recoverable failure in part of a WithTx callback can be undone on its own. When fn
+// returns an error or panics, only its changes are rolled back and the outer
+// transaction stays usable; on success the savepoint is released. name must be a
+// plain SQL identifier.
+func (s *Service) WithSavepoint(ctx context.Context, name string, fn func(ctx context.Context) error) (err error) {
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return ErrNoTransaction
+	}
+	if !savepointName.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name %q", name)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(r)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint %s failed: %v)", err, name, rbErr)
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint %s: %w", name, err)
+	}
+	return nil
+}
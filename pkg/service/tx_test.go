@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLMockService(t *testing.T) (*Service, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	svc := NewMock()
+	svc.DB = db
+	return svc, mock
+}
+
+// insertUser stands in for repository code that is unaware of transactions.
+func insertUser(ctx context.Context, svc *Service, name string) error {
+	_, err := svc.DBFromContext(ctx).ExecContext(ctx, "INSERT INTO users (name) VALUES ($1)", name)
+	return err
+}
+
+func TestDBFromContext_OutsideTxUsesPool(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	assert.Same(t, svc.DB, svc.DBFromContext(context.Background()))
+
+	mock.ExpectExec("INSERT INTO users").WithArgs("ada").WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, insertUser(context.Background(), svc, "ada"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTx_CommitsOnSuccess(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WithArgs("ada").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO users").WithArgs("grace").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error {
+		_, inTx := TxFromContext(ctx)
+		assert.True(t, inTx)
+		if err := insertUser(ctx, svc, "ada"); err != nil {
+			return err
+		}
+		return insertUser(ctx, svc, "grace")
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTx_RollsBackOnError(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error {
+		return insertUser(ctx, svc, "ada")
+	})
+
+	assert.EqualError(t, err, "duplicate key")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTx_RollsBackOnPanic(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = svc.WithTx(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTx_NestedJoinsOuterTransaction(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WithArgs("ada").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error {
+		outer, _ := TxFromContext(ctx)
+		return svc.WithTx(ctx, func(ctx context.Context) error {
+			inner, _ := TxFromContext(ctx)
+			assert.Same(t, outer, inner)
+			return insertUser(ctx, svc, "ada")
+		})
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTx_BeginFailure(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+	mock.ExpectBegin().WillReturnError(errors.New("too many connections"))
+
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error { return nil })
+	assert.ErrorContains(t, err, "failed to begin transaction")
+}
+
+func TestWithSavepoint_FailureKeepsOuterTransaction(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+	boom := errors.New("duplicate user")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WithArgs("ada").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("SAVEPOINT import_row").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO users").WithArgs("grace").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT import_row").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO users").WithArgs("linus").WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectCommit()
+
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error {
+		if err := insertUser(ctx, svc, "ada"); err != nil {
+			return err
+		}
+
+		spErr := svc.WithSavepoint(ctx, "import_row", func(ctx context.Context) error {
+			if err := insertUser(ctx, svc, "grace"); err != nil {
+				return err
+			}
+			return boom
+		})
+		assert.ErrorIs(t, spErr, boom)
+
+		return insertUser(ctx, svc, "linus")
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithSavepoint_ReleasesOnSuccess(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO users").WithArgs("ada").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT sp1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error {
+		return svc.WithSavepoint(ctx, "sp1", func(ctx context.Context) error {
+			return insertUser(ctx, svc, "ada")
+		})
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithSavepoint_RollsBackOnPanic(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = svc.WithTx(context.Background(), func(ctx context.Context) error {
+			return svc.WithSavepoint(ctx, "sp1", func(ctx context.Context) error { panic("boom") })
+		})
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithSavepoint_RequiresTransactionAndValidName(t *testing.T) {
+	svc, mock := newSQLMockService(t)
+	called := false
+	fn := func(ctx context.Context) error {
+		called = true
+		return nil
+	}
+
+	assert.ErrorIs(t, svc.WithSavepoint(context.Background(), "sp1", fn), ErrNoTransaction)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err := svc.WithTx(context.Background(), func(ctx context.Context) error {
+		return svc.WithSavepoint(ctx, "sp1; DROP TABLE users", fn)
+	})
+	assert.EqualError(t, err, `invalid savepoint name "sp1; DROP TABLE users"`)
+	assert.False(t, called)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
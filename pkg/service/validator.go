@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidator registers a custom validation rule with gin's binding engine,
+// so `binding:"<tag>"` struct tags are enforced by gin's bind methods and the
+// http package's BindAndValidate.
+func (s *Service) RegisterValidator(tag string, fn validator.Func) error {
+	if tag == "" || fn == nil {
+		return fmt.Errorf("validator tag and function are required")
+	}
+
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("binding validator engine %T does not support custom validators", binding.Validator.Engine())
+	}
+
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		return fmt.Errorf("failed to register validator %s: %w", tag, err)
+	}
+
+	if s.Logger != nil {
+		s.Logger.Info("Registered custom validator %s", tag)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type tenantRequest struct {
+	Tenant string `json:"tenant" binding:"required,tenant_slug"`
+}
+
+func TestRegisterValidator_Enforced(t *testing.T) {
+	svc := NewMock()
+	err := svc.RegisterValidator("tenant_slug", func(fl validator.FieldLevel) bool {
+		return slugPattern.MatchString(fl.Field().String())
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, binding.Validator.ValidateStruct(&tenantRequest{Tenant: "acme-corp"}))
+
+	err = binding.Validator.ValidateStruct(&tenantRequest{Tenant: "Acme Corp!"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tenant_slug")
+}
+
+func TestRegisterValidator_InvalidArgs(t *testing.T) {
+	svc := NewMock()
+	assert.Error(t, svc.RegisterValidator("", func(validator.FieldLevel) bool { return true }))
+	assert.Error(t, svc.RegisterValidator("noop", nil))
+}
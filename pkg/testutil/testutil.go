@@ -0,0 +1,60 @@
+// Package testutil provides helpers for unit testing handlers built on this library.
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ranorsolutions/svc-common-go/pkg/apikey"
+	"github.com/ranorsolutions/svc-common-go/pkg/firebase"
+	svchttp "github.com/ranorsolutions/svc-common-go/pkg/http"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+)
+
+// NewTestContext creates a gin context for a GET / request with the same
+// request-scoped values the HTTP server sets: the service, a request ID and a
+// contextual logger. To test another request, keep the context values with
+// c.Request = httptest.NewRequest(...).WithContext(c.Request.Context()).
+func NewTestContext(svc *service.Service) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var sink logging.Sink
+	if svc != nil && svc.Logger != nil {
+		sink = svc.Logger
+	}
+
+	id := uuid.NewString()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	l := logging.New(sink).With(logging.FieldRequestID, id)
+	c.Request = req.WithContext(logging.NewContext(req.Context(), l))
+
+	c.Set(svchttp.ServiceKey, svc)
+	c.Set(logging.RequestIDKey, id)
+	return c, w
+}
+
+// SetFirebaseUser marks the context as authenticated by Firebase as tok,
+// as FirebaseAuthMiddleware would.
+func SetFirebaseUser(c *gin.Context, tok *auth.Token) {
+	c.Set(firebase.UserKey, tok)
+	setUserID(c, tok.UID)
+}
+
+// SetAPIKey marks the context as authenticated by API key k,
+// as APIKeyMiddleware would.
+func SetAPIKey(c *gin.Context, k *apikey.Key) {
+	c.Set(apikey.ContextKey, k)
+	setUserID(c, k.Identity)
+}
+
+func setUserID(c *gin.Context, id string) {
+	c.Set(logging.UserIDKey, id)
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logging.NewContext(ctx, logging.FromContext(ctx).With(logging.FieldUserID, id)))
+}
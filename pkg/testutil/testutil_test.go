@@ -0,0 +1,57 @@
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/ranorsolutions/svc-common-go/pkg/apikey"
+	"github.com/ranorsolutions/svc-common-go/pkg/firebase"
+	svchttp "github.com/ranorsolutions/svc-common-go/pkg/http"
+	"github.com/ranorsolutions/svc-common-go/pkg/logging"
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestContext(t *testing.T) {
+	svc := service.NewMock()
+
+	c, w := NewTestContext(svc)
+	assert.Same(t, svc, svchttp.ServiceFromContext(c))
+
+	id := c.GetString(logging.RequestIDKey)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, id, svchttp.LoggerFromContext(c).Field(logging.FieldRequestID))
+
+	c.JSON(http.StatusTeapot, map[string]string{"ok": "yes"})
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.JSONEq(t, `{"ok":"yes"}`, w.Body.String())
+}
+
+func TestNewTestContext_ReplacedRequestKeepsValues(t *testing.T) {
+	c, _ := NewTestContext(nil)
+	id := c.GetString(logging.RequestIDKey)
+
+	c.Request = httptest.NewRequest(http.MethodPost, "/orders", nil).WithContext(c.Request.Context())
+	assert.Equal(t, id, logging.FromContext(c.Request.Context()).Field(logging.FieldRequestID))
+}
+
+func TestSetFirebaseUser(t *testing.T) {
+	c, _ := NewTestContext(nil)
+	SetFirebaseUser(c, &auth.Token{UID: "user-123"})
+
+	require.NotNil(t, firebase.GetFirebaseUser(c))
+	assert.Equal(t, "user-123", firebase.GetFirebaseUser(c).UID)
+	assert.Equal(t, "user-123", svchttp.LoggerFromContext(c).Field(logging.FieldUserID))
+}
+
+func TestSetAPIKey(t *testing.T) {
+	c, _ := NewTestContext(nil)
+	SetAPIKey(c, &apikey.Key{Identity: "etl", Scopes: []string{"reports:read"}})
+
+	require.NotNil(t, apikey.GetKey(c))
+	assert.True(t, apikey.GetKey(c).HasScope("reports:read"))
+	assert.Equal(t, "etl", c.GetString(logging.UserIDKey))
+}
@@ -0,0 +1,125 @@
+// Package tracing holds trace sampling configuration shared by HTTP and gRPC handlers.
+// pkg/http and pkg/grpc join every request to its trace through a Sampler built by
+// NewSampler.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/ranorsolutions/svc-common-go/pkg/service"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default sample rates when TRACE_SAMPLE_RATE is unset.
+const (
+	DefaultSampleRate           = 1.0
+	DefaultProductionSampleRate = 0.05
+)
+
+// SampleRateFromEnv reads the root sampling ratio from TRACE_SAMPLE_RATE. It defaults
+// to DefaultProductionSampleRate when ENVIRONMENT=production and DefaultSampleRate otherwise.
+func SampleRateFromEnv(svc *service.Service) float64 {
+	def := DefaultSampleRate
+	if os.Getenv("ENVIRONMENT") == "production" {
+		def = DefaultProductionSampleRate
+	}
+
+	raw := os.Getenv("TRACE_SAMPLE_RATE")
+	if raw == "" {
+		return def
+	}
+
+	rate, err := strconv.ParseFloat(raw, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		if svc != nil && svc.Logger != nil {
+			svc.Logger.Warn("invalid TRACE_SAMPLE_RATE %q, using default of %g", raw, def)
+		}
+		return def
+	}
+	return rate
+}
+
+// Sampler makes parent-based ratio sampling decisions: a valid parent's decision
+// is honored and root traces are sampled with probability Rate, deterministically
+// by trace ID. It matches the SDK's ParentBased(TraceIDRatioBased(Rate)), so
+// decisions agree across services exporting through an OTel tracer provider.
+type Sampler struct {
+	Rate float64
+}
+
+// NewSampler creates a Sampler configured from TRACE_SAMPLE_RATE.
+func NewSampler(svc *service.Service) *Sampler {
+	return &Sampler{Rate: SampleRateFromEnv(svc)}
+}
+
+// ShouldSample reports whether a span in trace id with the given parent is sampled.
+func (s *Sampler) ShouldSample(parent trace.SpanContext, id trace.TraceID) bool {
+	if parent.IsValid() {
+		return parent.IsSampled()
+	}
+
+	switch {
+	case s.Rate >= 1:
+		return true
+	case s.Rate <= 0:
+		return false
+	}
+	bound := uint64(s.Rate * (1 << 63))
+	return binary.BigEndian.Uint64(id[8:16])>>1 < bound
+}
+
+// Extract returns ctx carrying the trace of an incoming W3C traceparent header, and
+// whether that request should be sampled; see ExtractCarrier.
+func (s *Sampler) Extract(ctx context.Context, h http.Header) (context.Context, bool) {
+	return s.ExtractCarrier(ctx, propagation.HeaderCarrier(h))
+}
+
+// ExtractCarrier returns ctx carrying the remote span context of the traceparent in
+// carrier, and whether the request should be sampled. Requests without a valid
+// traceparent start a new root trace, sampled with probability Rate, so calls made
+// with ctx propagate one trace and one decision downstream.
+func (s *Sampler) ExtractCarrier(ctx context.Context, carrier propagation.TextMapCarrier) (context.Context, bool) {
+	ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+	parent := trace.SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		return ctx, s.ShouldSample(parent, parent.TraceID())
+	}
+
+	id := newTraceID()
+	sampled := s.ShouldSample(parent, id)
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	root := trace.NewSpanContext(trace.SpanContextConfig{TraceID: id, SpanID: newSpanID(), TraceFlags: flags, Remote: true})
+	return trace.ContextWithRemoteSpanContext(ctx, root), sampled
+}
+
+// Sampled reports whether the trace carried by ctx is sampled.
+func Sampled(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsSampled()
+}
+
+// newTraceID returns a random, valid trace ID.
+func newTraceID() trace.TraceID {
+	var id trace.TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID returns a random, valid span ID.
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
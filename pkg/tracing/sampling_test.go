@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func sampledCount(s *Sampler, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if s.ShouldSample(trace.SpanContext{}, newTraceID()) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSampler_Ratio(t *testing.T) {
+	assert.Equal(t, 0, sampledCount(&Sampler{Rate: 0}, 1000))
+	assert.Equal(t, 1000, sampledCount(&Sampler{Rate: 1}, 1000))
+
+	half := sampledCount(&Sampler{Rate: 0.5}, 10000)
+	assert.InDelta(t, 5000, half, 500)
+}
+
+func TestSampler_DeterministicByTraceID(t *testing.T) {
+	s := &Sampler{Rate: 0.3}
+	id := newTraceID()
+	first := s.ShouldSample(trace.SpanContext{}, id)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, s.ShouldSample(trace.SpanContext{}, id))
+	}
+}
+
+func TestSampler_HonorsTraceparent(t *testing.T) {
+	never := &Sampler{Rate: 0}
+	always := &Sampler{Rate: 1}
+
+	sampled := http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+	ctx, ok := never.Extract(context.Background(), sampled)
+	assert.True(t, ok, "a sampled parent is honored even at rate 0")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+
+	unsampled := http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}}
+	_, ok = always.Extract(context.Background(), unsampled)
+	assert.False(t, ok, "an unsampled parent is honored even at rate 1")
+
+	_, ok = never.Extract(context.Background(), http.Header{"Traceparent": {"garbage"}})
+	assert.False(t, ok)
+	_, ok = always.Extract(context.Background(), http.Header{})
+	assert.True(t, ok)
+}
+
+func TestSampleRateFromEnv(t *testing.T) {
+	t.Setenv("TRACE_SAMPLE_RATE", "")
+	t.Setenv("ENVIRONMENT", "")
+	assert.Equal(t, DefaultSampleRate, SampleRateFromEnv(nil))
+
+	t.Setenv("ENVIRONMENT", "production")
+	assert.Equal(t, DefaultProductionSampleRate, SampleRateFromEnv(nil))
+
+	t.Setenv("TRACE_SAMPLE_RATE", "0.25")
+	assert.Equal(t, 0.25, NewSampler(nil).Rate)
+
+	for _, bad := range []string{"1.5", "-0.1", "often"} {
+		t.Setenv("TRACE_SAMPLE_RATE", bad)
+		assert.Equal(t, DefaultProductionSampleRate, SampleRateFromEnv(nil))
+	}
+}
+
+func TestSampler_StartsSampledRootTraces(t *testing.T) {
+	ctx, ok := (&Sampler{Rate: 1}).Extract(context.Background(), http.Header{})
+	require.True(t, ok)
+	root := trace.SpanContextFromContext(ctx)
+	assert.True(t, root.IsValid())
+	assert.True(t, Sampled(ctx))
+
+	ctx, ok = (&Sampler{Rate: 0}).Extract(context.Background(), http.Header{})
+	assert.False(t, ok)
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid(), "unsampled traces still propagate")
+	assert.False(t, Sampled(ctx))
+	assert.NotEqual(t, root.TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+
+	assert.False(t, Sampled(context.Background()))
+}